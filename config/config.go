@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,3 +16,44 @@ func LoadConfig(filename string) {
 func GetString(key string) string {
 	return os.Getenv(key)
 }
+
+// GetInt returns key parsed as an integer, or fallback when it is unset or
+// malformed.
+func GetInt(key string, fallback int) int {
+	value, err := strconv.Atoi(strings.TrimSpace(os.Getenv(key)))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// GetBool returns key parsed with strconv.ParseBool, or fallback when it is
+// unset or malformed.
+func GetBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(strings.TrimSpace(os.Getenv(key)))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// GetDuration returns key parsed with time.ParseDuration (e.g. "30s"), or
+// fallback when it is unset or malformed.
+func GetDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(strings.TrimSpace(os.Getenv(key)))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// GetStrings splits a comma separated key into trimmed, non-empty values.
+func GetStrings(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
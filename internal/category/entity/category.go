@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Attribute types a category can declare for its products.
+const (
+	AttributeString  = "string"
+	AttributeNumber  = "number"
+	AttributeBoolean = "boolean"
+)
+
+type Category struct {
+	ID         int           `db:"id_category" json:"categoryId"`
+	ParentID   sql.NullInt64 `db:"parent_id" json:"-"`
+	Name       string        `db:"name" json:"name"`
+	Slug       string        `db:"slug" json:"slug"`
+	Attributes Attributes    `db:"attributes" json:"attributes"`
+	CreatedAt  time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt  sql.NullTime  `db:"updated_at" json:"updated_at"`
+}
+
+// Attribute describes a product attribute of a category. Options, when
+// set, lists the allowed values of a string attribute.
+type Attribute struct {
+	Name     string   `json:"name" validate:"required,max=50"`
+	Type     string   `json:"type" validate:"required,oneof=string number boolean"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options,omitempty"`
+}
+
+// Attributes is stored as a jsonb array.
+type Attributes []Attribute
+
+func (a Attributes) Value() (driver.Value, error) {
+	if a == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(a)
+}
+
+func (a *Attributes) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(value, a)
+	case string:
+		return json.Unmarshal([]byte(value), a)
+	default:
+		return errors.New("category attributes must be jsonb")
+	}
+}
+
+type CategoryParam struct {
+	ParentID   *int        `json:"parentId" validate:"omitempty,min=1"`
+	Name       string      `json:"name" validate:"required,min=1,max=50"`
+	Slug       string      `json:"slug" validate:"omitempty,max=60,slug"`
+	Attributes []Attribute `json:"attributes" validate:"omitempty,dive"`
+}
+
+// CategoryNode is a category with its subcategories.
+type CategoryNode struct {
+	Category
+	ParentID *int            `json:"parentId"`
+	Children []*CategoryNode `json:"children"`
+}
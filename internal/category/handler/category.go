@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"net/http"
+	"projectsphere/eniqlo-store/internal/category/entity"
+	"projectsphere/eniqlo-store/internal/category/service"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"projectsphere/eniqlo-store/pkg/validator"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryHandler struct {
+	categorySvc service.CategoryService
+}
+
+func NewCategoryHandler(categorySvc service.CategoryService) CategoryHandler {
+	return CategoryHandler{
+		categorySvc: categorySvc,
+	}
+}
+
+// List returns the category tree.
+func (h CategoryHandler) List(c *gin.Context) {
+	categories, err := h.categorySvc.List(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetAllResponse), categories))
+}
+
+// Get returns a category with its subcategories.
+func (h CategoryHandler) Get(c *gin.Context) {
+	categoryID, err := categoryIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	category, err := h.categorySvc.Get(c.Request.Context(), categoryID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetByIDResponse), category))
+}
+
+func (h CategoryHandler) Create(c *gin.Context) {
+	payload := new(entity.CategoryParam)
+	err := c.ShouldBindJSON(payload)
+	if err != nil {
+		c.Error(validator.BindError(err))
+		return
+	}
+
+	category, err := h.categorySvc.Create(c.Request.Context(), *payload)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, msg.ReturnResult(msg.T(c.Request.Context(), msg.CreateResponse), category))
+}
+
+func (h CategoryHandler) Update(c *gin.Context) {
+	categoryID, err := categoryIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	payload := new(entity.CategoryParam)
+	err = c.ShouldBindJSON(payload)
+	if err != nil {
+		c.Error(validator.BindError(err))
+		return
+	}
+
+	category, err := h.categorySvc.Update(c.Request.Context(), categoryID, *payload)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.UpdateResponse), category))
+}
+
+func (h CategoryHandler) Delete(c *gin.Context) {
+	categoryID, err := categoryIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = h.categorySvc.Delete(c.Request.Context(), categoryID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": msg.T(c.Request.Context(), msg.DeleteResponse)})
+}
+
+func categoryIDParam(c *gin.Context) (int, error) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || categoryID <= 0 {
+		return 0, msg.BadRequest(msg.ErrConvertIdToInt)
+	}
+	return categoryID, nil
+}
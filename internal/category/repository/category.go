@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"projectsphere/eniqlo-store/internal/category/entity"
+	"projectsphere/eniqlo-store/pkg/database"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"strings"
+)
+
+type CategoryRepo struct {
+	dbConnector database.PostgresConnector
+}
+
+func NewCategoryRepo(dbConnector database.PostgresConnector) CategoryRepo {
+	return CategoryRepo{
+		dbConnector: dbConnector,
+	}
+}
+
+func (r CategoryRepo) ListCategories(ctx context.Context) ([]entity.Category, error) {
+	query := `
+        SELECT id_category, parent_id, name, slug, attributes, created_at, updated_at
+        FROM "categories"
+        ORDER BY id_category
+    `
+
+	var categories []entity.Category
+	err := r.dbConnector.DB.SelectContext(ctx, &categories, query)
+	if err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+
+	return categories, nil
+}
+
+func (r CategoryRepo) CreateCategory(ctx context.Context, param entity.Category) (entity.Category, error) {
+	query := `
+        INSERT INTO "categories" (parent_id, name, slug, attributes)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id_category, parent_id, name, slug, attributes, created_at, updated_at
+    `
+
+	var category entity.Category
+	err := r.dbConnector.DB.GetContext(ctx, &category, query, param.ParentID, param.Name, param.Slug, param.Attributes)
+	if err != nil {
+		return entity.Category{}, writeError(err)
+	}
+
+	return category, nil
+}
+
+// UpdateCategory also renames the category on products referring to it by
+// its old name or slug.
+func (r CategoryRepo) UpdateCategory(ctx context.Context, old, param entity.Category) (entity.Category, error) {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return entity.Category{}, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	query := `
+        UPDATE "categories"
+        SET parent_id = $1, name = $2, slug = $3, attributes = $4, updated_at = CURRENT_TIMESTAMP
+        WHERE id_category = $5
+        RETURNING id_category, parent_id, name, slug, attributes, created_at, updated_at
+    `
+
+	var category entity.Category
+	err = tx.GetContext(ctx, &category, query, param.ParentID, param.Name, param.Slug, param.Attributes, old.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return entity.Category{}, msg.NotFound(msg.ErrCategoryNotFound)
+		}
+		return entity.Category{}, writeError(err)
+	}
+
+	renames := map[string]string{old.Name: category.Name, old.Slug: category.Slug}
+	for from, to := range renames {
+		if from == to {
+			continue
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE "products" SET category = $1 WHERE category = $2`, to, from)
+		if err != nil {
+			return entity.Category{}, msg.InternalServerError(err.Error())
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.Category{}, msg.InternalServerError(err.Error())
+	}
+
+	return category, nil
+}
+
+// DeleteCategory refuses to delete categories with subcategories or
+// products.
+func (r CategoryRepo) DeleteCategory(ctx context.Context, category entity.Category) error {
+	var hasChildren bool
+	err := r.dbConnector.DB.GetContext(ctx, &hasChildren, `SELECT EXISTS (SELECT 1 FROM "categories" WHERE parent_id = $1)`, category.ID)
+	if err != nil {
+		return msg.InternalServerError(err.Error())
+	}
+	if hasChildren {
+		return msg.Conflict(msg.ErrCategoryHasChildren)
+	}
+
+	var inUse bool
+	err = r.dbConnector.DB.GetContext(ctx, &inUse, `SELECT EXISTS (SELECT 1 FROM "products" WHERE category IN ($1, $2))`, category.Name, category.Slug)
+	if err != nil {
+		return msg.InternalServerError(err.Error())
+	}
+	if inUse {
+		return msg.Conflict(msg.ErrCategoryInUse)
+	}
+
+	result, err := r.dbConnector.DB.ExecContext(ctx, `DELETE FROM "categories" WHERE id_category = $1`, category.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "foreign key") {
+			return msg.Conflict(msg.ErrCategoryHasChildren)
+		}
+		return msg.InternalServerError(err.Error())
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return msg.NotFound(msg.ErrCategoryNotFound)
+	}
+
+	return nil
+}
+
+func writeError(err error) error {
+	switch {
+	case strings.Contains(err.Error(), "unique"):
+		return msg.Conflict(msg.ErrCategorySlugExists)
+	case strings.Contains(err.Error(), "foreign key"):
+		return msg.NotFound(msg.ErrCategoryParentNotFound)
+	default:
+		return msg.InternalServerError(err.Error())
+	}
+}
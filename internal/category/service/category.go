@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"projectsphere/eniqlo-store/internal/category/entity"
+	"projectsphere/eniqlo-store/internal/category/repository"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"projectsphere/eniqlo-store/pkg/validator"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type CategoryService struct {
+	categoryRepo repository.CategoryRepo
+	cache        *treeCache
+}
+
+// treeCache holds the category tree for ttl. Writes through the service
+// drop it at once; other instances see changes after ttl.
+type treeCache struct {
+	mu       sync.Mutex
+	tree     *Tree
+	loadedAt time.Time
+	ttl      time.Duration
+}
+
+func NewCategoryService(categoryRepo repository.CategoryRepo, cacheTTL time.Duration) CategoryService {
+	return CategoryService{
+		categoryRepo: categoryRepo,
+		cache:        &treeCache{ttl: cacheTTL},
+	}
+}
+
+// Tree returns the cached category tree, reloading it when expired. A stale
+// tree is served if reloading fails.
+func (s CategoryService) Tree(ctx context.Context) (*Tree, error) {
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+
+	if s.cache.tree != nil && time.Since(s.cache.loadedAt) < s.cache.ttl {
+		return s.cache.tree, nil
+	}
+
+	categories, err := s.categoryRepo.ListCategories(ctx)
+	if err != nil {
+		if s.cache.tree != nil {
+			log.Warn().Err(err).Msg("cannot reload categories, using cached tree")
+			return s.cache.tree, nil
+		}
+		return nil, err
+	}
+
+	s.cache.tree = NewTree(categories)
+	s.cache.loadedAt = time.Now()
+	return s.cache.tree, nil
+}
+
+func (s CategoryService) invalidate() {
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+	s.cache.tree = nil
+}
+
+// Exists backs the `category` validation rule.
+func (s CategoryService) Exists(category string) bool {
+	tree, err := s.Tree(context.Background())
+	if err != nil {
+		log.Error().Err(err).Msg("cannot load categories")
+		return false
+	}
+	_, ok := tree.Lookup(category)
+	return ok
+}
+
+func (s CategoryService) List(ctx context.Context) ([]*entity.CategoryNode, error) {
+	tree, err := s.Tree(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tree.Roots == nil {
+		return []*entity.CategoryNode{}, nil
+	}
+	return tree.Roots, nil
+}
+
+func (s CategoryService) Get(ctx context.Context, categoryID int) (*entity.CategoryNode, error) {
+	tree, err := s.Tree(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	node, ok := tree.Node(categoryID)
+	if !ok {
+		return nil, msg.NotFound(msg.ErrCategoryNotFound)
+	}
+	return node, nil
+}
+
+func (s CategoryService) Create(ctx context.Context, param entity.CategoryParam) (entity.Category, error) {
+	category, err := s.fromParam(ctx, 0, param)
+	if err != nil {
+		return entity.Category{}, err
+	}
+
+	category, err = s.categoryRepo.CreateCategory(ctx, category)
+	if err != nil {
+		return entity.Category{}, err
+	}
+
+	s.invalidate()
+	return category, nil
+}
+
+func (s CategoryService) Update(ctx context.Context, categoryID int, param entity.CategoryParam) (entity.Category, error) {
+	old, err := s.Get(ctx, categoryID)
+	if err != nil {
+		return entity.Category{}, err
+	}
+
+	category, err := s.fromParam(ctx, categoryID, param)
+	if err != nil {
+		return entity.Category{}, err
+	}
+
+	category, err = s.categoryRepo.UpdateCategory(ctx, old.Category, category)
+	if err != nil {
+		return entity.Category{}, err
+	}
+
+	s.invalidate()
+	return category, nil
+}
+
+func (s CategoryService) Delete(ctx context.Context, categoryID int) error {
+	category, err := s.Get(ctx, categoryID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.categoryRepo.DeleteCategory(ctx, category.Category); err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+// fromParam checks the parent, which must not be the category itself or
+// one of its subcategories, the slug and the attribute definitions.
+func (s CategoryService) fromParam(ctx context.Context, categoryID int, param entity.CategoryParam) (entity.Category, error) {
+	category := entity.Category{
+		Name:       strings.TrimSpace(param.Name),
+		Slug:       param.Slug,
+		Attributes: param.Attributes,
+	}
+	if category.Slug == "" {
+		category.Slug = Slugify(category.Name)
+	}
+
+	var details []msg.FieldError
+	if !validator.IsValidSlug(category.Slug) {
+		details = append(details, msg.NewFieldError("slug", "slug", msg.ValInvalidSlug, "slug"))
+	}
+
+	seen := make(map[string]bool)
+	for i, attribute := range param.Attributes {
+		field := "attributes[" + strconv.Itoa(i) + "]"
+		if seen[attribute.Name] {
+			details = append(details, msg.NewFieldError(field+".name", "unique", msg.ValDuplicate, field+".name"))
+		}
+		seen[attribute.Name] = true
+		if len(attribute.Options) > 0 && attribute.Type != entity.AttributeString {
+			details = append(details, msg.NewFieldError(field+".options", "options", msg.ValOptionsForString, field+".options"))
+		}
+	}
+	if len(details) > 0 {
+		return entity.Category{}, msg.Validation(details...)
+	}
+
+	if param.ParentID != nil {
+		tree, err := s.Tree(ctx)
+		if err != nil {
+			return entity.Category{}, err
+		}
+		if _, ok := tree.Node(*param.ParentID); !ok {
+			return entity.Category{}, msg.NotFound(msg.ErrCategoryParentNotFound)
+		}
+		if categoryID != 0 && tree.IsDescendant(*param.ParentID, categoryID) {
+			return entity.Category{}, msg.BadRequest(msg.ErrCategoryCycle)
+		}
+		category.ParentID = sql.NullInt64{Int64: int64(*param.ParentID), Valid: true}
+	}
+
+	return category, nil
+}
+
+var nonSlugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slugify turns a category name into a slug, e.g. "Men's Shoes" into
+// "men-s-shoes".
+func Slugify(name string) string {
+	return strings.Trim(nonSlugPattern.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"projectsphere/eniqlo-store/internal/category/entity"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"sort"
+	"strings"
+)
+
+// Tree is an immutable snapshot of all categories. Categories are looked up
+// by slug or, when no other category shares it, by name.
+type Tree struct {
+	Roots []*entity.CategoryNode
+	nodes map[int]*entity.CategoryNode
+	keys  map[string]*entity.CategoryNode
+}
+
+func NewTree(categories []entity.Category) *Tree {
+	tree := &Tree{
+		nodes: make(map[int]*entity.CategoryNode, len(categories)),
+		keys:  make(map[string]*entity.CategoryNode, 2*len(categories)),
+	}
+
+	for _, category := range categories {
+		tree.nodes[category.ID] = &entity.CategoryNode{Category: category, Children: []*entity.CategoryNode{}}
+	}
+
+	names := make(map[string]int, len(categories))
+	for _, category := range categories {
+		names[strings.ToLower(category.Name)]++
+	}
+
+	for _, category := range categories {
+		node := tree.nodes[category.ID]
+		if parent, ok := tree.nodes[int(category.ParentID.Int64)]; category.ParentID.Valid && ok {
+			parentID := parent.ID
+			node.ParentID = &parentID
+			parent.Children = append(parent.Children, node)
+		} else {
+			tree.Roots = append(tree.Roots, node)
+		}
+
+		tree.keys[category.Slug] = node
+		if name := strings.ToLower(category.Name); names[name] == 1 {
+			if _, taken := tree.keys[name]; !taken {
+				tree.keys[name] = node
+			}
+		}
+	}
+
+	sortNodes(tree.Roots)
+	for _, node := range tree.nodes {
+		sortNodes(node.Children)
+	}
+
+	return tree
+}
+
+func sortNodes(nodes []*entity.CategoryNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+}
+
+// Lookup finds a category by slug or unambiguous, case insensitive name.
+func (t *Tree) Lookup(key string) (*entity.CategoryNode, bool) {
+	if node, ok := t.keys[key]; ok {
+		return node, true
+	}
+	node, ok := t.keys[strings.ToLower(key)]
+	return node, ok
+}
+
+func (t *Tree) Node(id int) (*entity.CategoryNode, bool) {
+	node, ok := t.nodes[id]
+	return node, ok
+}
+
+// IsDescendant reports whether id is ancestorID or below it.
+func (t *Tree) IsDescendant(id, ancestorID int) bool {
+	for node, ok := t.nodes[id]; ok; {
+		if node.ID == ancestorID {
+			return true
+		}
+		if node.ParentID == nil {
+			return false
+		}
+		node, ok = t.nodes[*node.ParentID]
+	}
+	return false
+}
+
+// Keys returns the names and slugs of the category found by key and of all
+// its subcategories, which is what products may refer to them by.
+func (t *Tree) Keys(key string) []string {
+	node, ok := t.Lookup(key)
+	if !ok {
+		return nil
+	}
+
+	var keys []string
+	var walk func(node *entity.CategoryNode)
+	walk = func(node *entity.CategoryNode) {
+		keys = append(keys, node.Name, node.Slug)
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(node)
+
+	return keys
+}
+
+// Attributes returns the attributes of a category including those inherited
+// from its ancestors. A subcategory may redefine an inherited attribute.
+func (t *Tree) Attributes(node *entity.CategoryNode) []entity.Attribute {
+	var lineage []*entity.CategoryNode
+	for current := node; current != nil; {
+		lineage = append([]*entity.CategoryNode{current}, lineage...)
+		if current.ParentID == nil {
+			break
+		}
+		current = t.nodes[*current.ParentID]
+	}
+
+	var attributes []entity.Attribute
+	index := make(map[string]int)
+	for _, current := range lineage {
+		for _, attribute := range current.Attributes {
+			if i, ok := index[attribute.Name]; ok {
+				attributes[i] = attribute
+				continue
+			}
+			index[attribute.Name] = len(attributes)
+			attributes = append(attributes, attribute)
+		}
+	}
+
+	return attributes
+}
+
+// ValidateAttributes checks product attribute values against the attributes
+// of category. Unknown categories are left to the category rule.
+func (t *Tree) ValidateAttributes(category string, values map[string]interface{}) []msg.FieldError {
+	node, ok := t.Lookup(category)
+	if !ok {
+		return nil
+	}
+
+	var details []msg.FieldError
+	known := make(map[string]bool)
+	for _, attribute := range t.Attributes(node) {
+		known[attribute.Name] = true
+		field := "attributes." + attribute.Name
+
+		value, ok := values[attribute.Name]
+		if !ok || value == nil {
+			if attribute.Required {
+				details = append(details, msg.NewFieldError(field, "required", msg.ValRequired, field))
+			}
+			continue
+		}
+
+		if !hasType(value, attribute.Type) {
+			details = append(details, msg.NewFieldError(field, "type", msg.ValAttributeType, field, attribute.Type))
+			continue
+		}
+
+		if len(attribute.Options) > 0 && !contains(attribute.Options, fmt.Sprint(value)) {
+			details = append(details, msg.NewFieldError(field, "oneof", msg.ValOneOf, field, strings.Join(attribute.Options, " ")))
+		}
+	}
+
+	unknown := make([]string, 0)
+	for name := range values {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		field := "attributes." + name
+		details = append(details, msg.NewFieldError(field, "unknown", msg.ValUnknownAttribute, field))
+	}
+
+	return details
+}
+
+func hasType(value interface{}, attributeType string) bool {
+	switch value.(type) {
+	case string:
+		return attributeType == entity.AttributeString
+	case float64, json.Number:
+		return attributeType == entity.AttributeNumber
+	case bool:
+		return attributeType == entity.AttributeBoolean
+	default:
+		return false
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package entity
+
+import (
+	productEntity "projectsphere/eniqlo-store/internal/product/entity"
+	promotionEntity "projectsphere/eniqlo-store/internal/promotion/entity"
+	"projectsphere/eniqlo-store/pkg/money"
+	"time"
+)
+
+// CheckoutItemParam is one line of a sale. VariantID may be left out for
+// products without options.
+type CheckoutItemParam struct {
+	ProductID string `json:"productId" validate:"required,numeric"`
+	VariantID int    `json:"variantId" validate:"omitempty,min=1"`
+	Quantity  int    `json:"quantity" validate:"required,min=1,max=100000"`
+}
+
+// CheckoutParam is a sale. PromoCodes unlock the promotions that need a
+// code.
+type CheckoutParam struct {
+	ProductDetails []CheckoutItemParam `json:"productDetails" validate:"required,min=1,max=100,dive"`
+	PromoCodes     []string            `json:"promoCodes" validate:"max=5,dive,required,max=30"`
+	Paid           money.Money         `json:"paid" validate:"required,min=1"`
+}
+
+// CheckoutItem is a sold variant with the name, SKU and price it had at
+// the time of sale. Subtotal is at list price; Discounts explain how it
+// came down to Total. Cost and Category are kept for margin reporting and
+// left off the receipt.
+type CheckoutItem struct {
+	ProductID int                          `db:"id_product" json:"productId"`
+	VariantID int                          `db:"id_variant" json:"variantId"`
+	Name      string                       `db:"name" json:"name"`
+	SKU       string                       `db:"sku" json:"sku"`
+	Options   productEntity.VariantOptions `db:"options" json:"options"`
+	Quantity  int                          `db:"quantity" json:"quantity"`
+	Price     money.Money                  `db:"price" json:"price"`
+	Cost      money.Money                  `db:"cost" json:"-"`
+	Category  string                       `db:"category" json:"-"`
+	Subtotal  money.Money                  `db:"-" json:"subtotal"`
+	Discount  money.Money                  `db:"discount" json:"discount"`
+	Total     money.Money                  `db:"-" json:"total"`
+	Discounts []promotionEntity.Discount   `db:"-" json:"discounts"`
+}
+
+// Checkout is a receipt: Subtotal at list price less Discount is the Total
+// paid for.
+type Checkout struct {
+	ID        int            `db:"id_checkout" json:"checkoutId"`
+	Items     []CheckoutItem `db:"-" json:"productDetails"`
+	Subtotal  money.Money    `db:"-" json:"subtotal"`
+	Discount  money.Money    `db:"discount" json:"discount"`
+	Total     money.Money    `db:"total" json:"total"`
+	Paid      money.Money    `db:"paid" json:"paid"`
+	Change    money.Money    `db:"change" json:"change"`
+	CreatedAt time.Time      `db:"created_at" json:"created_at"`
+}
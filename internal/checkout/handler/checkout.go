@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+	"projectsphere/eniqlo-store/internal/checkout/entity"
+	"projectsphere/eniqlo-store/internal/checkout/service"
+	"projectsphere/eniqlo-store/pkg/middleware/auth"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"projectsphere/eniqlo-store/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CheckoutHandler struct {
+	checkoutSvc service.CheckoutService
+}
+
+func NewCheckoutHandler(checkoutSvc service.CheckoutService) CheckoutHandler {
+	return CheckoutHandler{
+		checkoutSvc: checkoutSvc,
+	}
+}
+
+// Checkout sells {"productDetails": [{"productId", "variantId", "quantity"}], "paid"}
+// and returns the receipt with the change.
+func (h CheckoutHandler) Checkout(c *gin.Context) {
+	payload := new(entity.CheckoutParam)
+	err := c.ShouldBindJSON(payload)
+	if err != nil {
+		c.Error(validator.BindError(err))
+		return
+	}
+
+	userID, err := auth.GetUserIdInsideCtx(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	checkout, err := h.checkoutSvc.Checkout(c.Request.Context(), userID, *payload)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, msg.ReturnResult(msg.T(c.Request.Context(), msg.CheckoutResponse), checkout))
+}
@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"projectsphere/eniqlo-store/internal/checkout/entity"
+	inventoryEntity "projectsphere/eniqlo-store/internal/inventory/entity"
+	inventoryRepository "projectsphere/eniqlo-store/internal/inventory/repository"
+	productRepository "projectsphere/eniqlo-store/internal/product/repository"
+	promotionEntity "projectsphere/eniqlo-store/internal/promotion/entity"
+	"projectsphere/eniqlo-store/pkg/database"
+	"projectsphere/eniqlo-store/pkg/money"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type CheckoutRepo struct {
+	dbConnector database.PostgresConnector
+}
+
+func NewCheckoutRepo(dbConnector database.PostgresConnector) CheckoutRepo {
+	return CheckoutRepo{
+		dbConnector: dbConnector,
+	}
+}
+
+// stockedVariant is a variant as it can be sold right now.
+type stockedVariant struct {
+	entity.CheckoutItem
+	Stock       int  `db:"stock"`
+	IsAvailable bool `db:"is_available"`
+}
+
+// Checkout sells the items of param in one transaction: it applies the
+// price changes that are due, prices every item at its variant's effective
+// price, checks availability and stock at the selling location, applies
+// the promotions running at now, checks the paid amount, records the sale
+// with its discounts and books it in the stock ledger.
+func (r CheckoutRepo) Checkout(ctx context.Context, userID uint32, param entity.CheckoutParam, promotions []promotionEntity.Promotion, now time.Time) (entity.Checkout, error) {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return entity.Checkout{}, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	productIDs := make([]int, 0, len(param.ProductDetails))
+	for _, item := range param.ProductDetails {
+		productID, _ := strconv.Atoi(item.ProductID)
+		productIDs = append(productIDs, productID)
+	}
+
+	// variants are only written with their product locked, so locking the
+	// products in id order keeps their stock stable and avoids deadlocks
+	_, err = tx.ExecContext(ctx, `SELECT id_product FROM "products" WHERE id_product = ANY($1) ORDER BY id_product FOR UPDATE`, pq.Array(productIDs))
+	if err != nil {
+		return entity.Checkout{}, msg.InternalServerError(err.Error())
+	}
+
+	// the scheduler may not have caught up with a price change yet
+	if _, err := productRepository.ApplyDuePrices(ctx, tx, productIDs); err != nil {
+		return entity.Checkout{}, err
+	}
+
+	locationID, err := inventoryRepository.ResolveLocation(ctx, tx, 0)
+	if err != nil {
+		return entity.Checkout{}, err
+	}
+
+	var variants []stockedVariant
+	err = tx.SelectContext(ctx, &variants, `
+        SELECT v.id_variant, v.id_product, p.name, v.sku, v.options, COALESCE(v.price, p.price) AS price, p.cost, p.category,
+            COALESCE(s.stock, 0) AS stock, v.is_available AND p.is_available AS is_available
+        FROM "product_variants" v
+        JOIN "products" p ON p.id_product = v.id_product
+        LEFT JOIN "variant_stocks" s ON s.id_variant = v.id_variant AND s.id_location = $2
+        WHERE v.id_product = ANY($1)
+        ORDER BY v.position, v.id_variant
+    `, pq.Array(productIDs), locationID)
+	if err != nil {
+		return entity.Checkout{}, msg.InternalServerError(err.Error())
+	}
+
+	byProduct := make(map[int][]stockedVariant)
+	for _, variant := range variants {
+		byProduct[variant.ProductID] = append(byProduct[variant.ProductID], variant)
+	}
+
+	var details []msg.FieldError
+	items := make([]entity.CheckoutItem, 0, len(param.ProductDetails))
+	soldVariants := make(map[int]int)
+	for i, item := range param.ProductDetails {
+		productID, _ := strconv.Atoi(item.ProductID)
+		candidates, ok := byProduct[productID]
+		if !ok {
+			return entity.Checkout{}, msg.NotFound(msg.ErrProductNotFound)
+		}
+
+		var variant stockedVariant
+		switch {
+		case item.VariantID != 0:
+			found := false
+			for _, candidate := range candidates {
+				if candidate.VariantID == item.VariantID {
+					variant, found = candidate, true
+				}
+			}
+			if !found {
+				return entity.Checkout{}, msg.NotFound(msg.ErrVariantNotFound)
+			}
+		case len(candidates) == 1:
+			variant = candidates[0]
+		default:
+			field := fmt.Sprintf("productDetails[%d].variantId", i)
+			details = append(details, msg.NewFieldError(field, "required", msg.ValVariantRequired, field))
+			continue
+		}
+
+		if !variant.IsAvailable {
+			field := fmt.Sprintf("productDetails[%d]", i)
+			details = append(details, msg.NewFieldError(field, "available", msg.ValUnavailable, field))
+		}
+		soldVariants[variant.VariantID] += item.Quantity
+		if soldVariants[variant.VariantID] > variant.Stock {
+			field := fmt.Sprintf("productDetails[%d].quantity", i)
+			details = append(details, msg.NewFieldError(field, "stock", msg.ValInsufficientStock, field, strconv.Itoa(variant.Stock)))
+		}
+
+		sold := variant.CheckoutItem
+		sold.Quantity = item.Quantity
+		items = append(items, sold)
+	}
+	if len(details) > 0 {
+		return entity.Checkout{}, msg.Validation(details...)
+	}
+
+	cart := promotionEntity.Cart{Codes: param.PromoCodes, At: now}
+	for _, item := range items {
+		cart.Lines = append(cart.Lines, promotionEntity.Line{
+			ProductID: item.ProductID,
+			Category:  item.Category,
+			Quantity:  item.Quantity,
+			UnitPrice: item.Price,
+		})
+	}
+	pricing := promotionEntity.Price(cart, promotions)
+	for i, line := range pricing.Lines {
+		items[i].Subtotal = line.Subtotal
+		items[i].Discount = line.Discount
+		items[i].Total = line.Total
+		items[i].Discounts = line.Discounts
+	}
+
+	checkout := entity.Checkout{
+		Items:    items,
+		Subtotal: pricing.Subtotal,
+		Discount: pricing.Discount,
+		Total:    pricing.Total,
+		Paid:     param.Paid,
+	}
+	if checkout.Paid.Cmp(checkout.Total) < 0 {
+		return entity.Checkout{}, msg.Validation(msg.NewFieldError("paid", "paid", msg.ValPaidNotEnough, "paid", checkout.Total.String()))
+	}
+	checkout.Change = money.Change(checkout.Paid, checkout.Total)
+
+	err = tx.QueryRowContext(ctx, `
+        INSERT INTO "checkouts" (user_id, discount, total, paid, change)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id_checkout, created_at
+    `, userID, checkout.Discount, checkout.Total, checkout.Paid, checkout.Change).Scan(&checkout.ID, &checkout.CreatedAt)
+	if err != nil {
+		return entity.Checkout{}, msg.InternalServerError(err.Error())
+	}
+
+	for _, item := range items {
+		var itemID int
+		err = tx.QueryRowContext(ctx, `
+            INSERT INTO "checkout_items" (id_checkout, id_product, id_variant, name, sku, options, quantity, price, discount, cost, category)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+            RETURNING id_item
+        `, checkout.ID, item.ProductID, item.VariantID, item.Name, item.SKU, item.Options, item.Quantity, item.Price, item.Discount,
+			item.Cost, item.Category).Scan(&itemID)
+		if err != nil {
+			return entity.Checkout{}, msg.InternalServerError(err.Error())
+		}
+
+		for _, discount := range item.Discounts {
+			_, err = tx.ExecContext(ctx, `
+                INSERT INTO "checkout_discounts" (id_item, id_promotion, name, type, quantity, amount)
+                VALUES ($1, $2, $3, $4, $5, $6)
+            `, itemID, discount.PromotionID, discount.Name, discount.Type, discount.Quantity, discount.Amount)
+			if err != nil {
+				return entity.Checkout{}, msg.InternalServerError(err.Error())
+			}
+		}
+
+		_, err = inventoryRepository.RecordMovement(ctx, tx, inventoryEntity.StockMovement{
+			ProductID:  item.ProductID,
+			VariantID:  item.VariantID,
+			LocationID: locationID,
+			Type:       inventoryEntity.MovementSale,
+			Quantity:   -item.Quantity,
+			Reference:  fmt.Sprintf("checkout:%d", checkout.ID),
+			UserID:     inventoryEntity.StaffID(userID),
+		})
+		if err != nil {
+			return entity.Checkout{}, err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE "products"
+        SET version = version + 1
+        WHERE id_product = ANY($1)
+    `, pq.Array(productIDs))
+	if err != nil {
+		return entity.Checkout{}, msg.InternalServerError(err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.Checkout{}, msg.InternalServerError(err.Error())
+	}
+
+	return checkout, nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"projectsphere/eniqlo-store/internal/checkout/entity"
+	"projectsphere/eniqlo-store/internal/checkout/repository"
+	promotionEntity "projectsphere/eniqlo-store/internal/promotion/entity"
+	promotionService "projectsphere/eniqlo-store/internal/promotion/service"
+	"projectsphere/eniqlo-store/pkg/middleware/logger"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+)
+
+type CheckoutService struct {
+	checkoutRepo repository.CheckoutRepo
+	promotionSvc promotionService.PromotionService
+}
+
+func NewCheckoutService(checkoutRepo repository.CheckoutRepo, promotionSvc promotionService.PromotionService) CheckoutService {
+	return CheckoutService{
+		checkoutRepo: checkoutRepo,
+		promotionSvc: promotionSvc,
+	}
+}
+
+// Checkout sells the requested variants at the running promotions and
+// returns the receipt. Every promotion code given has to belong to a
+// running promotion.
+func (s CheckoutService) Checkout(ctx context.Context, userID uint32, param entity.CheckoutParam) (entity.Checkout, error) {
+	promotions, now, err := s.promotionSvc.Running(ctx)
+	if err != nil {
+		return entity.Checkout{}, err
+	}
+
+	var details []msg.FieldError
+	for i, code := range param.PromoCodes {
+		if !hasCode(promotions, code) {
+			field := fmt.Sprintf("promoCodes[%d]", i)
+			details = append(details, msg.NewFieldError(field, "promo_code", msg.ValInvalidPromoCode, field))
+		}
+	}
+	if len(details) > 0 {
+		return entity.Checkout{}, msg.Validation(details...)
+	}
+
+	checkout, err := s.checkoutRepo.Checkout(ctx, userID, param, promotions, now)
+	if err != nil {
+		return entity.Checkout{}, err
+	}
+
+	logger.FromContext(ctx).Info().
+		Int("checkoutId", checkout.ID).
+		Int("items", len(checkout.Items)).
+		Str("discount", checkout.Discount.String()).
+		Str("total", checkout.Total.String()).
+		Msg("checkout completed")
+
+	return checkout, nil
+}
+
+func hasCode(promotions []promotionEntity.Promotion, code string) bool {
+	code = promotionEntity.NormalizeCode(code)
+	for _, promotion := range promotions {
+		if promotion.Code != nil && *promotion.Code == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package entity
+
+// MaxStock is the most a variant may hold, as enforced on products.
+const MaxStock = 100000
+
+// Reason codes of a manual stock adjustment.
+const (
+	ReasonDamage  = "damage"
+	ReasonTheft   = "theft"
+	ReasonRecount = "recount"
+	ReasonFound   = "found"
+)
+
+// AdjustmentParam changes a variant's stock at a location either by Delta
+// or to Count. VariantID may be left out for products without options,
+// LocationID for the selling location.
+type AdjustmentParam struct {
+	VariantID  int    `json:"variantId" validate:"omitempty,min=1"`
+	LocationID int    `json:"locationId" validate:"omitempty,min=1"`
+	Delta      *int   `json:"delta" validate:"omitempty,min=-100000,max=100000"`
+	Count      *int   `json:"count" validate:"omitempty,min=0,max=100000"`
+	Reason     string `json:"reason" validate:"required,oneof=damage theft recount found"`
+	Note       string `json:"note" validate:"max=200"`
+}
+
+// AdjustmentResult is the recorded movement, or nil when a count matched
+// the stock, the variant's stock over all locations and the product's new
+// version.
+type AdjustmentResult struct {
+	Movement *StockMovement `json:"movement"`
+	Stock    int            `json:"stock"`
+	Version  int            `json:"version"`
+}
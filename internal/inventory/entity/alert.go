@@ -0,0 +1,49 @@
+package entity
+
+import "time"
+
+// Stock alert statuses. An alert is open until someone acknowledges it and
+// resolved once the product is restocked above its reorder point or
+// someone resolves it.
+const (
+	AlertOpen         = "open"
+	AlertAcknowledged = "acknowledged"
+	AlertResolved     = "resolved"
+)
+
+// StockAlert reports a product at or below its reorder point. Stock,
+// ReorderPoint and ReorderQuantity are as detected; CurrentStock is the
+// product's stock now.
+type StockAlert struct {
+	ID              int        `db:"id_alert" json:"alertId"`
+	ProductID       int        `db:"id_product" json:"productId"`
+	Name            string     `db:"name" json:"name"`
+	SKU             string     `db:"sku" json:"sku"`
+	Status          string     `db:"status" json:"status"`
+	Stock           int        `db:"stock" json:"stock"`
+	CurrentStock    int        `db:"current_stock" json:"currentStock"`
+	ReorderPoint    int        `db:"reorder_point" json:"reorderPoint"`
+	ReorderQuantity int        `db:"reorder_quantity" json:"reorderQuantity"`
+	NotifiedAt      *time.Time `db:"notified_at" json:"notifiedAt"`
+	AcknowledgedAt  *time.Time `db:"acknowledged_at" json:"acknowledgedAt"`
+	AcknowledgedBy  *int       `db:"acknowledged_by" json:"acknowledgedBy"`
+	ResolvedAt      *time.Time `db:"resolved_at" json:"resolvedAt"`
+	ResolvedBy      *int       `db:"resolved_by" json:"resolvedBy"`
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
+}
+
+// StockAlertFilter narrows down the alert list; an empty Status lists the
+// open and acknowledged alerts.
+type StockAlertFilter struct {
+	Status string
+	Limit  int
+	Offset int
+}
+
+func IsAlertStatus(status string) bool {
+	switch status {
+	case AlertOpen, AlertAcknowledged, AlertResolved:
+		return true
+	}
+	return false
+}
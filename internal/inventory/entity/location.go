@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Location types.
+const (
+	LocationStore     = "store"
+	LocationBackroom  = "backroom"
+	LocationWarehouse = "warehouse"
+)
+
+// Location is a place stock is held in. Code is the stable key used in
+// configuration, e.g. SELLING_LOCATION=store.
+type Location struct {
+	ID        int          `db:"id_location" json:"locationId"`
+	Code      string       `db:"code" json:"code"`
+	Name      string       `db:"name" json:"name"`
+	Type      string       `db:"type" json:"type"`
+	IsActive  bool         `db:"is_active" json:"isActive"`
+	CreatedAt time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt sql.NullTime `db:"updated_at" json:"updated_at"`
+}
+
+// LocationParam creates or updates a location; IsActive defaults to true.
+type LocationParam struct {
+	Code     string `json:"code" validate:"required,min=1,max=30,slug"`
+	Name     string `json:"name" validate:"required,min=1,max=50"`
+	Type     string `json:"type" validate:"required,oneof=store backroom warehouse"`
+	IsActive *bool  `json:"isActive"`
+}
+
+// LocationStock is the stock of a variant at a location and the quantity
+// in transit to it.
+type LocationStock struct {
+	LocationID   int    `db:"id_location" json:"locationId"`
+	LocationCode string `db:"location_code" json:"locationCode"`
+	ProductID    int    `db:"id_product" json:"productId"`
+	Name         string `db:"name" json:"name"`
+	VariantID    int    `db:"id_variant" json:"variantId"`
+	SKU          string `db:"sku" json:"sku"`
+	Stock        int    `db:"stock" json:"stock"`
+	Incoming     int    `db:"incoming" json:"incoming"`
+}
+
+// ProductStock is a product's stock per location. Stock is on hand over
+// all locations; InTransit is moving between them and not on hand.
+type ProductStock struct {
+	ProductID int             `json:"productId"`
+	Stock     int             `json:"stock"`
+	InTransit int             `json:"inTransit"`
+	Locations []LocationStock `json:"locations"`
+}
+
+// LocationStockFilter pages through the stock held at a location.
+type LocationStockFilter struct {
+	LocationID int
+	Limit      int
+	Offset     int
+}
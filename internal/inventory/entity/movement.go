@@ -0,0 +1,73 @@
+package entity
+
+import "time"
+
+// Stock movement types.
+const (
+	MovementSale       = "sale"
+	MovementReturn     = "return"
+	MovementAdjustment = "adjustment"
+	MovementReceiving  = "receiving"
+	MovementTransfer   = "transfer"
+)
+
+// StockMovement is one entry of the append-only stock ledger. Quantity is
+// the signed change and Balance the variant's stock at the location after
+// it. A zero LocationID records on the selling location.
+type StockMovement struct {
+	ID         int64     `db:"id_movement" json:"movementId"`
+	ProductID  int       `db:"id_product" json:"productId"`
+	VariantID  int       `db:"id_variant" json:"variantId"`
+	LocationID int       `db:"id_location" json:"locationId"`
+	Type       string    `db:"type" json:"type"`
+	Quantity   int       `db:"quantity" json:"quantity"`
+	Balance    int       `db:"balance" json:"balance"`
+	Reason     string    `db:"reason" json:"reason"`
+	Note       string    `db:"note" json:"note"`
+	Reference  string    `db:"reference" json:"reference"`
+	UserID     *int      `db:"user_id" json:"userId"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+// StockLevel is the stock of one variant.
+type StockLevel struct {
+	ProductID int `db:"id_product" json:"productId"`
+	VariantID int `db:"id_variant" json:"variantId"`
+	Stock     int `db:"stock" json:"stock"`
+}
+
+// StockHistoryFilter narrows down a product's stock history.
+type StockHistoryFilter struct {
+	ProductID  int
+	VariantID  int
+	LocationID int
+	Type       string
+	Limit      int
+	Offset     int
+}
+
+// StockHistory is a page of a product's movements with the stock derived
+// from the whole ledger.
+type StockHistory struct {
+	ProductID int             `json:"productId"`
+	Stock     int             `json:"stock"`
+	Movements []StockMovement `json:"movements"`
+}
+
+func IsMovementType(movementType string) bool {
+	switch movementType {
+	case MovementSale, MovementReturn, MovementAdjustment, MovementReceiving, MovementTransfer:
+		return true
+	}
+	return false
+}
+
+// StaffID turns the id of the acting user into StockMovement.UserID, zero
+// meaning none.
+func StaffID(userID uint32) *int {
+	if userID == 0 {
+		return nil
+	}
+	id := int(userID)
+	return &id
+}
@@ -0,0 +1,143 @@
+package entity
+
+import (
+	"projectsphere/eniqlo-store/pkg/money"
+	"time"
+)
+
+// Stock take statuses.
+const (
+	StockTakeCounting  = "counting"
+	StockTakeApproved  = "approved"
+	StockTakeCancelled = "cancelled"
+)
+
+// StockTakeParam starts a stock take of a location, the selling location
+// when LocationID is zero, limited to a category and its subcategories
+// when Category is set.
+type StockTakeParam struct {
+	LocationID int    `json:"locationId" validate:"omitempty,min=1"`
+	Category   string `json:"category" validate:"omitempty,max=60"`
+	Note       string `json:"note" validate:"max=200"`
+}
+
+// StockTake is a counting session. Lines and Counted summarise its lines.
+type StockTake struct {
+	ID          int        `db:"id_stock_take" json:"stockTakeId"`
+	LocationID  int        `db:"id_location" json:"locationId"`
+	Category    *string    `db:"category" json:"category"`
+	Status      string     `db:"status" json:"status"`
+	Note        string     `db:"note" json:"note"`
+	Lines       int        `db:"lines" json:"lines"`
+	Counted     int        `db:"counted" json:"counted"`
+	CreatedBy   *int       `db:"created_by" json:"createdBy"`
+	ApprovedBy  *int       `db:"approved_by" json:"approvedBy"`
+	ApprovedAt  *time.Time `db:"approved_at" json:"approvedAt"`
+	CancelledBy *int       `db:"cancelled_by" json:"cancelledBy"`
+	CancelledAt *time.Time `db:"cancelled_at" json:"cancelledAt"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+}
+
+// CountParam is one count, usually a scan. SKU matches a variant's SKU or
+// barcode; VariantID is needed when a SKU matches several variants. The
+// quantity is added to the line's count, or replaces it with Replace.
+type CountParam struct {
+	VariantID int    `json:"variantId" validate:"omitempty,min=1"`
+	SKU       string `json:"sku" validate:"max=30"`
+	Quantity  int    `json:"quantity" validate:"min=0,max=100000"`
+	Replace   bool   `json:"replace"`
+}
+
+type CountsParam struct {
+	Counts []CountParam `json:"counts" validate:"required,min=1,max=500,dive"`
+}
+
+// StockTakeLine is a variant being counted. Expected is its stock at the
+// location when the stock take started; Counted is nil until counted.
+type StockTakeLine struct {
+	ProductID int         `db:"id_product" json:"productId"`
+	VariantID int         `db:"id_variant" json:"variantId"`
+	Name      string      `db:"name" json:"name"`
+	SKU       string      `db:"sku" json:"sku"`
+	Barcode   *string     `db:"barcode" json:"barcode"`
+	Price     money.Money `db:"price" json:"price"`
+	Expected  int         `db:"expected" json:"expected"`
+	Counted   *int        `db:"counted" json:"counted"`
+	CountedBy *int        `db:"counted_by" json:"countedBy"`
+	CountedAt *time.Time  `db:"counted_at" json:"countedAt"`
+}
+
+// VarianceLine is a line with its difference to the expected stock, in
+// units and at the variant's price.
+type VarianceLine struct {
+	StockTakeLine
+	Variance      int         `json:"variance"`
+	VarianceValue money.Money `json:"varianceValue"`
+}
+
+// VarianceReport lists the lines of a stock take with their variance.
+// Uncounted lines have no variance unless ZeroUncounted treats them as
+// counted at zero, as approval can.
+type VarianceReport struct {
+	StockTake     StockTake      `json:"stockTake"`
+	Lines         []VarianceLine `json:"lines"`
+	Uncounted     int            `json:"uncounted"`
+	Shortage      int            `json:"shortage"`
+	Surplus       int            `json:"surplus"`
+	VarianceValue money.Money    `json:"varianceValue"`
+}
+
+// ApproveParam decides what approval does with uncounted lines: leave
+// their stock alone, or set it to zero.
+type ApproveParam struct {
+	ZeroUncounted bool `json:"zeroUncounted"`
+}
+
+// Variance computes the variance of lines, treating uncounted lines as
+// counted at zero when zeroUncounted is set. With onlyVariances, lines
+// without variance are left out of the report.
+func Variance(stockTake StockTake, lines []StockTakeLine, zeroUncounted, onlyVariances bool) VarianceReport {
+	report := VarianceReport{StockTake: stockTake, Lines: []VarianceLine{}}
+	values := make([]money.Money, 0, len(lines))
+	for _, line := range lines {
+		variance := VarianceLine{StockTakeLine: line}
+		switch {
+		case line.Counted != nil:
+			variance.Variance = *line.Counted - line.Expected
+		case zeroUncounted:
+			variance.Variance = -line.Expected
+		default:
+			report.Uncounted++
+		}
+		variance.VarianceValue = line.Price.Mul(int64(variance.Variance))
+
+		if variance.Variance < 0 {
+			report.Shortage -= variance.Variance
+		} else {
+			report.Surplus += variance.Variance
+		}
+		values = append(values, variance.VarianceValue)
+
+		if onlyVariances && variance.Variance == 0 && (line.Counted != nil || zeroUncounted) {
+			continue
+		}
+		report.Lines = append(report.Lines, variance)
+	}
+	report.VarianceValue = money.Sum(values...)
+	return report
+}
+
+type StockTakeFilter struct {
+	Status     string
+	LocationID int
+	Limit      int
+	Offset     int
+}
+
+func IsStockTakeStatus(status string) bool {
+	switch status {
+	case StockTakeCounting, StockTakeApproved, StockTakeCancelled:
+		return true
+	}
+	return false
+}
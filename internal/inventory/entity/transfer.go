@@ -0,0 +1,68 @@
+package entity
+
+import "time"
+
+// Transfer statuses. Items leave the source location when the transfer is
+// created and are in transit until it is received or cancelled.
+const (
+	TransferInTransit = "in_transit"
+	TransferReceived  = "received"
+	TransferCancelled = "cancelled"
+)
+
+// MaxTransferItems bounds the lines of one transfer document.
+const MaxTransferItems = 100
+
+type TransferItemParam struct {
+	ProductID int `json:"productId" validate:"required,min=1"`
+	VariantID int `json:"variantId" validate:"omitempty,min=1"`
+	Quantity  int `json:"quantity" validate:"required,min=1,max=100000"`
+}
+
+type TransferParam struct {
+	FromLocationID int                 `json:"fromLocationId" validate:"required,min=1"`
+	ToLocationID   int                 `json:"toLocationId" validate:"required,min=1,nefield=FromLocationID"`
+	Note           string              `json:"note" validate:"max=200"`
+	Items          []TransferItemParam `json:"items" validate:"required,min=1,max=100,dive"`
+}
+
+type TransferItem struct {
+	ID        int    `db:"id_item" json:"itemId"`
+	ProductID int    `db:"id_product" json:"productId"`
+	VariantID int    `db:"id_variant" json:"variantId"`
+	SKU       string `db:"sku" json:"sku"`
+	Quantity  int    `db:"quantity" json:"quantity"`
+}
+
+// Transfer is a document moving stock from one location to another.
+type Transfer struct {
+	ID             int            `db:"id_transfer" json:"transferId"`
+	FromLocationID int            `db:"from_location" json:"fromLocationId"`
+	ToLocationID   int            `db:"to_location" json:"toLocationId"`
+	Status         string         `db:"status" json:"status"`
+	Note           string         `db:"note" json:"note"`
+	CreatedBy      *int           `db:"created_by" json:"createdBy"`
+	ReceivedBy     *int           `db:"received_by" json:"receivedBy"`
+	ReceivedAt     *time.Time     `db:"received_at" json:"receivedAt"`
+	CancelledBy    *int           `db:"cancelled_by" json:"cancelledBy"`
+	CancelledAt    *time.Time     `db:"cancelled_at" json:"cancelledAt"`
+	CreatedAt      time.Time      `db:"created_at" json:"created_at"`
+	Items          []TransferItem `db:"-" json:"items,omitempty"`
+}
+
+// TransferFilter narrows down the transfer list; LocationID matches either
+// end.
+type TransferFilter struct {
+	Status     string
+	LocationID int
+	Limit      int
+	Offset     int
+}
+
+func IsTransferStatus(status string) bool {
+	switch status {
+	case TransferInTransit, TransferReceived, TransferCancelled:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"net/http"
+	"projectsphere/eniqlo-store/internal/inventory/entity"
+	"projectsphere/eniqlo-store/internal/inventory/service"
+	"projectsphere/eniqlo-store/pkg/middleware/auth"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AlertHandler struct {
+	alertSvc service.AlertService
+}
+
+func NewAlertHandler(alertSvc service.AlertService) AlertHandler {
+	return AlertHandler{
+		alertSvc: alertSvc,
+	}
+}
+
+// List returns stock alerts, newest first, e.g. ?status=open&limit=20.
+// Without status it lists the open and acknowledged alerts.
+func (h AlertHandler) List(c *gin.Context) {
+	filter := entity.StockAlertFilter{Status: c.Query("status")}
+	if filter.Status != "" && !entity.IsAlertStatus(filter.Status) {
+		c.Error(msg.BadRequest(msg.ErrInvalidFilterRequest))
+		return
+	}
+
+	var err error
+	if filter.Limit, filter.Offset, err = pageParams(c); err != nil {
+		c.Error(err)
+		return
+	}
+
+	alerts, err := h.alertSvc.List(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetAllResponse), alerts))
+}
+
+func (h AlertHandler) Acknowledge(c *gin.Context) {
+	alertID, userID, err := alertParams(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	alert, err := h.alertSvc.Acknowledge(c.Request.Context(), alertID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.AlertAcknowledgedResponse), alert))
+}
+
+func (h AlertHandler) Resolve(c *gin.Context) {
+	alertID, userID, err := alertParams(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	alert, err := h.alertSvc.Resolve(c.Request.Context(), alertID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.AlertResolvedResponse), alert))
+}
+
+func alertParams(c *gin.Context) (int, uint32, error) {
+	alertID, err := pathID(c, "id")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	userID, err := auth.GetUserIdInsideCtx(c)
+	if err != nil {
+		return 0, 0, err
+	}
+	return alertID, userID, nil
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"projectsphere/eniqlo-store/internal/inventory/entity"
+	"projectsphere/eniqlo-store/internal/inventory/service"
+	"projectsphere/eniqlo-store/pkg/middleware/auth"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"projectsphere/eniqlo-store/pkg/validator"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+type InventoryHandler struct {
+	inventorySvc service.InventoryService
+}
+
+func NewInventoryHandler(inventorySvc service.InventoryService) InventoryHandler {
+	return InventoryHandler{
+		inventorySvc: inventorySvc,
+	}
+}
+
+// StockHistory lists the stock movements of a product, newest first, e.g.
+// ?variantId=3&locationId=1&type=sale&limit=20&offset=0.
+func (h InventoryHandler) StockHistory(c *gin.Context) {
+	productID, err := productIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filter := entity.StockHistoryFilter{
+		ProductID: productID,
+		Type:      c.Query("type"),
+	}
+	if filter.Type != "" && !entity.IsMovementType(filter.Type) {
+		c.Error(msg.BadRequest(msg.ErrInvalidFilterRequest))
+		return
+	}
+	if filter.VariantID, err = idQuery(c, "variantId"); err != nil {
+		c.Error(err)
+		return
+	}
+	if filter.LocationID, err = idQuery(c, "locationId"); err != nil {
+		c.Error(err)
+		return
+	}
+	if filter.Limit, filter.Offset, err = pageParams(c); err != nil {
+		c.Error(err)
+		return
+	}
+
+	history, err := h.inventorySvc.StockHistory(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetAllResponse), history))
+}
+
+// Adjust corrects a variant's stock with {"delta": -2} or {"count": 40},
+// a reason code and an optional note.
+func (h InventoryHandler) Adjust(c *gin.Context) {
+	productID, err := productIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	payload := new(entity.AdjustmentParam)
+	err = c.ShouldBindJSON(payload)
+	if err != nil {
+		c.Error(validator.BindError(err))
+		return
+	}
+
+	userID, err := auth.GetUserIdInsideCtx(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := h.inventorySvc.Adjust(c.Request.Context(), productID, *payload, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("ETag", fmt.Sprintf(`"%d"`, result.Version))
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.StockAdjustedResponse), result))
+}
+
+func productIDParam(c *gin.Context) (int, error) {
+	raw := c.Param("id")
+	if raw == "" {
+		return 0, msg.BadRequest(msg.ErrProductIDMissing)
+	}
+	productID, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, msg.BadRequest(msg.ErrConvertIdToInt)
+	}
+	return productID, nil
+}
+
+// pageParams reads ?limit=20&offset=0.
+func pageParams(c *gin.Context) (limit, offset int, err error) {
+	limit = defaultPageLimit
+	if raw := c.Query("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil {
+			return 0, 0, msg.BadRequest(msg.ErrLimitNotNumber)
+		}
+		if limit < 1 || limit > maxPageLimit {
+			return 0, 0, msg.BadRequest(msg.ErrLimitMustBetween0Until100)
+		}
+	}
+	if raw := c.Query("offset"); raw != "" {
+		offset, err = strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return 0, 0, msg.BadRequest(msg.ErrInvalidFilterRequest)
+		}
+	}
+	return limit, offset, nil
+}
+
+// idQuery reads an optional positive id query parameter, zero when absent.
+func idQuery(c *gin.Context, key string) (int, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return 0, nil
+	}
+	id, err := strconv.Atoi(raw)
+	if err != nil || id <= 0 {
+		return 0, msg.BadRequest(msg.ErrConvertIdToInt)
+	}
+	return id, nil
+}
+
+// pathID reads a positive id path parameter.
+func pathID(c *gin.Context, key string) (int, error) {
+	id, err := strconv.Atoi(c.Param(key))
+	if err != nil || id <= 0 {
+		return 0, msg.BadRequest(msg.ErrConvertIdToInt)
+	}
+	return id, nil
+}
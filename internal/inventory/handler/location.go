@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"net/http"
+	"projectsphere/eniqlo-store/internal/inventory/entity"
+	"projectsphere/eniqlo-store/internal/inventory/service"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"projectsphere/eniqlo-store/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LocationHandler struct {
+	locationSvc service.LocationService
+}
+
+func NewLocationHandler(locationSvc service.LocationService) LocationHandler {
+	return LocationHandler{
+		locationSvc: locationSvc,
+	}
+}
+
+func (h LocationHandler) List(c *gin.Context) {
+	locations, err := h.locationSvc.List(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetAllResponse), locations))
+}
+
+func (h LocationHandler) Get(c *gin.Context) {
+	locationID, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	location, err := h.locationSvc.Get(c.Request.Context(), locationID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetByIDResponse), location))
+}
+
+func (h LocationHandler) Create(c *gin.Context) {
+	payload := new(entity.LocationParam)
+	err := c.ShouldBindJSON(payload)
+	if err != nil {
+		c.Error(validator.BindError(err))
+		return
+	}
+
+	location, err := h.locationSvc.Create(c.Request.Context(), *payload)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, msg.ReturnResult(msg.T(c.Request.Context(), msg.CreateResponse), location))
+}
+
+func (h LocationHandler) Update(c *gin.Context) {
+	locationID, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	payload := new(entity.LocationParam)
+	err = c.ShouldBindJSON(payload)
+	if err != nil {
+		c.Error(validator.BindError(err))
+		return
+	}
+
+	location, err := h.locationSvc.Update(c.Request.Context(), locationID, *payload)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.UpdateResponse), location))
+}
+
+// Stock lists the variants in stock at a location, e.g. ?limit=20&offset=0.
+func (h LocationHandler) Stock(c *gin.Context) {
+	locationID, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filter := entity.LocationStockFilter{LocationID: locationID}
+	if filter.Limit, filter.Offset, err = pageParams(c); err != nil {
+		c.Error(err)
+		return
+	}
+
+	stock, err := h.locationSvc.LocationStock(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetAllResponse), stock))
+}
+
+// ProductStock returns a product's stock per location and in transit.
+func (h LocationHandler) ProductStock(c *gin.Context) {
+	productID, err := productIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	stock, err := h.locationSvc.ProductStock(c.Request.Context(), productID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetDataResponse), stock))
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"net/http"
+	"projectsphere/eniqlo-store/internal/inventory/entity"
+	"projectsphere/eniqlo-store/internal/inventory/service"
+	"projectsphere/eniqlo-store/pkg/middleware/auth"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"projectsphere/eniqlo-store/pkg/validator"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StockTakeHandler struct {
+	stockTakeSvc service.StockTakeService
+}
+
+func NewStockTakeHandler(stockTakeSvc service.StockTakeService) StockTakeHandler {
+	return StockTakeHandler{
+		stockTakeSvc: stockTakeSvc,
+	}
+}
+
+// List returns stock takes, newest first, e.g.
+// ?status=counting&locationId=1&limit=20&offset=0.
+func (h StockTakeHandler) List(c *gin.Context) {
+	filter := entity.StockTakeFilter{Status: c.Query("status")}
+	if filter.Status != "" && !entity.IsStockTakeStatus(filter.Status) {
+		c.Error(msg.BadRequest(msg.ErrInvalidFilterRequest))
+		return
+	}
+
+	var err error
+	if filter.LocationID, err = idQuery(c, "locationId"); err != nil {
+		c.Error(err)
+		return
+	}
+	if filter.Limit, filter.Offset, err = pageParams(c); err != nil {
+		c.Error(err)
+		return
+	}
+
+	stockTakes, err := h.stockTakeSvc.List(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetAllResponse), stockTakes))
+}
+
+func (h StockTakeHandler) Get(c *gin.Context) {
+	stockTakeID, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	stockTake, err := h.stockTakeSvc.Get(c.Request.Context(), stockTakeID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetByIDResponse), stockTake))
+}
+
+// Create starts a stock take and freezes the expected stock.
+func (h StockTakeHandler) Create(c *gin.Context) {
+	payload := new(entity.StockTakeParam)
+	err := c.ShouldBindJSON(payload)
+	if err != nil {
+		c.Error(validator.BindError(err))
+		return
+	}
+
+	userID, err := auth.GetUserIdInsideCtx(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	stockTake, err := h.stockTakeSvc.Create(c.Request.Context(), *payload, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, msg.ReturnResult(msg.T(c.Request.Context(), msg.StockTakeCreatedResponse), stockTake))
+}
+
+// Counts records a batch of counts and returns the lines it changed.
+func (h StockTakeHandler) Counts(c *gin.Context) {
+	stockTakeID, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	payload := new(entity.CountsParam)
+	err = c.ShouldBindJSON(payload)
+	if err != nil {
+		c.Error(validator.BindError(err))
+		return
+	}
+
+	userID, err := auth.GetUserIdInsideCtx(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	lines, err := h.stockTakeSvc.RecordCounts(c.Request.Context(), stockTakeID, payload.Counts, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.CountsRecordedResponse), lines))
+}
+
+// Variance returns the variance report, with ?onlyVariances=true leaving
+// out the lines counted as expected.
+func (h StockTakeHandler) Variance(c *gin.Context) {
+	stockTakeID, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var onlyVariances bool
+	if raw := c.Query("onlyVariances"); raw != "" {
+		if onlyVariances, err = strconv.ParseBool(raw); err != nil {
+			c.Error(msg.BadRequest(msg.ErrInvalidFilterRequest))
+			return
+		}
+	}
+
+	report, err := h.stockTakeSvc.Variance(c.Request.Context(), stockTakeID, onlyVariances)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetByIDResponse), report))
+}
+
+// Approve posts the variances to stock. The body is optional.
+func (h StockTakeHandler) Approve(c *gin.Context) {
+	stockTakeID, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	payload := new(entity.ApproveParam)
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(payload); err != nil {
+			c.Error(validator.BindError(err))
+			return
+		}
+	}
+
+	userID, err := auth.GetUserIdInsideCtx(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	report, err := h.stockTakeSvc.Approve(c.Request.Context(), stockTakeID, *payload, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.StockTakeApprovedResponse), report))
+}
+
+// Cancel ends a stock take without changing stock.
+func (h StockTakeHandler) Cancel(c *gin.Context) {
+	stockTakeID, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	userID, err := auth.GetUserIdInsideCtx(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	stockTake, err := h.stockTakeSvc.Cancel(c.Request.Context(), stockTakeID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.StockTakeCancelledResponse), stockTake))
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"projectsphere/eniqlo-store/internal/inventory/entity"
+	"projectsphere/eniqlo-store/internal/inventory/service"
+	"projectsphere/eniqlo-store/pkg/middleware/auth"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"projectsphere/eniqlo-store/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TransferHandler struct {
+	transferSvc service.TransferService
+}
+
+func NewTransferHandler(transferSvc service.TransferService) TransferHandler {
+	return TransferHandler{
+		transferSvc: transferSvc,
+	}
+}
+
+// List returns transfers, newest first, e.g.
+// ?status=in_transit&locationId=2&limit=20&offset=0.
+func (h TransferHandler) List(c *gin.Context) {
+	filter := entity.TransferFilter{Status: c.Query("status")}
+	if filter.Status != "" && !entity.IsTransferStatus(filter.Status) {
+		c.Error(msg.BadRequest(msg.ErrInvalidFilterRequest))
+		return
+	}
+
+	var err error
+	if filter.LocationID, err = idQuery(c, "locationId"); err != nil {
+		c.Error(err)
+		return
+	}
+	if filter.Limit, filter.Offset, err = pageParams(c); err != nil {
+		c.Error(err)
+		return
+	}
+
+	transfers, err := h.transferSvc.List(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetAllResponse), transfers))
+}
+
+func (h TransferHandler) Get(c *gin.Context) {
+	transferID, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	transfer, err := h.transferSvc.Get(c.Request.Context(), transferID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetByIDResponse), transfer))
+}
+
+// Create moves stock out of the source location into transit.
+func (h TransferHandler) Create(c *gin.Context) {
+	payload := new(entity.TransferParam)
+	err := c.ShouldBindJSON(payload)
+	if err != nil {
+		c.Error(validator.BindError(err))
+		return
+	}
+
+	userID, err := auth.GetUserIdInsideCtx(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	transfer, err := h.transferSvc.Create(c.Request.Context(), *payload, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, msg.ReturnResult(msg.T(c.Request.Context(), msg.TransferCreatedResponse), transfer))
+}
+
+// Receive puts the stock in transit into the destination location.
+func (h TransferHandler) Receive(c *gin.Context) {
+	h.finish(c, h.transferSvc.Receive, msg.TransferReceivedResponse)
+}
+
+// Cancel returns the stock in transit to the source location.
+func (h TransferHandler) Cancel(c *gin.Context) {
+	h.finish(c, h.transferSvc.Cancel, msg.TransferCancelledResponse)
+}
+
+func (h TransferHandler) finish(c *gin.Context, finish func(context.Context, int, uint32) (entity.Transfer, error), message string) {
+	transferID, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	userID, err := auth.GetUserIdInsideCtx(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	transfer, err := finish(c.Request.Context(), transferID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), message), transfer))
+}
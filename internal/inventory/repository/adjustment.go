@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"projectsphere/eniqlo-store/internal/inventory/entity"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+)
+
+// Adjust applies a manual adjustment at a location under the product lock,
+// so the bounds are checked against the stock the movement is recorded on.
+// A count is the stock at the location; one equal to it records nothing.
+func (r MovementRepo) Adjust(ctx context.Context, productID int, param entity.AdjustmentParam, userID uint32) (entity.AdjustmentResult, error) {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return entity.AdjustmentResult{}, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	level, err := LockVariant(ctx, tx, productID, param.VariantID)
+	if err != nil {
+		return entity.AdjustmentResult{}, err
+	}
+
+	locationID, err := ResolveLocation(ctx, tx, param.LocationID)
+	if err != nil {
+		return entity.AdjustmentResult{}, err
+	}
+	atLocation, err := locationStock(ctx, tx, level.VariantID, locationID)
+	if err != nil {
+		return entity.AdjustmentResult{}, err
+	}
+
+	delta := 0
+	if param.Count != nil {
+		delta = *param.Count - atLocation
+	} else {
+		delta = *param.Delta
+	}
+	if err := checkDirection(param.Reason, delta); err != nil {
+		return entity.AdjustmentResult{}, err
+	}
+
+	result := entity.AdjustmentResult{Stock: level.Stock + delta}
+	if atLocation+delta < 0 {
+		return entity.AdjustmentResult{}, msg.Conflict(msg.ErrStockNegative)
+	}
+	if result.Stock > entity.MaxStock {
+		return entity.AdjustmentResult{}, msg.Conflict(msg.ErrStockAboveMax)
+	}
+
+	if delta == 0 {
+		err = tx.GetContext(ctx, &result.Version, `SELECT version FROM "products" WHERE id_product = $1`, productID)
+		if err != nil {
+			return entity.AdjustmentResult{}, msg.InternalServerError(err.Error())
+		}
+		return result, nil
+	}
+
+	movement, err := RecordMovement(ctx, tx, entity.StockMovement{
+		ProductID:  level.ProductID,
+		VariantID:  level.VariantID,
+		LocationID: locationID,
+		Type:       entity.MovementAdjustment,
+		Quantity:   delta,
+		Reason:     param.Reason,
+		Note:       param.Note,
+		UserID:     entity.StaffID(userID),
+	})
+	if err != nil {
+		return entity.AdjustmentResult{}, err
+	}
+	result.Movement = &movement
+
+	err = tx.GetContext(ctx, &result.Version, `
+        UPDATE "products"
+        SET version = version + 1
+        WHERE id_product = $1
+        RETURNING version
+    `, productID)
+	if err != nil {
+		return entity.AdjustmentResult{}, msg.InternalServerError(err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.AdjustmentResult{}, msg.InternalServerError(err.Error())
+	}
+
+	return result, nil
+}
+
+// checkDirection keeps damage and theft to decreases and found stock to
+// increases; a recount may go either way.
+func checkDirection(reason string, delta int) error {
+	switch {
+	case (reason == entity.ReasonDamage || reason == entity.ReasonTheft) && delta > 0:
+		return msg.Validation(msg.NewFieldError("reason", "direction", msg.ValDecreaseOnly, "reason"))
+	case reason == entity.ReasonFound && delta < 0:
+		return msg.Validation(msg.NewFieldError("reason", "direction", msg.ValIncreaseOnly, "reason"))
+	}
+	return nil
+}
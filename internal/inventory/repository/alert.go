@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"projectsphere/eniqlo-store/internal/inventory/entity"
+	"projectsphere/eniqlo-store/pkg/database"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"time"
+)
+
+type AlertRepo struct {
+	dbConnector database.PostgresConnector
+}
+
+func NewAlertRepo(dbConnector database.PostgresConnector) AlertRepo {
+	return AlertRepo{
+		dbConnector: dbConnector,
+	}
+}
+
+const alertColumns = `a.id_alert, a.id_product, p.name, p.sku, a.status, a.stock, p.stock AS current_stock, a.reorder_point,
+            a.reorder_quantity, a.notified_at, a.acknowledged_at, a.acknowledged_by, a.resolved_at, a.resolved_by, a.created_at`
+
+// EvaluateAlerts checks the products updated after since, which includes
+// every stock movement. It resolves the active alerts of products back
+// above their reorder point and opens one for products at or below it,
+// unless one is active already. It returns the database time the check
+// started, to pass as since next time.
+func (r AlertRepo) EvaluateAlerts(ctx context.Context, since time.Time) (opened, resolved int64, now time.Time, err error) {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, 0, time.Time{}, err
+	}
+	defer tx.Rollback()
+
+	if err = tx.GetContext(ctx, &now, `SELECT CURRENT_TIMESTAMP`); err != nil {
+		return 0, 0, time.Time{}, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+        UPDATE "stock_alerts" a
+        SET status = 'resolved', resolved_at = CURRENT_TIMESTAMP
+        FROM "products" p
+        WHERE p.id_product = a.id_product AND a.status <> 'resolved' AND p.updated_at > $1
+            AND (p.reorder_point = 0 OR p.stock > p.reorder_point)
+    `, since)
+	if err != nil {
+		return 0, 0, time.Time{}, err
+	}
+	resolved, _ = result.RowsAffected()
+
+	result, err = tx.ExecContext(ctx, `
+        INSERT INTO "stock_alerts" (id_product, stock, reorder_point, reorder_quantity)
+        SELECT id_product, stock, reorder_point, reorder_quantity
+        FROM "products"
+        WHERE updated_at > $1 AND reorder_point > 0 AND stock <= reorder_point
+        ON CONFLICT (id_product) WHERE status <> 'resolved' DO NOTHING
+    `, since)
+	if err != nil {
+		return 0, 0, time.Time{}, err
+	}
+	opened, _ = result.RowsAffected()
+
+	if err = tx.Commit(); err != nil {
+		return 0, 0, time.Time{}, err
+	}
+
+	return opened, resolved, now, nil
+}
+
+// ClaimUnnotified marks up to limit open alerts nobody was notified about
+// yet as notified and returns them. Concurrent evaluators claim different
+// alerts; call ReleaseNotification when delivery fails.
+func (r AlertRepo) ClaimUnnotified(ctx context.Context, limit int) ([]entity.StockAlert, error) {
+	var alerts []entity.StockAlert
+	err := r.dbConnector.DB.SelectContext(ctx, &alerts, `
+        UPDATE "stock_alerts" a
+        SET notified_at = CURRENT_TIMESTAMP
+        FROM "products" p
+        WHERE p.id_product = a.id_product AND a.id_alert IN (
+            SELECT id_alert
+            FROM "stock_alerts"
+            WHERE status = 'open' AND notified_at IS NULL
+            ORDER BY id_alert
+            LIMIT $1
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING `+alertColumns, limit)
+	if err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+func (r AlertRepo) ReleaseNotification(ctx context.Context, alertID int) error {
+	_, err := r.dbConnector.DB.ExecContext(ctx, `UPDATE "stock_alerts" SET notified_at = NULL WHERE id_alert = $1`, alertID)
+	return err
+}
+
+// ListAlerts returns a page of alerts, newest first.
+func (r AlertRepo) ListAlerts(ctx context.Context, filter entity.StockAlertFilter) ([]entity.StockAlert, error) {
+	condition := "a.status <> 'resolved'"
+	args := []interface{}{}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		condition = fmt.Sprintf("a.status = $%d", len(args))
+	}
+
+	alerts := []entity.StockAlert{}
+	err := r.dbConnector.DB.SelectContext(ctx, &alerts, fmt.Sprintf(`
+        SELECT `+alertColumns+`
+        FROM "stock_alerts" a
+        JOIN "products" p ON p.id_product = a.id_product
+        WHERE %s
+        ORDER BY a.id_alert DESC
+        LIMIT %d OFFSET %d
+    `, condition, filter.Limit, filter.Offset), args...)
+	if err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+
+	return alerts, nil
+}
+
+// AcknowledgeAlert records that userID is handling an alert. Acknowledging
+// again keeps the first acknowledgement.
+func (r AlertRepo) AcknowledgeAlert(ctx context.Context, alertID int, userID uint32) (entity.StockAlert, error) {
+	return r.changeAlert(ctx, alertID, `
+        status = 'acknowledged',
+        acknowledged_at = COALESCE(a.acknowledged_at, CURRENT_TIMESTAMP),
+        acknowledged_by = COALESCE(a.acknowledged_by, $2)
+    `, entity.StaffID(userID))
+}
+
+// ResolveAlert closes an alert by hand, e.g. once a purchase order is
+// placed. The next stock change at or below the reorder point opens a new
+// one.
+func (r AlertRepo) ResolveAlert(ctx context.Context, alertID int, userID uint32) (entity.StockAlert, error) {
+	return r.changeAlert(ctx, alertID, `
+        status = 'resolved',
+        resolved_at = CURRENT_TIMESTAMP,
+        resolved_by = $2
+    `, entity.StaffID(userID))
+}
+
+// changeAlert applies sets to an alert that is not resolved yet.
+func (r AlertRepo) changeAlert(ctx context.Context, alertID int, sets string, userID *int) (entity.StockAlert, error) {
+	var alert entity.StockAlert
+	err := r.dbConnector.DB.GetContext(ctx, &alert, `
+        UPDATE "stock_alerts" a
+        SET `+sets+`
+        FROM "products" p
+        WHERE p.id_product = a.id_product AND a.id_alert = $1 AND a.status <> 'resolved'
+        RETURNING `+alertColumns, alertID, userID)
+	if err == nil {
+		return alert, nil
+	}
+	if err != sql.ErrNoRows {
+		return entity.StockAlert{}, msg.InternalServerError(err.Error())
+	}
+
+	var exists bool
+	err = r.dbConnector.DB.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM "stock_alerts" WHERE id_alert = $1)`, alertID)
+	if err != nil {
+		return entity.StockAlert{}, msg.InternalServerError(err.Error())
+	}
+	if !exists {
+		return entity.StockAlert{}, msg.NotFound(msg.ErrAlertNotFound)
+	}
+	return entity.StockAlert{}, msg.Conflict(msg.ErrAlertResolved)
+}
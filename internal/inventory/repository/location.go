@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"projectsphere/eniqlo-store/internal/inventory/entity"
+	"projectsphere/eniqlo-store/pkg/database"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"strings"
+)
+
+type LocationRepo struct {
+	dbConnector database.PostgresConnector
+}
+
+func NewLocationRepo(dbConnector database.PostgresConnector) LocationRepo {
+	return LocationRepo{
+		dbConnector: dbConnector,
+	}
+}
+
+const locationColumns = `id_location, code, name, type, is_active, created_at, updated_at`
+
+func (r LocationRepo) ListLocations(ctx context.Context) ([]entity.Location, error) {
+	locations := []entity.Location{}
+	err := r.dbConnector.DB.SelectContext(ctx, &locations, `SELECT `+locationColumns+` FROM "locations" ORDER BY id_location`)
+	if err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+	return locations, nil
+}
+
+func (r LocationRepo) GetLocation(ctx context.Context, locationID int) (entity.Location, error) {
+	var location entity.Location
+	err := r.dbConnector.DB.GetContext(ctx, &location, `SELECT `+locationColumns+` FROM "locations" WHERE id_location = $1`, locationID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return entity.Location{}, msg.NotFound(msg.ErrLocationNotFound)
+		}
+		return entity.Location{}, msg.InternalServerError(err.Error())
+	}
+	return location, nil
+}
+
+func (r LocationRepo) CreateLocation(ctx context.Context, param entity.Location) (entity.Location, error) {
+	var location entity.Location
+	err := r.dbConnector.DB.GetContext(ctx, &location, `
+        INSERT INTO "locations" (code, name, type, is_active)
+        VALUES ($1, $2, $3, $4)
+        RETURNING `+locationColumns,
+		param.Code, param.Name, param.Type, param.IsActive)
+	if err != nil {
+		return entity.Location{}, locationWriteError(err)
+	}
+	return location, nil
+}
+
+func (r LocationRepo) UpdateLocation(ctx context.Context, param entity.Location) (entity.Location, error) {
+	var location entity.Location
+	err := r.dbConnector.DB.GetContext(ctx, &location, `
+        UPDATE "locations"
+        SET code = $1, name = $2, type = $3, is_active = $4, updated_at = CURRENT_TIMESTAMP
+        WHERE id_location = $5
+        RETURNING `+locationColumns,
+		param.Code, param.Name, param.Type, param.IsActive, param.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return entity.Location{}, msg.NotFound(msg.ErrLocationNotFound)
+		}
+		return entity.Location{}, locationWriteError(err)
+	}
+	return location, nil
+}
+
+// ProductStock returns the stock of every variant of a product per
+// location, leaving out locations where it has none and none is coming.
+func (r LocationRepo) ProductStock(ctx context.Context, productID int) (entity.ProductStock, error) {
+	var exists bool
+	err := r.dbConnector.DB.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM "products" WHERE id_product = $1)`, productID)
+	if err != nil {
+		return entity.ProductStock{}, msg.InternalServerError(err.Error())
+	}
+	if !exists {
+		return entity.ProductStock{}, msg.NotFound(msg.ErrProductNotFound)
+	}
+
+	stock := entity.ProductStock{ProductID: productID, Locations: []entity.LocationStock{}}
+	err = r.dbConnector.DB.SelectContext(ctx, &stock.Locations, `
+        SELECT l.id_location, l.code AS location_code, v.id_product, p.name, v.id_variant, v.sku,
+            COALESCE(s.stock, 0) AS stock, COALESCE(transit.incoming, 0) AS incoming
+        FROM "product_variants" v
+        JOIN "products" p ON p.id_product = v.id_product
+        CROSS JOIN "locations" l
+        LEFT JOIN "variant_stocks" s ON s.id_variant = v.id_variant AND s.id_location = l.id_location
+        LEFT JOIN (
+            SELECT i.id_variant, t.to_location, SUM(i.quantity) AS incoming
+            FROM "stock_transfer_items" i
+            JOIN "stock_transfers" t ON t.id_transfer = i.id_transfer
+            WHERE t.status = 'in_transit'
+            GROUP BY i.id_variant, t.to_location
+        ) transit ON transit.id_variant = v.id_variant AND transit.to_location = l.id_location
+        WHERE v.id_product = $1 AND (s.stock > 0 OR transit.incoming > 0)
+        ORDER BY l.id_location, v.position, v.id_variant
+    `, productID)
+	if err != nil {
+		return entity.ProductStock{}, msg.InternalServerError(err.Error())
+	}
+
+	for _, level := range stock.Locations {
+		stock.Stock += level.Stock
+		stock.InTransit += level.Incoming
+	}
+	return stock, nil
+}
+
+// LocationStock returns a page of the variants in stock at a location,
+// ordered by product name.
+func (r LocationRepo) LocationStock(ctx context.Context, filter entity.LocationStockFilter) ([]entity.LocationStock, error) {
+	if _, err := r.GetLocation(ctx, filter.LocationID); err != nil {
+		return nil, err
+	}
+
+	stock := []entity.LocationStock{}
+	err := r.dbConnector.DB.SelectContext(ctx, &stock, fmt.Sprintf(`
+        SELECT l.id_location, l.code AS location_code, v.id_product, p.name, v.id_variant, v.sku, s.stock, 0 AS incoming
+        FROM "variant_stocks" s
+        JOIN "locations" l ON l.id_location = s.id_location
+        JOIN "product_variants" v ON v.id_variant = s.id_variant
+        JOIN "products" p ON p.id_product = v.id_product
+        WHERE s.id_location = $1 AND s.stock > 0
+        ORDER BY p.name, v.id_product, v.position, v.id_variant
+        LIMIT %d OFFSET %d
+    `, filter.Limit, filter.Offset), filter.LocationID)
+	if err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+	return stock, nil
+}
+
+func locationWriteError(err error) error {
+	switch {
+	case strings.Contains(err.Error(), "locations_code_key"):
+		return msg.Conflict(msg.ErrLocationCodeExists)
+	default:
+		return msg.InternalServerError(err.Error())
+	}
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"projectsphere/eniqlo-store/internal/inventory/entity"
+	"projectsphere/eniqlo-store/pkg/database"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type MovementRepo struct {
+	dbConnector database.PostgresConnector
+}
+
+func NewMovementRepo(dbConnector database.PostgresConnector) MovementRepo {
+	return MovementRepo{
+		dbConnector: dbConnector,
+	}
+}
+
+// SellingLocation is the code of the location checkouts sell from and
+// stock changes without a location are recorded on.
+var SellingLocation = "store"
+
+const movementColumns = `id_movement, id_product, id_variant, id_location, type, quantity, balance, reason, note, reference, user_id, created_at`
+
+// RecordMovement appends movement to the ledger and applies its quantity
+// to the cached stock of the variant at its location, of the variant and
+// of its product in tx, so the caches always equal the ledger. Stock may
+// not go below zero anywhere. The caller locks the product and bumps its
+// version.
+func RecordMovement(ctx context.Context, tx *sqlx.Tx, movement entity.StockMovement) (entity.StockMovement, error) {
+	var err error
+	movement.LocationID, err = ResolveLocation(ctx, tx, movement.LocationID)
+	if err != nil {
+		return entity.StockMovement{}, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+        UPDATE "product_variants"
+        SET stock = stock + $1, updated_at = CURRENT_TIMESTAMP
+        WHERE id_variant = $2 AND id_product = $3
+    `, movement.Quantity, movement.VariantID, movement.ProductID)
+	if err != nil {
+		return entity.StockMovement{}, stockWriteError(err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return entity.StockMovement{}, msg.NotFound(msg.ErrVariantNotFound)
+	}
+
+	// the product lock keeps the row from appearing concurrently, and the
+	// check constraint is tested before ON CONFLICT, so update first
+	err = tx.GetContext(ctx, &movement.Balance, `
+        UPDATE "variant_stocks"
+        SET stock = stock + $1
+        WHERE id_variant = $2 AND id_location = $3
+        RETURNING stock
+    `, movement.Quantity, movement.VariantID, movement.LocationID)
+	if err == sql.ErrNoRows {
+		err = tx.GetContext(ctx, &movement.Balance, `
+            INSERT INTO "variant_stocks" (id_variant, id_location, stock)
+            VALUES ($1, $2, $3)
+            RETURNING stock
+        `, movement.VariantID, movement.LocationID, movement.Quantity)
+	}
+	if err != nil {
+		return entity.StockMovement{}, stockWriteError(err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE "products"
+        SET stock = stock + $1, updated_at = CURRENT_TIMESTAMP
+        WHERE id_product = $2
+    `, movement.Quantity, movement.ProductID)
+	if err != nil {
+		return entity.StockMovement{}, msg.InternalServerError(err.Error())
+	}
+
+	err = tx.GetContext(ctx, &movement, `
+        INSERT INTO "stock_movements" (id_product, id_variant, id_location, type, quantity, balance, reason, note, reference, user_id)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        RETURNING `+movementColumns,
+		movement.ProductID, movement.VariantID, movement.LocationID, movement.Type, movement.Quantity, movement.Balance,
+		movement.Reason, movement.Note, movement.Reference, movement.UserID)
+	if err != nil {
+		return entity.StockMovement{}, msg.InternalServerError(err.Error())
+	}
+
+	return movement, nil
+}
+
+// ResolveLocation returns locationID after checking it exists, or the id of
+// SellingLocation when locationID is zero.
+func ResolveLocation(ctx context.Context, db sqlx.QueryerContext, locationID int) (int, error) {
+	var id int
+	err := sqlx.GetContext(ctx, db, &id, `
+        SELECT id_location
+        FROM "locations"
+        WHERE CASE WHEN $1 = 0 THEN code = $2 ELSE id_location = $1 END
+    `, locationID, SellingLocation)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, msg.NotFound(msg.ErrLocationNotFound)
+		}
+		return 0, msg.InternalServerError(err.Error())
+	}
+	return id, nil
+}
+
+// locationStock returns the stock of a variant at a location.
+func locationStock(ctx context.Context, tx *sqlx.Tx, variantID, locationID int) (int, error) {
+	var stock int
+	err := tx.GetContext(ctx, &stock, `
+        SELECT COALESCE((SELECT stock FROM "variant_stocks" WHERE id_variant = $1 AND id_location = $2), 0)
+    `, variantID, locationID)
+	if err != nil {
+		return 0, msg.InternalServerError(err.Error())
+	}
+	return stock, nil
+}
+
+// LockVariant locks a product for a stock change and returns the stock of
+// its variant variantID, or of its only variant when variantID is zero.
+func LockVariant(ctx context.Context, tx *sqlx.Tx, productID, variantID int) (entity.StockLevel, error) {
+	var locked int
+	err := tx.GetContext(ctx, &locked, `SELECT id_product FROM "products" WHERE id_product = $1 FOR UPDATE`, productID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return entity.StockLevel{}, msg.NotFound(msg.ErrProductNotFound)
+		}
+		return entity.StockLevel{}, msg.InternalServerError(err.Error())
+	}
+
+	var levels []entity.StockLevel
+	err = tx.SelectContext(ctx, &levels, `
+        SELECT id_product, id_variant, stock
+        FROM "product_variants"
+        WHERE id_product = $1 AND ($2 = 0 OR id_variant = $2)
+    `, productID, variantID)
+	if err != nil {
+		return entity.StockLevel{}, msg.InternalServerError(err.Error())
+	}
+
+	switch {
+	case len(levels) == 1:
+		return levels[0], nil
+	case variantID != 0 || len(levels) == 0:
+		return entity.StockLevel{}, msg.NotFound(msg.ErrVariantNotFound)
+	default:
+		return entity.StockLevel{}, msg.Validation(msg.NewFieldError("variantId", "required", msg.ValVariantRequired, "variantId"))
+	}
+}
+
+// StockHistory returns a page of a product's movements, newest first, and
+// its stock summed from the ledger.
+func (r MovementRepo) StockHistory(ctx context.Context, filter entity.StockHistoryFilter) (entity.StockHistory, error) {
+	var exists bool
+	err := r.dbConnector.DB.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM "products" WHERE id_product = $1)`, filter.ProductID)
+	if err != nil {
+		return entity.StockHistory{}, msg.InternalServerError(err.Error())
+	}
+	if !exists {
+		return entity.StockHistory{}, msg.NotFound(msg.ErrProductNotFound)
+	}
+
+	conditions := []string{"id_product = $1"}
+	args := []interface{}{filter.ProductID}
+	if filter.VariantID != 0 {
+		args = append(args, filter.VariantID)
+		conditions = append(conditions, fmt.Sprintf("id_variant = $%d", len(args)))
+	}
+	if filter.LocationID != 0 {
+		args = append(args, filter.LocationID)
+		conditions = append(conditions, fmt.Sprintf("id_location = $%d", len(args)))
+	}
+	where := strings.Join(conditions, " AND ")
+
+	history := entity.StockHistory{ProductID: filter.ProductID}
+	err = r.dbConnector.DB.GetContext(ctx, &history.Stock, `SELECT COALESCE(SUM(quantity), 0) FROM "stock_movements" WHERE `+where, args...)
+	if err != nil {
+		return entity.StockHistory{}, msg.InternalServerError(err.Error())
+	}
+
+	if filter.Type != "" {
+		args = append(args, filter.Type)
+		where += fmt.Sprintf(" AND type = $%d", len(args))
+	}
+
+	history.Movements = []entity.StockMovement{}
+	err = r.dbConnector.DB.SelectContext(ctx, &history.Movements, fmt.Sprintf(`
+        SELECT `+movementColumns+`
+        FROM "stock_movements"
+        WHERE %s
+        ORDER BY id_movement DESC
+        LIMIT %d OFFSET %d
+    `, where, filter.Limit, filter.Offset), args...)
+	if err != nil {
+		return entity.StockHistory{}, msg.InternalServerError(err.Error())
+	}
+
+	return history, nil
+}
+
+func stockWriteError(err error) error {
+	switch {
+	case strings.Contains(err.Error(), "product_variants_stock_check"), strings.Contains(err.Error(), "variant_stocks_stock_check"):
+		return msg.Conflict(msg.ErrInsufficientStock)
+	default:
+		return msg.InternalServerError(err.Error())
+	}
+}
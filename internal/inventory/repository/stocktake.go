@@ -0,0 +1,371 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"projectsphere/eniqlo-store/internal/inventory/entity"
+	"projectsphere/eniqlo-store/pkg/database"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"sort"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type StockTakeRepo struct {
+	dbConnector database.PostgresConnector
+}
+
+func NewStockTakeRepo(dbConnector database.PostgresConnector) StockTakeRepo {
+	return StockTakeRepo{
+		dbConnector: dbConnector,
+	}
+}
+
+const stockTakeColumns = `t.id_stock_take, t.id_location, t.category, t.status, t.note, t.created_by, t.approved_by, t.approved_at,
+            t.cancelled_by, t.cancelled_at, t.created_at,
+            (SELECT COUNT(*) FROM "stock_take_lines" l WHERE l.id_stock_take = t.id_stock_take) AS lines,
+            (SELECT COUNT(l.counted) FROM "stock_take_lines" l WHERE l.id_stock_take = t.id_stock_take) AS counted`
+
+// CreateStockTake starts a stock take of a location and freezes the stock
+// of every variant of the products in categories, or of all products when
+// categories is nil, as the expected quantities.
+func (r StockTakeRepo) CreateStockTake(ctx context.Context, param entity.StockTakeParam, categories []string, userID uint32) (entity.StockTake, error) {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return entity.StockTake{}, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	locationID, err := ResolveLocation(ctx, tx, param.LocationID)
+	if err != nil {
+		return entity.StockTake{}, err
+	}
+
+	var category *string
+	if param.Category != "" {
+		category = &param.Category
+	}
+
+	var stockTakeID int
+	err = tx.GetContext(ctx, &stockTakeID, `
+        INSERT INTO "stock_takes" (id_location, category, note, created_by)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id_stock_take
+    `, locationID, category, param.Note, entity.StaffID(userID))
+	if err != nil {
+		return entity.StockTake{}, msg.InternalServerError(err.Error())
+	}
+
+	// one statement, so the expected stock is a consistent snapshot
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO "stock_take_lines" (id_stock_take, id_variant, id_product, expected)
+        SELECT $1, v.id_variant, v.id_product, COALESCE(s.stock, 0)
+        FROM "product_variants" v
+        JOIN "products" p ON p.id_product = v.id_product
+        LEFT JOIN "variant_stocks" s ON s.id_variant = v.id_variant AND s.id_location = $2
+        WHERE $3::varchar[] IS NULL OR p.category = ANY($3)
+    `, stockTakeID, locationID, pq.Array(categories))
+	if err != nil {
+		return entity.StockTake{}, msg.InternalServerError(err.Error())
+	}
+
+	stockTake, err := getStockTake(ctx, tx, stockTakeID, "")
+	if err != nil {
+		return entity.StockTake{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.StockTake{}, msg.InternalServerError(err.Error())
+	}
+
+	return stockTake, nil
+}
+
+func (r StockTakeRepo) GetStockTake(ctx context.Context, stockTakeID int) (entity.StockTake, error) {
+	return getStockTake(ctx, r.dbConnector.DB, stockTakeID, "")
+}
+
+// ListStockTakes returns a page of stock takes, newest first.
+func (r StockTakeRepo) ListStockTakes(ctx context.Context, filter entity.StockTakeFilter) ([]entity.StockTake, error) {
+	conditions := "TRUE"
+	var args []interface{}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions += fmt.Sprintf(" AND t.status = $%d", len(args))
+	}
+	if filter.LocationID != 0 {
+		args = append(args, filter.LocationID)
+		conditions += fmt.Sprintf(" AND t.id_location = $%d", len(args))
+	}
+
+	stockTakes := []entity.StockTake{}
+	err := r.dbConnector.DB.SelectContext(ctx, &stockTakes, fmt.Sprintf(`
+        SELECT `+stockTakeColumns+`
+        FROM "stock_takes" t
+        WHERE %s
+        ORDER BY t.id_stock_take DESC
+        LIMIT %d OFFSET %d
+    `, conditions, filter.Limit, filter.Offset), args...)
+	if err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+	return stockTakes, nil
+}
+
+func (r StockTakeRepo) StockTakeLines(ctx context.Context, stockTakeID int) ([]entity.StockTakeLine, error) {
+	return stockTakeLines(ctx, r.dbConnector.DB, stockTakeID, nil)
+}
+
+// RecordCounts applies counts to the lines of a stock take that is still
+// counting. Every count is matched first, so a batch with an unknown or
+// ambiguous SKU changes nothing. Several staff may count at once: counts
+// add up unless they replace the line's count. It returns the changed
+// lines.
+func (r StockTakeRepo) RecordCounts(ctx context.Context, stockTakeID int, counts []entity.CountParam, userID uint32) ([]entity.StockTakeLine, error) {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	// a shared lock lets counts run concurrently but makes approval wait
+	// for them
+	if _, err := getStockTake(ctx, tx, stockTakeID, "FOR SHARE OF t"); err != nil {
+		return nil, err
+	}
+
+	type lineCount struct {
+		variantID int
+		count     entity.CountParam
+	}
+	lineCounts := make([]lineCount, 0, len(counts))
+	var details []msg.FieldError
+	for i, count := range counts {
+		var variantIDs []int
+		err = tx.SelectContext(ctx, &variantIDs, `
+            SELECT l.id_variant
+            FROM "stock_take_lines" l
+            JOIN "product_variants" v ON v.id_variant = l.id_variant
+            WHERE l.id_stock_take = $1 AND ($2 = 0 OR l.id_variant = $2) AND ($3 = '' OR v.sku = $3 OR v.barcode = $3)
+        `, stockTakeID, count.VariantID, count.SKU)
+		if err != nil {
+			return nil, msg.InternalServerError(err.Error())
+		}
+
+		field := fmt.Sprintf("counts[%d].sku", i)
+		switch {
+		case count.VariantID == 0 && count.SKU == "":
+			details = append(details, msg.NewFieldError(field, "required", msg.ValSKUOrVariant, field))
+		case len(variantIDs) == 0:
+			details = append(details, msg.NewFieldError(field, "stock_take", msg.ValNotInStockTake, field))
+		case len(variantIDs) > 1:
+			details = append(details, msg.NewFieldError(field, "ambiguous", msg.ValAmbiguousSKU, field))
+		default:
+			lineCounts = append(lineCounts, lineCount{variantID: variantIDs[0], count: count})
+		}
+	}
+	if len(details) > 0 {
+		return nil, msg.Validation(details...)
+	}
+
+	// a stable order keeps concurrent batches from deadlocking
+	sort.SliceStable(lineCounts, func(i, j int) bool { return lineCounts[i].variantID < lineCounts[j].variantID })
+
+	variantIDs := make([]int, 0, len(lineCounts))
+	for _, lineCount := range lineCounts {
+		_, err = tx.ExecContext(ctx, `
+            UPDATE "stock_take_lines"
+            SET counted = CASE WHEN $4 THEN $3 ELSE COALESCE(counted, 0) + $3 END,
+                counted_by = $5, counted_at = CURRENT_TIMESTAMP
+            WHERE id_stock_take = $1 AND id_variant = $2
+        `, stockTakeID, lineCount.variantID, lineCount.count.Quantity, lineCount.count.Replace, entity.StaffID(userID))
+		if err != nil {
+			return nil, msg.InternalServerError(err.Error())
+		}
+		variantIDs = append(variantIDs, lineCount.variantID)
+	}
+
+	lines, err := stockTakeLines(ctx, tx, stockTakeID, variantIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+
+	return lines, nil
+}
+
+// ApproveStockTake posts the variance of every line as a recount
+// adjustment on the stock take's location, in one transaction. The
+// variance is applied to the current stock, so sales made while counting
+// are kept; a shortage larger than the current stock takes it to zero.
+func (r StockTakeRepo) ApproveStockTake(ctx context.Context, stockTakeID int, param entity.ApproveParam, userID uint32) (entity.VarianceReport, error) {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return entity.VarianceReport{}, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	stockTake, err := getStockTake(ctx, tx, stockTakeID, "FOR UPDATE OF t")
+	if err != nil {
+		return entity.VarianceReport{}, err
+	}
+
+	lines, err := stockTakeLines(ctx, tx, stockTakeID, nil)
+	if err != nil {
+		return entity.VarianceReport{}, err
+	}
+	report := entity.Variance(stockTake, lines, param.ZeroUncounted, true)
+
+	productIDs := make([]int, 0, len(report.Lines))
+	for _, line := range report.Lines {
+		productIDs = append(productIDs, line.ProductID)
+	}
+	if err := lockProducts(ctx, tx, productIDs); err != nil {
+		return entity.VarianceReport{}, err
+	}
+
+	adjusted := make([]int, 0, len(report.Lines))
+	for _, line := range report.Lines {
+		if line.Variance == 0 {
+			continue
+		}
+
+		current, err := locationStock(ctx, tx, line.VariantID, stockTake.LocationID)
+		if err != nil {
+			return entity.VarianceReport{}, err
+		}
+		delta := line.Variance
+		if current+delta < 0 {
+			delta = -current
+		}
+		if delta == 0 {
+			continue
+		}
+
+		_, err = RecordMovement(ctx, tx, entity.StockMovement{
+			ProductID:  line.ProductID,
+			VariantID:  line.VariantID,
+			LocationID: stockTake.LocationID,
+			Type:       entity.MovementAdjustment,
+			Quantity:   delta,
+			Reason:     entity.ReasonRecount,
+			Reference:  fmt.Sprintf("stocktake:%d", stockTakeID),
+			UserID:     entity.StaffID(userID),
+		})
+		if err != nil {
+			return entity.VarianceReport{}, err
+		}
+		adjusted = append(adjusted, line.ProductID)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE "products"
+        SET version = version + 1
+        WHERE id_product = ANY($1)
+    `, pq.Array(adjusted))
+	if err != nil {
+		return entity.VarianceReport{}, msg.InternalServerError(err.Error())
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE "stock_takes"
+        SET status = 'approved', approved_by = $2, approved_at = CURRENT_TIMESTAMP
+        WHERE id_stock_take = $1
+    `, stockTakeID, entity.StaffID(userID))
+	if err != nil {
+		return entity.VarianceReport{}, msg.InternalServerError(err.Error())
+	}
+
+	if report.StockTake, err = getStockTake(ctx, tx, stockTakeID, ""); err != nil {
+		return entity.VarianceReport{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.VarianceReport{}, msg.InternalServerError(err.Error())
+	}
+
+	return report, nil
+}
+
+// CancelStockTake ends a stock take without touching stock.
+func (r StockTakeRepo) CancelStockTake(ctx context.Context, stockTakeID int, userID uint32) (entity.StockTake, error) {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return entity.StockTake{}, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	if _, err := getStockTake(ctx, tx, stockTakeID, "FOR UPDATE OF t"); err != nil {
+		return entity.StockTake{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE "stock_takes"
+        SET status = 'cancelled', cancelled_by = $2, cancelled_at = CURRENT_TIMESTAMP
+        WHERE id_stock_take = $1
+    `, stockTakeID, entity.StaffID(userID))
+	if err != nil {
+		return entity.StockTake{}, msg.InternalServerError(err.Error())
+	}
+
+	stockTake, err := getStockTake(ctx, tx, stockTakeID, "")
+	if err != nil {
+		return entity.StockTake{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.StockTake{}, msg.InternalServerError(err.Error())
+	}
+
+	return stockTake, nil
+}
+
+// getStockTake reads a stock take. With a locking clause it also requires
+// the stock take to be counting.
+func getStockTake(ctx context.Context, db sqlx.QueryerContext, stockTakeID int, lock string) (entity.StockTake, error) {
+	var stockTake entity.StockTake
+	err := sqlx.GetContext(ctx, db, &stockTake, `
+        SELECT `+stockTakeColumns+`
+        FROM "stock_takes" t
+        WHERE t.id_stock_take = $1
+        `+lock, stockTakeID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return entity.StockTake{}, msg.NotFound(msg.ErrStockTakeNotFound)
+		}
+		return entity.StockTake{}, msg.InternalServerError(err.Error())
+	}
+	if lock != "" && stockTake.Status != entity.StockTakeCounting {
+		return entity.StockTake{}, msg.Conflict(msg.ErrStockTakeClosed)
+	}
+	return stockTake, nil
+}
+
+// stockTakeLines reads the lines of a stock take, only those of
+// variantIDs unless it is nil.
+func stockTakeLines(ctx context.Context, db sqlx.QueryerContext, stockTakeID int, variantIDs []int) ([]entity.StockTakeLine, error) {
+	var filter interface{}
+	if variantIDs != nil {
+		filter = pq.Array(variantIDs)
+	}
+
+	lines := []entity.StockTakeLine{}
+	err := sqlx.SelectContext(ctx, db, &lines, `
+        SELECT l.id_product, l.id_variant, p.name, v.sku, v.barcode, COALESCE(v.price, p.price) AS price,
+            l.expected, l.counted, l.counted_by, l.counted_at
+        FROM "stock_take_lines" l
+        JOIN "product_variants" v ON v.id_variant = l.id_variant
+        JOIN "products" p ON p.id_product = l.id_product
+        WHERE l.id_stock_take = $1 AND ($2::integer[] IS NULL OR l.id_variant = ANY($2))
+        ORDER BY p.name, l.id_product, v.position, l.id_variant
+    `, stockTakeID, filter)
+	if err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+	return lines, nil
+}
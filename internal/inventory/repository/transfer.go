@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"projectsphere/eniqlo-store/internal/inventory/entity"
+	"projectsphere/eniqlo-store/pkg/database"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type TransferRepo struct {
+	dbConnector database.PostgresConnector
+}
+
+func NewTransferRepo(dbConnector database.PostgresConnector) TransferRepo {
+	return TransferRepo{
+		dbConnector: dbConnector,
+	}
+}
+
+const transferColumns = `id_transfer, from_location, to_location, status, note, created_by, received_by, received_at,
+            cancelled_by, cancelled_at, created_at`
+
+// CreateTransfer books the items of param out of the source location and
+// leaves them in transit. The destination has to be active.
+func (r TransferRepo) CreateTransfer(ctx context.Context, param entity.TransferParam, userID uint32) (entity.Transfer, error) {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return entity.Transfer{}, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	if _, err := ResolveLocation(ctx, tx, param.FromLocationID); err != nil {
+		return entity.Transfer{}, err
+	}
+	var active bool
+	err = tx.GetContext(ctx, &active, `SELECT is_active FROM "locations" WHERE id_location = $1`, param.ToLocationID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return entity.Transfer{}, msg.NotFound(msg.ErrLocationNotFound)
+		}
+		return entity.Transfer{}, msg.InternalServerError(err.Error())
+	}
+	if !active {
+		return entity.Transfer{}, msg.Conflict(msg.ErrLocationInactive)
+	}
+
+	productIDs := make([]int, 0, len(param.Items))
+	for _, item := range param.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	if err := lockProducts(ctx, tx, productIDs); err != nil {
+		return entity.Transfer{}, err
+	}
+
+	transfer := entity.Transfer{Items: make([]entity.TransferItem, 0, len(param.Items))}
+	err = tx.GetContext(ctx, &transfer, `
+        INSERT INTO "stock_transfers" (from_location, to_location, note, created_by)
+        VALUES ($1, $2, $3, $4)
+        RETURNING `+transferColumns,
+		param.FromLocationID, param.ToLocationID, param.Note, entity.StaffID(userID))
+	if err != nil {
+		return entity.Transfer{}, msg.InternalServerError(err.Error())
+	}
+
+	for _, itemParam := range param.Items {
+		level, err := LockVariant(ctx, tx, itemParam.ProductID, itemParam.VariantID)
+		if err != nil {
+			return entity.Transfer{}, err
+		}
+
+		item := entity.TransferItem{ProductID: level.ProductID, VariantID: level.VariantID, Quantity: itemParam.Quantity}
+		err = tx.QueryRowContext(ctx, `
+            INSERT INTO "stock_transfer_items" (id_transfer, id_product, id_variant, quantity)
+            VALUES ($1, $2, $3, $4)
+            RETURNING id_item, (SELECT sku FROM "product_variants" WHERE id_variant = $3)
+        `, transfer.ID, item.ProductID, item.VariantID, item.Quantity).Scan(&item.ID, &item.SKU)
+		if err != nil {
+			return entity.Transfer{}, msg.InternalServerError(err.Error())
+		}
+		transfer.Items = append(transfer.Items, item)
+	}
+
+	if err := r.book(ctx, tx, transfer, transfer.FromLocationID, -1, userID); err != nil {
+		return entity.Transfer{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.Transfer{}, msg.InternalServerError(err.Error())
+	}
+
+	return transfer, nil
+}
+
+// ReceiveTransfer books the items of a transfer in transit into its
+// destination.
+func (r TransferRepo) ReceiveTransfer(ctx context.Context, transferID int, userID uint32) (entity.Transfer, error) {
+	return r.finish(ctx, transferID, userID, entity.TransferReceived)
+}
+
+// CancelTransfer books the items of a transfer in transit back into its
+// source.
+func (r TransferRepo) CancelTransfer(ctx context.Context, transferID int, userID uint32) (entity.Transfer, error) {
+	return r.finish(ctx, transferID, userID, entity.TransferCancelled)
+}
+
+func (r TransferRepo) finish(ctx context.Context, transferID int, userID uint32, status string) (entity.Transfer, error) {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return entity.Transfer{}, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	var transfer entity.Transfer
+	err = tx.GetContext(ctx, &transfer, `SELECT `+transferColumns+` FROM "stock_transfers" WHERE id_transfer = $1 FOR UPDATE`, transferID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return entity.Transfer{}, msg.NotFound(msg.ErrTransferNotFound)
+		}
+		return entity.Transfer{}, msg.InternalServerError(err.Error())
+	}
+	if transfer.Status != entity.TransferInTransit {
+		return entity.Transfer{}, msg.Conflict(msg.ErrTransferNotInTransit)
+	}
+
+	if transfer.Items, err = transferItems(ctx, tx, transferID); err != nil {
+		return entity.Transfer{}, err
+	}
+
+	productIDs := make([]int, 0, len(transfer.Items))
+	for _, item := range transfer.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	if err := lockProducts(ctx, tx, productIDs); err != nil {
+		return entity.Transfer{}, err
+	}
+
+	query := `
+        UPDATE "stock_transfers"
+        SET status = 'received', received_by = $2, received_at = CURRENT_TIMESTAMP
+        WHERE id_transfer = $1
+        RETURNING ` + transferColumns
+	locationID := transfer.ToLocationID
+	if status == entity.TransferCancelled {
+		query = `
+            UPDATE "stock_transfers"
+            SET status = 'cancelled', cancelled_by = $2, cancelled_at = CURRENT_TIMESTAMP
+            WHERE id_transfer = $1
+            RETURNING ` + transferColumns
+		locationID = transfer.FromLocationID
+	}
+
+	if err := r.book(ctx, tx, transfer, locationID, 1, userID); err != nil {
+		return entity.Transfer{}, err
+	}
+
+	items := transfer.Items
+	err = tx.GetContext(ctx, &transfer, query, transferID, entity.StaffID(userID))
+	if err != nil {
+		return entity.Transfer{}, msg.InternalServerError(err.Error())
+	}
+	transfer.Items = items
+
+	if err := tx.Commit(); err != nil {
+		return entity.Transfer{}, msg.InternalServerError(err.Error())
+	}
+
+	return transfer, nil
+}
+
+// book records a transfer movement per item at locationID, sign giving
+// the direction, and bumps the versions of the products involved.
+func (r TransferRepo) book(ctx context.Context, tx *sqlx.Tx, transfer entity.Transfer, locationID, sign int, userID uint32) error {
+	productIDs := make([]int, 0, len(transfer.Items))
+	for _, item := range transfer.Items {
+		_, err := RecordMovement(ctx, tx, entity.StockMovement{
+			ProductID:  item.ProductID,
+			VariantID:  item.VariantID,
+			LocationID: locationID,
+			Type:       entity.MovementTransfer,
+			Quantity:   sign * item.Quantity,
+			Reference:  fmt.Sprintf("transfer:%d", transfer.ID),
+			UserID:     entity.StaffID(userID),
+		})
+		if err != nil {
+			return err
+		}
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	_, err := tx.ExecContext(ctx, `
+        UPDATE "products"
+        SET version = version + 1
+        WHERE id_product = ANY($1)
+    `, pq.Array(productIDs))
+	if err != nil {
+		return msg.InternalServerError(err.Error())
+	}
+	return nil
+}
+
+func (r TransferRepo) GetTransfer(ctx context.Context, transferID int) (entity.Transfer, error) {
+	var transfer entity.Transfer
+	err := r.dbConnector.DB.GetContext(ctx, &transfer, `SELECT `+transferColumns+` FROM "stock_transfers" WHERE id_transfer = $1`, transferID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return entity.Transfer{}, msg.NotFound(msg.ErrTransferNotFound)
+		}
+		return entity.Transfer{}, msg.InternalServerError(err.Error())
+	}
+
+	if transfer.Items, err = transferItems(ctx, r.dbConnector.DB, transferID); err != nil {
+		return entity.Transfer{}, err
+	}
+	return transfer, nil
+}
+
+// ListTransfers returns a page of transfers, newest first, without their
+// items.
+func (r TransferRepo) ListTransfers(ctx context.Context, filter entity.TransferFilter) ([]entity.Transfer, error) {
+	conditions := "TRUE"
+	var args []interface{}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if filter.LocationID != 0 {
+		args = append(args, filter.LocationID)
+		conditions += fmt.Sprintf(" AND (from_location = $%[1]d OR to_location = $%[1]d)", len(args))
+	}
+
+	transfers := []entity.Transfer{}
+	err := r.dbConnector.DB.SelectContext(ctx, &transfers, fmt.Sprintf(`
+        SELECT `+transferColumns+`
+        FROM "stock_transfers"
+        WHERE %s
+        ORDER BY id_transfer DESC
+        LIMIT %d OFFSET %d
+    `, conditions, filter.Limit, filter.Offset), args...)
+	if err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+	return transfers, nil
+}
+
+func transferItems(ctx context.Context, db sqlx.QueryerContext, transferID int) ([]entity.TransferItem, error) {
+	items := []entity.TransferItem{}
+	err := sqlx.SelectContext(ctx, db, &items, `
+        SELECT i.id_item, i.id_product, i.id_variant, COALESCE(v.sku, '') AS sku, i.quantity
+        FROM "stock_transfer_items" i
+        LEFT JOIN "product_variants" v ON v.id_variant = i.id_variant
+        WHERE i.id_transfer = $1
+        ORDER BY i.id_item
+    `, transferID)
+	if err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+	return items, nil
+}
+
+// lockProducts locks products in id order, the order every stock writer
+// uses, so concurrent writers cannot deadlock.
+func lockProducts(ctx context.Context, tx *sqlx.Tx, productIDs []int) error {
+	_, err := tx.ExecContext(ctx, `SELECT id_product FROM "products" WHERE id_product = ANY($1) ORDER BY id_product FOR UPDATE`, pq.Array(productIDs))
+	if err != nil {
+		return msg.InternalServerError(err.Error())
+	}
+	return nil
+}
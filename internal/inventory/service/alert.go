@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"projectsphere/eniqlo-store/internal/inventory/entity"
+	"projectsphere/eniqlo-store/internal/inventory/repository"
+	"projectsphere/eniqlo-store/pkg/middleware/logger"
+)
+
+type AlertService struct {
+	alertRepo repository.AlertRepo
+}
+
+func NewAlertService(alertRepo repository.AlertRepo) AlertService {
+	return AlertService{
+		alertRepo: alertRepo,
+	}
+}
+
+func (s AlertService) List(ctx context.Context, filter entity.StockAlertFilter) ([]entity.StockAlert, error) {
+	return s.alertRepo.ListAlerts(ctx, filter)
+}
+
+func (s AlertService) Acknowledge(ctx context.Context, alertID int, userID uint32) (entity.StockAlert, error) {
+	alert, err := s.alertRepo.AcknowledgeAlert(ctx, alertID, userID)
+	if err != nil {
+		return entity.StockAlert{}, err
+	}
+
+	logger.FromContext(ctx).Info().Int("alertId", alertID).Int("productId", alert.ProductID).Msg("stock alert acknowledged")
+	return alert, nil
+}
+
+func (s AlertService) Resolve(ctx context.Context, alertID int, userID uint32) (entity.StockAlert, error) {
+	alert, err := s.alertRepo.ResolveAlert(ctx, alertID, userID)
+	if err != nil {
+		return entity.StockAlert{}, err
+	}
+
+	logger.FromContext(ctx).Info().Int("alertId", alertID).Int("productId", alert.ProductID).Msg("stock alert resolved")
+	return alert, nil
+}
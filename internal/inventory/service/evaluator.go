@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"projectsphere/eniqlo-store/internal/inventory/entity"
+	"projectsphere/eniqlo-store/internal/inventory/repository"
+	"projectsphere/eniqlo-store/pkg/notify"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// EventLowStock is the notify.Message event of a new stock alert.
+const EventLowStock = "stock.low"
+
+type EvaluatorConfig struct {
+	// Interval between evaluations.
+	Interval time.Duration
+	// BatchSize bounds the notifications sent per evaluation.
+	BatchSize int
+	// NotifyTimeout bounds the delivery of one notification.
+	NotifyTimeout time.Duration
+}
+
+// AlertEvaluator periodically looks at the products changed since its last
+// run, which covers checkouts and adjustments, opens stock alerts for
+// products that fell to their reorder point and resolves those restocked
+// above it. New alerts are delivered through the notifier; failed
+// deliveries are retried on the next run.
+type AlertEvaluator struct {
+	alertRepo repository.AlertRepo
+	notifier  notify.Notifier
+	config    EvaluatorConfig
+
+	// since is the database time of the previous evaluation; the zero
+	// time evaluates every product on the first run
+	since time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+func NewAlertEvaluator(alertRepo repository.AlertRepo, notifier notify.Notifier, config EvaluatorConfig) *AlertEvaluator {
+	return &AlertEvaluator{
+		alertRepo: alertRepo,
+		notifier:  notifier,
+		config:    config,
+		done:      make(chan struct{}),
+	}
+}
+
+// Start runs the evaluator in the background until Stop or until ctx is
+// cancelled.
+func (e *AlertEvaluator) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+	go e.run(ctx)
+	return nil
+}
+
+// Stop cancels the running evaluation and waits for it until ctx expires.
+func (e *AlertEvaluator) Stop(ctx context.Context) error {
+	if e.cancel == nil {
+		return nil
+	}
+	e.once.Do(e.cancel)
+
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *AlertEvaluator) run(ctx context.Context) {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		e.evaluate(ctx)
+		e.notify(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *AlertEvaluator) evaluate(ctx context.Context) {
+	opened, resolved, now, err := e.alertRepo.EvaluateAlerts(ctx, e.since)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error().Err(err).Msg("cannot evaluate stock alerts")
+		}
+		return
+	}
+	if opened > 0 || resolved > 0 {
+		log.Info().Int64("opened", opened).Int64("resolved", resolved).Msg("stock alerts evaluated")
+	}
+
+	// look back one interval so changes committed by transactions that
+	// started before now are not missed; evaluating twice is harmless
+	e.since = now.Add(-e.config.Interval)
+}
+
+func (e *AlertEvaluator) notify(ctx context.Context) {
+	alerts, err := e.alertRepo.ClaimUnnotified(ctx, e.config.BatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error().Err(err).Msg("cannot list stock alerts to notify")
+		}
+		return
+	}
+
+	for _, alert := range alerts {
+		if err := e.send(ctx, alert); err != nil {
+			log.Error().Err(err).Int("alertId", alert.ID).Msg("cannot deliver stock alert")
+			// ctx may be cancelled already, the release must still happen
+			if err := e.alertRepo.ReleaseNotification(context.Background(), alert.ID); err != nil {
+				log.Error().Err(err).Int("alertId", alert.ID).Msg("cannot release stock alert notification")
+			}
+		}
+	}
+}
+
+func (e *AlertEvaluator) send(ctx context.Context, alert entity.StockAlert) error {
+	ctx, cancel := context.WithTimeout(ctx, e.config.NotifyTimeout)
+	defer cancel()
+
+	return e.notifier.Notify(ctx, notify.Message{
+		Event:   EventLowStock,
+		Subject: fmt.Sprintf("Low stock: %s (%s)", alert.Name, alert.SKU),
+		Body: fmt.Sprintf("%s (SKU %s) is down to %d in stock, at or below its reorder point of %d.\nSuggested reorder quantity: %d.\nAlert #%d",
+			alert.Name, alert.SKU, alert.Stock, alert.ReorderPoint, alert.ReorderQuantity, alert.ID),
+		Data: alert,
+	})
+}
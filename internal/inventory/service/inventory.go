@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"projectsphere/eniqlo-store/internal/inventory/entity"
+	"projectsphere/eniqlo-store/internal/inventory/repository"
+	"projectsphere/eniqlo-store/pkg/middleware/logger"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+)
+
+type InventoryService struct {
+	movementRepo repository.MovementRepo
+}
+
+func NewInventoryService(movementRepo repository.MovementRepo) InventoryService {
+	return InventoryService{
+		movementRepo: movementRepo,
+	}
+}
+
+func (s InventoryService) StockHistory(ctx context.Context, filter entity.StockHistoryFilter) (entity.StockHistory, error) {
+	return s.movementRepo.StockHistory(ctx, filter)
+}
+
+// Adjust changes a variant's stock by param.Delta or to param.Count,
+// exactly one of which must be given.
+func (s InventoryService) Adjust(ctx context.Context, productID int, param entity.AdjustmentParam, userID uint32) (entity.AdjustmentResult, error) {
+	if (param.Delta == nil) == (param.Count == nil) {
+		return entity.AdjustmentResult{}, msg.Validation(msg.NewFieldError("delta", "delta_or_count", msg.ValDeltaOrCount, "delta"))
+	}
+
+	result, err := s.movementRepo.Adjust(ctx, productID, param, userID)
+	if err != nil {
+		return entity.AdjustmentResult{}, err
+	}
+
+	if result.Movement != nil {
+		logger.FromContext(ctx).Info().
+			Int("productId", productID).
+			Int("variantId", result.Movement.VariantID).
+			Int("quantity", result.Movement.Quantity).
+			Str("reason", param.Reason).
+			Msg("stock adjusted")
+	}
+
+	return result, nil
+}
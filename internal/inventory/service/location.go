@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"projectsphere/eniqlo-store/internal/inventory/entity"
+	"projectsphere/eniqlo-store/internal/inventory/repository"
+)
+
+type LocationService struct {
+	locationRepo repository.LocationRepo
+}
+
+func NewLocationService(locationRepo repository.LocationRepo) LocationService {
+	return LocationService{
+		locationRepo: locationRepo,
+	}
+}
+
+func (s LocationService) List(ctx context.Context) ([]entity.Location, error) {
+	return s.locationRepo.ListLocations(ctx)
+}
+
+func (s LocationService) Get(ctx context.Context, locationID int) (entity.Location, error) {
+	return s.locationRepo.GetLocation(ctx, locationID)
+}
+
+func (s LocationService) Create(ctx context.Context, param entity.LocationParam) (entity.Location, error) {
+	return s.locationRepo.CreateLocation(ctx, toLocation(param))
+}
+
+func (s LocationService) Update(ctx context.Context, locationID int, param entity.LocationParam) (entity.Location, error) {
+	location := toLocation(param)
+	location.ID = locationID
+	return s.locationRepo.UpdateLocation(ctx, location)
+}
+
+func (s LocationService) ProductStock(ctx context.Context, productID int) (entity.ProductStock, error) {
+	return s.locationRepo.ProductStock(ctx, productID)
+}
+
+func (s LocationService) LocationStock(ctx context.Context, filter entity.LocationStockFilter) ([]entity.LocationStock, error) {
+	return s.locationRepo.LocationStock(ctx, filter)
+}
+
+func toLocation(param entity.LocationParam) entity.Location {
+	location := entity.Location{
+		Code:     param.Code,
+		Name:     param.Name,
+		Type:     param.Type,
+		IsActive: true,
+	}
+	if param.IsActive != nil {
+		location.IsActive = *param.IsActive
+	}
+	return location
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	categoryService "projectsphere/eniqlo-store/internal/category/service"
+	"projectsphere/eniqlo-store/internal/inventory/entity"
+	"projectsphere/eniqlo-store/internal/inventory/repository"
+	"projectsphere/eniqlo-store/pkg/middleware/logger"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+)
+
+type StockTakeService struct {
+	stockTakeRepo repository.StockTakeRepo
+	categorySvc   categoryService.CategoryService
+}
+
+func NewStockTakeService(stockTakeRepo repository.StockTakeRepo, categorySvc categoryService.CategoryService) StockTakeService {
+	return StockTakeService{
+		stockTakeRepo: stockTakeRepo,
+		categorySvc:   categorySvc,
+	}
+}
+
+func (s StockTakeService) List(ctx context.Context, filter entity.StockTakeFilter) ([]entity.StockTake, error) {
+	return s.stockTakeRepo.ListStockTakes(ctx, filter)
+}
+
+func (s StockTakeService) Get(ctx context.Context, stockTakeID int) (entity.StockTake, error) {
+	return s.stockTakeRepo.GetStockTake(ctx, stockTakeID)
+}
+
+// Create starts a stock take, covering the subcategories of the category
+// too.
+func (s StockTakeService) Create(ctx context.Context, param entity.StockTakeParam, userID uint32) (entity.StockTake, error) {
+	var categories []string
+	if param.Category != "" {
+		tree, err := s.categorySvc.Tree(ctx)
+		if err != nil {
+			return entity.StockTake{}, err
+		}
+		categories = tree.Keys(param.Category)
+		if len(categories) == 0 {
+			return entity.StockTake{}, msg.NotFound(msg.ErrCategoryNotFound)
+		}
+	}
+
+	stockTake, err := s.stockTakeRepo.CreateStockTake(ctx, param, categories, userID)
+	if err != nil {
+		return entity.StockTake{}, err
+	}
+
+	logger.FromContext(ctx).Info().
+		Int("stockTakeId", stockTake.ID).
+		Int("locationId", stockTake.LocationID).
+		Int("lines", stockTake.Lines).
+		Msg("stock take started")
+	return stockTake, nil
+}
+
+func (s StockTakeService) RecordCounts(ctx context.Context, stockTakeID int, counts []entity.CountParam, userID uint32) ([]entity.StockTakeLine, error) {
+	return s.stockTakeRepo.RecordCounts(ctx, stockTakeID, counts, userID)
+}
+
+// Variance reports the difference between the counts and the stock frozen
+// when the stock take started.
+func (s StockTakeService) Variance(ctx context.Context, stockTakeID int, onlyVariances bool) (entity.VarianceReport, error) {
+	stockTake, err := s.stockTakeRepo.GetStockTake(ctx, stockTakeID)
+	if err != nil {
+		return entity.VarianceReport{}, err
+	}
+
+	lines, err := s.stockTakeRepo.StockTakeLines(ctx, stockTakeID)
+	if err != nil {
+		return entity.VarianceReport{}, err
+	}
+
+	return entity.Variance(stockTake, lines, false, onlyVariances), nil
+}
+
+func (s StockTakeService) Approve(ctx context.Context, stockTakeID int, param entity.ApproveParam, userID uint32) (entity.VarianceReport, error) {
+	report, err := s.stockTakeRepo.ApproveStockTake(ctx, stockTakeID, param, userID)
+	if err != nil {
+		return entity.VarianceReport{}, err
+	}
+
+	logger.FromContext(ctx).Info().
+		Int("stockTakeId", stockTakeID).
+		Int("shortage", report.Shortage).
+		Int("surplus", report.Surplus).
+		Int("uncounted", report.Uncounted).
+		Msg("stock take approved")
+	return report, nil
+}
+
+func (s StockTakeService) Cancel(ctx context.Context, stockTakeID int, userID uint32) (entity.StockTake, error) {
+	stockTake, err := s.stockTakeRepo.CancelStockTake(ctx, stockTakeID, userID)
+	if err != nil {
+		return entity.StockTake{}, err
+	}
+
+	logger.FromContext(ctx).Info().Int("stockTakeId", stockTakeID).Msg("stock take cancelled")
+	return stockTake, nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"projectsphere/eniqlo-store/internal/inventory/entity"
+	"projectsphere/eniqlo-store/internal/inventory/repository"
+	"projectsphere/eniqlo-store/pkg/middleware/logger"
+)
+
+type TransferService struct {
+	transferRepo repository.TransferRepo
+}
+
+func NewTransferService(transferRepo repository.TransferRepo) TransferService {
+	return TransferService{
+		transferRepo: transferRepo,
+	}
+}
+
+func (s TransferService) List(ctx context.Context, filter entity.TransferFilter) ([]entity.Transfer, error) {
+	return s.transferRepo.ListTransfers(ctx, filter)
+}
+
+func (s TransferService) Get(ctx context.Context, transferID int) (entity.Transfer, error) {
+	return s.transferRepo.GetTransfer(ctx, transferID)
+}
+
+func (s TransferService) Create(ctx context.Context, param entity.TransferParam, userID uint32) (entity.Transfer, error) {
+	transfer, err := s.transferRepo.CreateTransfer(ctx, param, userID)
+	if err != nil {
+		return entity.Transfer{}, err
+	}
+
+	logger.FromContext(ctx).Info().
+		Int("transferId", transfer.ID).
+		Int("from", transfer.FromLocationID).
+		Int("to", transfer.ToLocationID).
+		Int("items", len(transfer.Items)).
+		Msg("stock transfer created")
+	return transfer, nil
+}
+
+func (s TransferService) Receive(ctx context.Context, transferID int, userID uint32) (entity.Transfer, error) {
+	transfer, err := s.transferRepo.ReceiveTransfer(ctx, transferID, userID)
+	if err != nil {
+		return entity.Transfer{}, err
+	}
+
+	logger.FromContext(ctx).Info().Int("transferId", transferID).Msg("stock transfer received")
+	return transfer, nil
+}
+
+func (s TransferService) Cancel(ctx context.Context, transferID int, userID uint32) (entity.Transfer, error) {
+	transfer, err := s.transferRepo.CancelTransfer(ctx, transferID, userID)
+	if err != nil {
+		return entity.Transfer{}, err
+	}
+
+	logger.FromContext(ctx).Info().Int("transferId", transferID).Msg("stock transfer cancelled")
+	return transfer, nil
+}
@@ -0,0 +1,35 @@
+package entity
+
+// ProductFilter narrows down product searches. Category matches the
+// category and all of its subcategories, Options the values of a variant.
+// With LocationID, InStock looks at the stock held at that location.
+type ProductFilter struct {
+	Name        string
+	SKU         string
+	Barcode     string
+	Options     VariantOptions
+	Category    string
+	Categories  []string
+	ImageStatus string
+	IsAvailable *bool
+	InStock     *bool
+	LocationID  int
+	Limit       int
+	Offset      int
+	SortBy      string
+	OrderBy     string
+}
+
+// sortColumns maps the sortBy values accepted by the search endpoint to
+// columns.
+var sortColumns = map[string]string{
+	"createdAt": "created_at",
+	"name":      "name",
+	"price":     "price",
+	"stock":     "stock",
+}
+
+func SortColumn(sortBy string) (string, bool) {
+	column, ok := sortColumns[sortBy]
+	return column, ok
+}
@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"io"
+	"time"
+)
+
+type ProductImage struct {
+	ID           int       `db:"id_image" json:"imageId"`
+	ProductID    int       `db:"id_product" json:"productId"`
+	ImageURL     string    `db:"image_url" json:"imageUrl"`
+	ThumbnailURL string    `db:"thumbnail_url" json:"thumbnailUrl"`
+	StorageKey   string    `db:"storage_key" json:"-"`
+	ThumbnailKey string    `db:"thumbnail_key" json:"-"`
+	ContentType  string    `db:"content_type" json:"contentType"`
+	Size         int64     `db:"size_bytes" json:"size"`
+	Width        int       `db:"width" json:"width"`
+	Height       int       `db:"height" json:"height"`
+	Position     int       `db:"position" json:"position"`
+	IsPrimary    bool      `db:"is_primary" json:"isPrimary"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+}
+
+// ImageUpload is one uploaded file. It is opened when it is processed, so
+// only one file at a time is held in memory.
+type ImageUpload struct {
+	Filename string
+	Size     int64
+	Open     func() (io.ReadCloser, error)
+}
+
+type ImageOrderParam struct {
+	ImageIDs []int `json:"imageIds" validate:"required,min=1,dive,min=1"`
+}
+
+// Image verification states of products.image_status.
+const (
+	ImageStatusPending = "pending"
+	ImageStatusOK      = "ok"
+	ImageStatusBroken  = "broken"
+)
+
+// ImageCheck is the outcome of verifying a product's imageUrl.
+type ImageCheck struct {
+	ProductID   string `db:"id"`
+	ImageURL    string `db:"image_url"`
+	Status      string `db:"image_status"`
+	Error       string `db:"image_error"`
+	CachedImage string `db:"cached_image_url"`
+	// ETag of the response the cached copy was made from.
+	ETag string `db:"image_etag"`
+	// StaleCachedImage is the cached copy of a replaced image URL, deleted
+	// once the check is saved.
+	StaleCachedImage string `db:"stale_cached_image_url"`
+}
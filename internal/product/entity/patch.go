@@ -0,0 +1,58 @@
+package entity
+
+import "reflect"
+
+// Patch media types accepted by PATCH /v1/product/:id.
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+// ReadOnlyFields are maintained by the server and cannot be patched.
+// Options and variants have their own endpoints; cost follows receiving.
+var ReadOnlyFields = []string{"productId", "imageStatus", "cachedImageUrl", "cost", "options", "variants", "version", "created_at", "updated_at", "deleted_at"}
+
+// FieldChange is one column changed by a patch.
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Column string      `json:"-"`
+	Old    interface{} `json:"old"`
+	New    interface{} `json:"new"`
+}
+
+type ProductPatchResult struct {
+	ID            string   `json:"productId"`
+	Version       int      `json:"version"`
+	ChangedFields []string `json:"changedFields"`
+}
+
+// Changes lists the editable fields that differ between p and patched. The
+// stock of a product with options is the sum of its variants and is not
+// editable.
+func (p Product) Changes(patched Product) []FieldChange {
+	var changes []FieldChange
+	add := func(field, column string, old, new interface{}) {
+		if !reflect.DeepEqual(old, new) {
+			changes = append(changes, FieldChange{Field: field, Column: column, Old: old, New: new})
+		}
+	}
+
+	add("name", "name", p.Name, patched.Name)
+	add("sku", "sku", p.SKU, patched.SKU)
+	add("category", "category", p.Category, patched.Category)
+	add("imageUrl", "image_url", p.ImageURL, patched.ImageURL)
+	add("notes", "notes", p.Notes, patched.Notes)
+	add("price", "price", p.Price, patched.Price)
+	if len(p.Options) == 0 {
+		add("stock", "stock", p.Stock, patched.Stock)
+	}
+	add("reorderPoint", "reorder_point", p.ReorderPoint, patched.ReorderPoint)
+	add("reorderQuantity", "reorder_quantity", p.ReorderQuantity, patched.ReorderQuantity)
+	add("location", "location", p.Location, patched.Location)
+	add("isAvailable", "is_available", p.IsAvailable, patched.IsAvailable)
+	if len(p.Attributes) > 0 || len(patched.Attributes) > 0 {
+		add("attributes", "attributes", p.Attributes, patched.Attributes)
+	}
+
+	return changes
+}
@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"projectsphere/eniqlo-store/pkg/money"
+	"time"
+)
+
+// Price schedule statuses. A pending schedule is applied by the price
+// scheduler, or by a checkout of the product, once it is due.
+const (
+	SchedulePending   = "pending"
+	ScheduleApplied   = "applied"
+	ScheduleCancelled = "cancelled"
+)
+
+// PriceChange is one entry of the price history of a product, or of one of
+// its variants when VariantID is set. OldPrice is nil for the first price
+// and Price is nil when a variant falls back to the product price.
+// ScheduleID is set for changes made by a price schedule.
+type PriceChange struct {
+	ID         int          `db:"id_price_change" json:"priceChangeId"`
+	ProductID  int          `db:"id_product" json:"productId"`
+	VariantID  *int         `db:"id_variant" json:"variantId"`
+	OldPrice   *money.Money `db:"old_price" json:"oldPrice"`
+	Price      *money.Money `db:"price" json:"price"`
+	ScheduleID *int         `db:"id_schedule" json:"scheduleId"`
+	UserID     *int         `db:"user_id" json:"userId"`
+	CreatedAt  time.Time    `db:"created_at" json:"created_at"`
+}
+
+// ScheduledPrice sets the price of a product, or the price override of one
+// of its variants, at EffectiveAt.
+type ScheduledPrice struct {
+	ID          int         `db:"id_schedule" json:"scheduleId"`
+	ProductID   int         `db:"id_product" json:"productId"`
+	VariantID   *int        `db:"id_variant" json:"variantId"`
+	Price       money.Money `db:"price" json:"price"`
+	EffectiveAt time.Time   `db:"effective_at" json:"effectiveAt"`
+	Status      string      `db:"status" json:"status"`
+	Note        string      `db:"note" json:"note"`
+	CreatedBy   *int        `db:"created_by" json:"createdBy"`
+	AppliedAt   *time.Time  `db:"applied_at" json:"appliedAt"`
+	CancelledBy *int        `db:"cancelled_by" json:"cancelledBy"`
+	CancelledAt *time.Time  `db:"cancelled_at" json:"cancelledAt"`
+	CreatedAt   time.Time   `db:"created_at" json:"created_at"`
+}
+
+// ScheduleParam schedules a price change. VariantID is left out to change
+// the product price.
+type ScheduleParam struct {
+	VariantID   int         `json:"variantId" validate:"omitempty,min=1"`
+	Price       money.Money `json:"price" validate:"required,min=1,max=1000000000"`
+	EffectiveAt time.Time   `json:"effectiveAt" validate:"required"`
+	Note        string      `json:"note" validate:"max=200"`
+}
+
+// Prices is the price history of a product, newest first, with the price
+// changes still scheduled in the order they take effect.
+type Prices struct {
+	Price     money.Money      `json:"price"`
+	History   []PriceChange    `json:"history"`
+	Scheduled []ScheduledPrice `json:"scheduled"`
+}
+
+// PriceFilter pages through the price history.
+type PriceFilter struct {
+	Limit  int
+	Offset int
+}
@@ -2,23 +2,35 @@ package entity
 
 import (
 	"database/sql"
+	"projectsphere/eniqlo-store/pkg/money"
 	"time"
 )
 
+// Product is an item for sale. Its price is capped so that a checkout of
+// 100 lines of 100000 units stays within int64 minor units.
 type Product struct {
-	ID          string       `db:"id" json:"productId"`
-	Name        string       `json:"name" validate:"required,min=1,max=30"`
-	SKU         string       `json:"sku" validate:"required,min=1,max=30"`
-	Category    string       `json:"category" validate:"required,oneof=Clothing Accessories Footwear Beverages"`
-	ImageURL    string       `json:"imageUrl" validate:"required,url"`
-	Notes       string       `json:"notes" validate:"required,min=1,max=200"`
-	Price       float64      `json:"price" validate:"required,min=1"`
-	Stock       int          `json:"stock" validate:"required,min=0,max=100000"`
-	Location    string       `json:"location" validate:"required,min=1,max=200"`
-	IsAvailable bool         `json:"isAvailable" validate:"required"`
-	CreatedAt   time.Time    `db:"created_at" json:"created_at"`
-	UpdatedAt   sql.NullTime `db:"updated_at" json:"updated_at"`
-	DeletedAt   sql.NullTime `db:"deleted_at" json:"deleted_at"`
+	ID              string       `db:"id" json:"productId"`
+	Name            string       `db:"name" json:"name" validate:"required,min=1,max=30"`
+	SKU             string       `db:"sku" json:"sku" validate:"required,min=1,max=30"`
+	Category        string       `db:"category" json:"category" validate:"required,category"`
+	ImageURL        string       `db:"image_url" json:"imageUrl" validate:"required,httpurl"`
+	ImageStatus     string       `db:"image_status" json:"imageStatus"`
+	CachedImage     *string      `db:"cached_image_url" json:"cachedImageUrl"`
+	Notes           string       `db:"notes" json:"notes" validate:"required,min=1,max=200"`
+	Price           money.Money  `db:"price" json:"price" validate:"required,min=1,max=1000000000"`
+	Cost            money.Money  `db:"cost" json:"cost"`
+	Stock           int          `db:"stock" json:"stock" validate:"min=0,max=100000"`
+	ReorderPoint    int          `db:"reorder_point" json:"reorderPoint" validate:"min=0,max=100000"`
+	ReorderQuantity int          `db:"reorder_quantity" json:"reorderQuantity" validate:"min=0,max=100000"`
+	Location        string       `db:"location" json:"location" validate:"required,min=1,max=200"`
+	IsAvailable     bool         `db:"is_available" json:"isAvailable"`
+	Attributes      Attributes   `db:"attributes" json:"attributes"`
+	Options         Options      `db:"options" json:"options" validate:"max=3,dive"`
+	Variants        []Variant    `db:"-" json:"variants,omitempty"`
+	Version         int          `db:"version" json:"version"`
+	CreatedAt       time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt       sql.NullTime `db:"updated_at" json:"updated_at"`
+	DeletedAt       sql.NullTime `db:"deleted_at" json:"deleted_at"`
 }
 
 type ProductResponse struct {
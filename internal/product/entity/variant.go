@@ -0,0 +1,188 @@
+package entity
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"projectsphere/eniqlo-store/pkg/money"
+	"strings"
+	"time"
+)
+
+// MaxVariants bounds the combinations a product's options may generate.
+const MaxVariants = 100
+
+// Option is a dimension a product varies in, e.g. size with S, M and L.
+type Option struct {
+	Name   string   `json:"name" validate:"required,min=1,max=30"`
+	Values []string `json:"values" validate:"required,min=1,max=30,dive,required,max=30"`
+}
+
+// Options is stored as a jsonb array. A product without options has a
+// single default variant.
+type Options []Option
+
+func (o Options) Value() (driver.Value, error) {
+	if o == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(o)
+}
+
+func (o *Options) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case nil:
+		*o = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(value, o)
+	case string:
+		return json.Unmarshal([]byte(value), o)
+	default:
+		return errors.New("product options must be jsonb")
+	}
+}
+
+// Combinations returns every combination of option values in definition
+// order, or the single empty combination of the default variant.
+func (o Options) Combinations() []VariantOptions {
+	combinations := []VariantOptions{{}}
+	for _, option := range o {
+		next := make([]VariantOptions, 0, len(combinations)*len(option.Values))
+		for _, combination := range combinations {
+			for _, value := range option.Values {
+				variant := make(VariantOptions, len(combination)+1)
+				for name, chosen := range combination {
+					variant[name] = chosen
+				}
+				variant[option.Name] = value
+				next = append(next, variant)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// VariantOptions holds the value a variant has for each option, stored as a
+// jsonb object.
+type VariantOptions map[string]string
+
+func (v VariantOptions) Value() (driver.Value, error) {
+	if v == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(v)
+}
+
+func (v *VariantOptions) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(value, v)
+	case string:
+		return json.Unmarshal([]byte(value), v)
+	default:
+		return errors.New("variant options must be jsonb")
+	}
+}
+
+// Key identifies a combination independently of map order.
+func (v VariantOptions) Key() string {
+	if len(v) == 0 {
+		return "{}"
+	}
+	key, _ := json.Marshal(map[string]string(v))
+	return string(key)
+}
+
+// Variant is a sellable combination of a product's options. Price
+// overrides the product price when set.
+type Variant struct {
+	ID             int            `db:"id_variant" json:"variantId"`
+	ProductID      int            `db:"id_product" json:"productId"`
+	Options        VariantOptions `db:"options" json:"options"`
+	SKU            string         `db:"sku" json:"sku"`
+	Barcode        *string        `db:"barcode" json:"barcode"`
+	Price          *money.Money   `db:"price" json:"price"`
+	EffectivePrice money.Money    `db:"effective_price" json:"effectivePrice"`
+	Stock          int            `db:"stock" json:"stock"`
+	IsAvailable    bool           `db:"is_available" json:"isAvailable"`
+	Position       int            `db:"position" json:"position"`
+	CreatedAt      time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt      sql.NullTime   `db:"updated_at" json:"updated_at"`
+}
+
+// VariantParam is the body of a variant update. Stock may be sent back as
+// read, it is changed through a stock adjustment.
+type VariantParam struct {
+	SKU         string       `json:"sku" validate:"required,min=1,max=50"`
+	Barcode     *string      `json:"barcode" validate:"omitempty,min=1,max=50"`
+	Price       *money.Money `json:"price" validate:"omitempty,min=1,max=1000000000"`
+	Stock       *int         `json:"stock"`
+	IsAvailable bool         `json:"isAvailable"`
+}
+
+type OptionsParam struct {
+	Options []Option `json:"options" validate:"max=3,dive"`
+}
+
+// VariantSKU derives the SKU of a generated variant from the product SKU,
+// e.g. TSHIRT-M-RED.
+func VariantSKU(productSKU string, options Options, combination VariantOptions) string {
+	parts := []string{productSKU}
+	for _, option := range options {
+		parts = append(parts, strings.ToUpper(strings.Join(strings.Fields(combination[option.Name]), "")))
+	}
+	return strings.Join(parts, "-")
+}
+
+// VariantPlan is how a product's variants change with its options.
+type VariantPlan struct {
+	Keep   []Variant
+	Create []Variant
+	Remove []Variant
+}
+
+// PlanVariants matches existing variants to the combinations of options.
+// Matching variants keep their SKU, price and stock, new combinations get
+// a generated SKU and no stock, and positions follow the combination order.
+func PlanVariants(productSKU string, options Options, existing []Variant) VariantPlan {
+	byKey := make(map[string]Variant, len(existing))
+	for _, variant := range existing {
+		byKey[variant.Options.Key()] = variant
+	}
+
+	var plan VariantPlan
+	for i, combination := range options.Combinations() {
+		key := combination.Key()
+		variant, ok := byKey[key]
+		if ok {
+			delete(byKey, key)
+			variant.Position = i + 1
+			plan.Keep = append(plan.Keep, variant)
+			continue
+		}
+
+		sku := productSKU
+		if len(options) > 0 {
+			sku = VariantSKU(productSKU, options, combination)
+		}
+		plan.Create = append(plan.Create, Variant{
+			Options:     combination,
+			SKU:         sku,
+			IsAvailable: true,
+			Position:    i + 1,
+		})
+	}
+
+	for _, variant := range existing {
+		if _, removed := byKey[variant.Options.Key()]; removed {
+			plan.Remove = append(plan.Remove, variant)
+		}
+	}
+	return plan
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"fmt"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// formatETag renders a product version as a strong entity tag.
+func formatETag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// expectedVersion reads the version a write is conditioned on from
+// If-Match. Zero means unconditional: the header is absent or "*".
+func expectedVersion(c *gin.Context, required bool) (int, error) {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" {
+		if required {
+			return 0, msg.New(msg.CodePreconditionRequired, msg.ErrIfMatchRequired)
+		}
+		return 0, nil
+	}
+	if ifMatch == "*" {
+		return 0, nil
+	}
+
+	// weak tags never match under the strong comparison If-Match requires
+	if strings.HasPrefix(ifMatch, "W/") {
+		return 0, msg.New(msg.CodePreconditionFailed, msg.ErrProductVersionMismatch)
+	}
+
+	version, err := strconv.Atoi(strings.Trim(ifMatch, `"`))
+	if err != nil || version <= 0 || !strings.HasPrefix(ifMatch, `"`) || !strings.HasSuffix(ifMatch, `"`) {
+		return 0, msg.BadRequest(msg.ErrInvalidIfMatch)
+	}
+
+	return version, nil
+}
+
+// notModified reports whether If-None-Match already names version.
+func notModified(c *gin.Context, version int) bool {
+	etag := formatETag(version)
+	for _, candidate := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func productIDParam(c *gin.Context) (string, error) {
+	productID := c.Param("id")
+	if productID == "" {
+		return "", msg.BadRequest(msg.ErrProductIDMissing)
+	}
+	if _, err := strconv.Atoi(productID); err != nil {
+		return "", msg.BadRequest(msg.ErrConvertIdToInt)
+	}
+	return productID, nil
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"projectsphere/eniqlo-store/internal/product/entity"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 100
+)
+
+// productFilter reads the search query string, e.g.
+// ?category=footwear&option[size]=42&inStock=true&locationId=1&sortBy=price&orderBy=asc&limit=20.
+func productFilter(c *gin.Context) (entity.ProductFilter, error) {
+	filter := entity.ProductFilter{
+		Name:        c.Query("name"),
+		SKU:         c.Query("sku"),
+		Barcode:     c.Query("barcode"),
+		Options:     c.QueryMap("option"),
+		Category:    c.Query("category"),
+		ImageStatus: c.Query("imageStatus"),
+		Limit:       defaultSearchLimit,
+		SortBy:      c.DefaultQuery("sortBy", "createdAt"),
+		OrderBy:     c.DefaultQuery("orderBy", "desc"),
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return entity.ProductFilter{}, msg.BadRequest(msg.ErrLimitNotNumber)
+		}
+		if limit < 1 || limit > maxSearchLimit {
+			return entity.ProductFilter{}, msg.BadRequest(msg.ErrLimitMustBetween0Until100)
+		}
+		filter.Limit = limit
+	}
+
+	if raw := c.Query("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return entity.ProductFilter{}, msg.BadRequest(msg.ErrInvalidFilterRequest)
+		}
+		filter.Offset = offset
+	}
+
+	if _, ok := entity.SortColumn(filter.SortBy); !ok {
+		return entity.ProductFilter{}, msg.BadRequest(msg.ErrInvalidSortRequest)
+	}
+	if filter.OrderBy != "asc" && filter.OrderBy != "desc" {
+		return entity.ProductFilter{}, msg.BadRequest(msg.ErrInvalidSortRequest)
+	}
+
+	switch filter.ImageStatus {
+	case "", entity.ImageStatusPending, entity.ImageStatusOK, entity.ImageStatusBroken:
+	default:
+		return entity.ProductFilter{}, msg.BadRequest(msg.ErrInvalidFilterRequest)
+	}
+
+	var err error
+	if filter.IsAvailable, err = boolQuery(c, "isAvailable"); err != nil {
+		return entity.ProductFilter{}, err
+	}
+	if filter.InStock, err = boolQuery(c, "inStock"); err != nil {
+		return entity.ProductFilter{}, err
+	}
+	if raw := c.Query("locationId"); raw != "" {
+		filter.LocationID, err = strconv.Atoi(raw)
+		if err != nil || filter.LocationID <= 0 {
+			return entity.ProductFilter{}, msg.BadRequest(msg.ErrInvalidFilterRequest)
+		}
+	}
+
+	return filter, nil
+}
+
+// boolQuery reads an optional true/false query parameter.
+func boolQuery(c *gin.Context, key string) (*bool, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, msg.BadRequest(msg.ErrInvalidFilterRequest)
+	}
+	return &value, nil
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"projectsphere/eniqlo-store/internal/product/entity"
+	svc "projectsphere/eniqlo-store/internal/product/service"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"projectsphere/eniqlo-store/pkg/validator"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead allows for the multipart boundaries and fields around
+// the files.
+const multipartOverhead = 1 << 20
+
+type ImageHandler struct {
+	imageSvc svc.ImageService
+	maxSize  int64
+	maxFiles int
+}
+
+func NewImageHandler(imageSvc svc.ImageService, maxSize int64, maxFiles int) ImageHandler {
+	return ImageHandler{
+		imageSvc: imageSvc,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+	}
+}
+
+func (h ImageHandler) List(c *gin.Context) {
+	productID, err := productIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	images, err := h.imageSvc.List(c.Request.Context(), productID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetAllResponse), images))
+}
+
+// Upload accepts one or more files in the multipart field "images".
+func (h ImageHandler) Upload(c *gin.Context) {
+	productID, err := productIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(h.maxFiles)*h.maxSize+multipartOverhead)
+	form, err := c.MultipartForm()
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			c.Error(msg.New(msg.CodePayloadTooLarge, msg.ErrImageTooLarge))
+			return
+		}
+		c.Error(msg.BadRequest(msg.ErrNoImageUploaded))
+		return
+	}
+
+	files := form.File["images"]
+	if len(files) == 0 {
+		c.Error(msg.BadRequest(msg.ErrNoImageUploaded))
+		return
+	}
+	if len(files) > h.maxFiles {
+		c.Error(msg.BadRequest(msg.ErrTooManyImages))
+		return
+	}
+
+	uploads := make([]entity.ImageUpload, 0, len(files))
+	for _, file := range files {
+		if file.Size > h.maxSize {
+			c.Error(msg.New(msg.CodePayloadTooLarge, msg.ErrImageTooLarge))
+			return
+		}
+
+		file := file
+		uploads = append(uploads, entity.ImageUpload{
+			Filename: file.Filename,
+			Size:     file.Size,
+			Open: func() (io.ReadCloser, error) {
+				return file.Open()
+			},
+		})
+	}
+
+	images, err := h.imageSvc.Upload(c.Request.Context(), productID, uploads)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, msg.ReturnResult(msg.T(c.Request.Context(), msg.ImageUploadedResponse), images))
+}
+
+// Reorder sets the image order from {"imageIds": [...]}.
+func (h ImageHandler) Reorder(c *gin.Context) {
+	productID, err := productIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	payload := new(entity.ImageOrderParam)
+	err = c.ShouldBindJSON(payload)
+	if err != nil {
+		c.Error(validator.BindError(err))
+		return
+	}
+
+	err = h.imageSvc.Reorder(c.Request.Context(), productID, payload.ImageIDs)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": msg.T(c.Request.Context(), msg.UpdateResponse)})
+}
+
+func (h ImageHandler) SetPrimary(c *gin.Context) {
+	productID, imageID, err := imageParams(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = h.imageSvc.SetPrimary(c.Request.Context(), productID, imageID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": msg.T(c.Request.Context(), msg.UpdateResponse)})
+}
+
+func (h ImageHandler) Delete(c *gin.Context) {
+	productID, imageID, err := imageParams(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = h.imageSvc.Delete(c.Request.Context(), productID, imageID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": msg.T(c.Request.Context(), msg.DeleteResponse)})
+}
+
+func imageParams(c *gin.Context) (string, int, error) {
+	productID, err := productIDParam(c)
+	if err != nil {
+		return "", 0, err
+	}
+
+	imageID, err := strconv.Atoi(c.Param("imageId"))
+	if err != nil || imageID <= 0 {
+		return "", 0, msg.BadRequest(msg.ErrConvertIdToInt)
+	}
+	return productID, imageID, nil
+}
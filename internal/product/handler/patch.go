@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"mime"
+	"projectsphere/eniqlo-store/internal/product/entity"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+
+	"github.com/gin-gonic/gin"
+)
+
+// patchContentType maps the request media type to a patch format. Plain
+// JSON is treated as a merge patch.
+func patchContentType(c *gin.Context) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if err != nil {
+		return "", msg.New(msg.CodeUnsupportedMediaType, msg.ErrUnsupportedPatchType)
+	}
+
+	switch mediaType {
+	case entity.MergePatchType, gin.MIMEJSON:
+		return entity.MergePatchType, nil
+	case entity.JSONPatchType:
+		return entity.JSONPatchType, nil
+	default:
+		return "", msg.New(msg.CodeUnsupportedMediaType, msg.ErrUnsupportedPatchType)
+	}
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"net/http"
+	"projectsphere/eniqlo-store/internal/product/entity"
+	svc "projectsphere/eniqlo-store/internal/product/service"
+	"projectsphere/eniqlo-store/pkg/middleware/auth"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"projectsphere/eniqlo-store/pkg/validator"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+type PriceHandler struct {
+	priceSvc svc.PriceService
+}
+
+func NewPriceHandler(priceSvc svc.PriceService) PriceHandler {
+	return PriceHandler{
+		priceSvc: priceSvc,
+	}
+}
+
+// List returns the current price of a product, its price history newest
+// first, paged with limit and offset, and its scheduled price changes.
+func (h PriceHandler) List(c *gin.Context) {
+	productID, err := productIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	limit, offset, err := pageParams(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	prices, err := h.priceSvc.Prices(c.Request.Context(), productID, entity.PriceFilter{Limit: limit, Offset: offset})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetDataResponse), prices))
+}
+
+// Schedule schedules a price change of the product, or of one of its
+// variants, from {"price": 45000, "effectiveAt": "2024-06-01T00:00:00+07:00"}.
+func (h PriceHandler) Schedule(c *gin.Context) {
+	productID, err := productIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	payload := new(entity.ScheduleParam)
+	if err := c.ShouldBindJSON(payload); err != nil {
+		c.Error(validator.BindError(err))
+		return
+	}
+
+	userID, err := auth.GetUserIdInsideCtx(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schedule, err := h.priceSvc.Schedule(c.Request.Context(), productID, *payload, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, msg.ReturnResult(msg.T(c.Request.Context(), msg.PriceScheduledResponse), schedule))
+}
+
+// Cancel cancels a price change that has not been applied yet.
+func (h PriceHandler) Cancel(c *gin.Context) {
+	productID, err := productIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	scheduleID, err := strconv.Atoi(c.Param("scheduleId"))
+	if err != nil || scheduleID <= 0 {
+		c.Error(msg.BadRequest(msg.ErrConvertIdToInt))
+		return
+	}
+
+	userID, err := auth.GetUserIdInsideCtx(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schedule, err := h.priceSvc.Cancel(c.Request.Context(), productID, scheduleID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.PriceScheduleCancelledResponse), schedule))
+}
+
+func pageParams(c *gin.Context) (limit, offset int, err error) {
+	limit = defaultPageLimit
+	if raw := c.Query("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil {
+			return 0, 0, msg.BadRequest(msg.ErrLimitNotNumber)
+		}
+		if limit < 1 || limit > maxPageLimit {
+			return 0, 0, msg.BadRequest(msg.ErrLimitMustBetween0Until100)
+		}
+	}
+	if raw := c.Query("offset"); raw != "" {
+		offset, err = strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return 0, 0, msg.BadRequest(msg.ErrInvalidFilterRequest)
+		}
+	}
+	return limit, offset, nil
+}
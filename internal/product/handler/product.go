@@ -1,134 +1,237 @@
 package handler
 
 import (
-	"fmt"
+	"bytes"
+	"io"
 	"net/http"
 	"projectsphere/eniqlo-store/internal/product/entity"
 	svc "projectsphere/eniqlo-store/internal/product/service"
 	"projectsphere/eniqlo-store/pkg/middleware/auth"
+	"projectsphere/eniqlo-store/pkg/middleware/logger"
 	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"projectsphere/eniqlo-store/pkg/validator"
 
 	"github.com/gin-gonic/gin"
 )
 
 type ProductHandler struct {
-	productSvc svc.ProductService
+	productSvc     svc.ProductService
+	requireIfMatch bool
 }
 
-func NewProductHandler(productSvc svc.ProductService) ProductHandler {
+func NewProductHandler(productSvc svc.ProductService, requireIfMatch bool) ProductHandler {
 	return ProductHandler{
-		productSvc: productSvc,
+		productSvc:     productSvc,
+		requireIfMatch: requireIfMatch,
 	}
 }
 func (h ProductHandler) Create(c *gin.Context) {
 
 	if c.GetHeader("Authorization") == "" {
-		c.JSON(http.StatusUnauthorized, msg.Unauthorization("No authorization header provided"))
+		c.Error(msg.Unauthorization(msg.ErrNoAuthHeader))
 		return
 	}
 
 	if c.Request.Body == nil {
-		c.JSON(http.StatusBadRequest, msg.BadRequest("Request body is empty"))
+		c.Error(msg.BadRequest(msg.ErrEmptyRequestBody))
 		return
 	}
 	payload := new(entity.Product)
 	err := c.ShouldBindJSON(payload)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, msg.BadRequest(err.Error()))
+		c.Error(validator.BindError(err))
 		return
 	}
 
 	userID, err := auth.GetUserIdInsideCtx(c)
 	if err != nil {
-		fmt.Println(err)
+		logger.FromContext(c.Request.Context()).Error().Err(err).Msg("cannot read userId from context")
 	}
 
-	if containsNull(payload) {
-		c.JSON(http.StatusBadRequest, msg.BadRequest("JSON payload contains null values"))
+	resp, err := h.productSvc.Create(c.Request.Context(), *payload, userID)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	resp, err := h.productSvc.Create(c.Request.Context(), *payload, userID)
+	resp.Message = msg.T(c.Request.Context(), resp.Message)
+	c.Header("ETag", formatETag(resp.Data.Version))
+	c.JSON(http.StatusCreated, resp)
+}
+
+// Search lists products filtered by name, sku, barcode, option values,
+// category (including its subcategories), imageStatus, isAvailable and
+// inStock.
+func (h ProductHandler) Search(c *gin.Context) {
+	filter, err := productFilter(c)
 	if err != nil {
-		respError := msg.UnwrapRespError(err)
-		c.JSON(respError.Code, respError)
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, resp)
+	products, err := h.productSvc.Search(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetAllResponse), products))
+}
+
+// Get returns a product and its variants with its version as ETag.
+func (h ProductHandler) Get(c *gin.Context) {
+	productID, err := productIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	product, err := h.productSvc.Get(c.Request.Context(), productID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("ETag", formatETag(product.Version))
+	if notModified(c, product.Version) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetByIDResponse), product))
 }
 
-// Update updates a product.
+// Update updates a product. Options and variants have their own endpoints.
 func (h ProductHandler) Update(c *gin.Context) {
 	if c.GetHeader("Authorization") == "" {
-		c.JSON(http.StatusUnauthorized, msg.Unauthorization("No authorization header provided"))
+		c.Error(msg.Unauthorization(msg.ErrNoAuthHeader))
+		return
+	}
+
+	productID, err := productIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	version, err := expectedVersion(c, h.requireIfMatch)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	if c.Request.Body == nil {
-		c.JSON(http.StatusBadRequest, msg.BadRequest("Request body is empty"))
+		c.Error(msg.BadRequest(msg.ErrEmptyRequestBody))
 		return
 	}
 
 	payload := new(entity.Product)
-	err := c.ShouldBindJSON(payload)
+	err = c.ShouldBindJSON(payload)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, msg.BadRequest(err.Error()))
+		c.Error(validator.BindError(err))
 		return
 	}
+	payload.ID = productID
 
-	if containsNull(payload) {
-		c.JSON(http.StatusBadRequest, msg.BadRequest("JSON payload contains null values"))
-		return
+	userID, err := auth.GetUserIdInsideCtx(c)
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Error().Err(err).Msg("cannot read userId from context")
 	}
 
-	err = h.productSvc.Update(c.Request.Context(), *payload)
+	newVersion, err := h.productSvc.Update(c.Request.Context(), *payload, version, userID)
 	if err != nil {
-		respError := msg.UnwrapRespError(err)
-		c.JSON(respError.Code, respError)
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Product updated successfully"})
+	c.Header("ETag", formatETag(newVersion))
+	c.JSON(http.StatusOK, gin.H{"message": msg.T(c.Request.Context(), msg.ProductUpdatedResponse)})
 }
 
-// Delete deletes a product.
-func (h ProductHandler) Delete(c *gin.Context) {
+// Patch partially updates a product with an RFC 7386 merge patch or an
+// RFC 6902 JSON Patch and reports the fields that changed.
+func (h ProductHandler) Patch(c *gin.Context) {
 	if c.GetHeader("Authorization") == "" {
-		c.JSON(http.StatusUnauthorized, msg.Unauthorization("No authorization header provided"))
+		c.Error(msg.Unauthorization(msg.ErrNoAuthHeader))
 		return
 	}
 
-	productID := c.Param("id")
-	if productID == "" {
-		c.JSON(http.StatusBadRequest, msg.BadRequest("Product ID is missing"))
+	productID, err := productIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	version, err := expectedVersion(c, h.requireIfMatch)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	patchType, err := patchContentType(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if c.Request.Body == nil {
+		c.Error(msg.BadRequest(msg.ErrEmptyRequestBody))
+		return
+	}
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Error(msg.BadRequest(err.Error()))
+		return
+	}
+	if len(bytes.TrimSpace(patch)) == 0 {
+		c.Error(msg.BadRequest(msg.ErrEmptyRequestBody))
 		return
 	}
 
 	userID, err := auth.GetUserIdInsideCtx(c)
 	if err != nil {
-		fmt.Println(err)
+		logger.FromContext(c.Request.Context()).Error().Err(err).Msg("cannot read userId from context")
 	}
 
-	err = h.productSvc.Delete(c.Request.Context(), productID, userID)
+	result, err := h.productSvc.Patch(c.Request.Context(), productID, patchType, patch, version, userID)
 	if err != nil {
-		respError := msg.UnwrapRespError(err)
-		c.JSON(respError.Code, respError)
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Product deleted successfully"})
+	c.Header("ETag", formatETag(result.Version))
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.ProductUpdatedResponse), result))
 }
 
-func containsNull(param *entity.Product) bool {
-	if param == nil {
-		return false
+// Delete deletes a product.
+func (h ProductHandler) Delete(c *gin.Context) {
+	if c.GetHeader("Authorization") == "" {
+		c.Error(msg.Unauthorization(msg.ErrNoAuthHeader))
+		return
+	}
+
+	productID, err := productIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	version, err := expectedVersion(c, h.requireIfMatch)
+	if err != nil {
+		c.Error(err)
+		return
 	}
 
-	// Check each field for null
-	if param.Name == "" || param.SKU == "" || param.Category == "" || param.ImageURL == "" || param.Notes == "" ||
-		param.Price <= 0 || param.Stock < 0 || param.Location == "" {
-		return true
+	userID, err := auth.GetUserIdInsideCtx(c)
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Error().Err(err).Msg("cannot read userId from context")
 	}
-	return false
+
+	err = h.productSvc.Delete(c.Request.Context(), productID, userID, version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": msg.T(c.Request.Context(), msg.ProductDeletedResponse)})
 }
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"net/http"
+	"projectsphere/eniqlo-store/internal/product/entity"
+	svc "projectsphere/eniqlo-store/internal/product/service"
+	"projectsphere/eniqlo-store/pkg/middleware/auth"
+	"projectsphere/eniqlo-store/pkg/middleware/logger"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"projectsphere/eniqlo-store/pkg/validator"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VariantHandler struct {
+	variantSvc     svc.VariantService
+	requireIfMatch bool
+}
+
+func NewVariantHandler(variantSvc svc.VariantService, requireIfMatch bool) VariantHandler {
+	return VariantHandler{
+		variantSvc:     variantSvc,
+		requireIfMatch: requireIfMatch,
+	}
+}
+
+func (h VariantHandler) List(c *gin.Context) {
+	productID, err := productIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	variants, err := h.variantSvc.List(c.Request.Context(), productID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetAllResponse), variants))
+}
+
+// SetOptions replaces the product's options from {"options": [...]} and
+// returns the regenerated variants.
+func (h VariantHandler) SetOptions(c *gin.Context) {
+	productID, err := productIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	version, err := expectedVersion(c, h.requireIfMatch)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	payload := new(entity.OptionsParam)
+	err = c.ShouldBindJSON(payload)
+	if err != nil {
+		c.Error(validator.BindError(err))
+		return
+	}
+
+	newVersion, err := h.variantSvc.SetOptions(c.Request.Context(), productID, payload.Options, version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	variants, err := h.variantSvc.List(c.Request.Context(), productID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("ETag", formatETag(newVersion))
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.ProductUpdatedResponse), variants))
+}
+
+// Update sets the SKU, barcode, price override and availability of a
+// variant; stock is changed with a stock adjustment. It changes the product, so it follows the product's If-Match.
+func (h VariantHandler) Update(c *gin.Context) {
+	productID, variantID, err := variantParams(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	version, err := expectedVersion(c, h.requireIfMatch)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	payload := new(entity.VariantParam)
+	err = c.ShouldBindJSON(payload)
+	if err != nil {
+		c.Error(validator.BindError(err))
+		return
+	}
+
+	userID, err := auth.GetUserIdInsideCtx(c)
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Error().Err(err).Msg("cannot read userId from context")
+	}
+
+	newVersion, err := h.variantSvc.Update(c.Request.Context(), productID, variantID, *payload, version, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("ETag", formatETag(newVersion))
+	c.JSON(http.StatusOK, gin.H{"message": msg.T(c.Request.Context(), msg.ProductUpdatedResponse)})
+}
+
+func variantParams(c *gin.Context) (string, int, error) {
+	productID, err := productIDParam(c)
+	if err != nil {
+		return "", 0, err
+	}
+
+	variantID, err := strconv.Atoi(c.Param("variantId"))
+	if err != nil || variantID <= 0 {
+		return "", 0, msg.BadRequest(msg.ErrConvertIdToInt)
+	}
+	return productID, variantID, nil
+}
@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"projectsphere/eniqlo-store/internal/product/entity"
+	"projectsphere/eniqlo-store/pkg/database"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ImageRepo struct {
+	dbConnector database.PostgresConnector
+}
+
+func NewImageRepo(dbConnector database.PostgresConnector) ImageRepo {
+	return ImageRepo{
+		dbConnector: dbConnector,
+	}
+}
+
+const imageColumns = `id_image, id_product, image_url, thumbnail_url, storage_key, thumbnail_key, content_type, size_bytes, width, height, position, is_primary, created_at`
+
+func (r ImageRepo) ListImages(ctx context.Context, productID string) ([]entity.ProductImage, error) {
+	images := []entity.ProductImage{}
+	err := r.dbConnector.DB.SelectContext(ctx, &images, `
+        SELECT `+imageColumns+`
+        FROM "product_images"
+        WHERE id_product = $1
+        ORDER BY position, id_image
+    `, productID)
+	if err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+
+	return images, nil
+}
+
+// AddImages appends images after the existing ones. The first image of a
+// product becomes its primary image.
+func (r ImageRepo) AddImages(ctx context.Context, productID string, images []entity.ProductImage) ([]entity.ProductImage, error) {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	if err := lockProduct(ctx, tx, productID); err != nil {
+		return nil, err
+	}
+
+	var position int
+	var hasPrimary bool
+	err = tx.QueryRowContext(ctx, `
+        SELECT COALESCE(MAX(position), 0), COALESCE(BOOL_OR(is_primary), false)
+        FROM "product_images"
+        WHERE id_product = $1
+    `, productID).Scan(&position, &hasPrimary)
+	if err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+
+	created := make([]entity.ProductImage, 0, len(images))
+	for i, image := range images {
+		position++
+		var row entity.ProductImage
+		err = tx.GetContext(ctx, &row, `
+            INSERT INTO "product_images" (id_product, image_url, thumbnail_url, storage_key, thumbnail_key, content_type, size_bytes, width, height, position, is_primary)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+            RETURNING `+imageColumns,
+			productID, image.ImageURL, image.ThumbnailURL, image.StorageKey, image.ThumbnailKey, image.ContentType,
+			image.Size, image.Width, image.Height, position, !hasPrimary && i == 0)
+		if err != nil {
+			return nil, msg.InternalServerError(err.Error())
+		}
+		created = append(created, row)
+	}
+
+	if !hasPrimary {
+		if err := setProductImageURL(ctx, tx, productID, created[0].ImageURL); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+
+	return created, nil
+}
+
+// ReorderImages sets positions following imageIDs, which must list every
+// image of the product exactly once.
+func (r ImageRepo) ReorderImages(ctx context.Context, productID string, imageIDs []int) error {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	if err := lockProduct(ctx, tx, productID); err != nil {
+		return err
+	}
+
+	var existing []int
+	err = tx.SelectContext(ctx, &existing, `SELECT id_image FROM "product_images" WHERE id_product = $1`, productID)
+	if err != nil {
+		return msg.InternalServerError(err.Error())
+	}
+	if !sameIDs(existing, imageIDs) {
+		return msg.BadRequest(msg.ErrImageOrderMismatch)
+	}
+
+	for i, imageID := range imageIDs {
+		_, err = tx.ExecContext(ctx, `UPDATE "product_images" SET position = $1 WHERE id_image = $2`, i+1, imageID)
+		if err != nil {
+			return msg.InternalServerError(err.Error())
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return msg.InternalServerError(err.Error())
+	}
+
+	return nil
+}
+
+// SetPrimaryImage makes imageID the primary image and the product's
+// imageUrl.
+func (r ImageRepo) SetPrimaryImage(ctx context.Context, productID string, imageID int) error {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	if err := lockProduct(ctx, tx, productID); err != nil {
+		return err
+	}
+
+	var imageURL string
+	err = tx.GetContext(ctx, &imageURL, `SELECT image_url FROM "product_images" WHERE id_image = $1 AND id_product = $2`, imageID, productID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return msg.NotFound(msg.ErrImageNotFound)
+		}
+		return msg.InternalServerError(err.Error())
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE "product_images" SET is_primary = false WHERE id_product = $1 AND is_primary`, productID)
+	if err != nil {
+		return msg.InternalServerError(err.Error())
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE "product_images" SET is_primary = true WHERE id_image = $1`, imageID)
+	if err != nil {
+		return msg.InternalServerError(err.Error())
+	}
+	if err := setProductImageURL(ctx, tx, productID, imageURL); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return msg.InternalServerError(err.Error())
+	}
+
+	return nil
+}
+
+// DeleteImage removes an image and returns it so its objects can be
+// deleted. The next image in order replaces a deleted primary image; the
+// product keeps its imageUrl when its last image is deleted.
+func (r ImageRepo) DeleteImage(ctx context.Context, productID string, imageID int) (entity.ProductImage, error) {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return entity.ProductImage{}, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	if err := lockProduct(ctx, tx, productID); err != nil {
+		return entity.ProductImage{}, err
+	}
+
+	var image entity.ProductImage
+	err = tx.GetContext(ctx, &image, `
+        DELETE FROM "product_images"
+        WHERE id_image = $1 AND id_product = $2
+        RETURNING `+imageColumns, imageID, productID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return entity.ProductImage{}, msg.NotFound(msg.ErrImageNotFound)
+		}
+		return entity.ProductImage{}, msg.InternalServerError(err.Error())
+	}
+
+	if image.IsPrimary {
+		var next entity.ProductImage
+		err = tx.GetContext(ctx, &next, `
+            UPDATE "product_images" SET is_primary = true
+            WHERE id_image = (SELECT id_image FROM "product_images" WHERE id_product = $1 ORDER BY position, id_image LIMIT 1)
+            RETURNING `+imageColumns, productID)
+		switch {
+		case err == sql.ErrNoRows:
+		case err != nil:
+			return entity.ProductImage{}, msg.InternalServerError(err.Error())
+		default:
+			if err := setProductImageURL(ctx, tx, productID, next.ImageURL); err != nil {
+				return entity.ProductImage{}, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.ProductImage{}, msg.InternalServerError(err.Error())
+	}
+
+	return image, nil
+}
+
+// lockProduct serializes image changes of a product.
+func lockProduct(ctx context.Context, tx *sqlx.Tx, productID string) error {
+	var id int
+	err := tx.GetContext(ctx, &id, `SELECT id_product FROM "products" WHERE id_product = $1 FOR UPDATE`, productID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return msg.NotFound(msg.ErrProductNotFound)
+		}
+		return msg.InternalServerError(err.Error())
+	}
+	return nil
+}
+
+// setProductImageURL keeps products.image_url on the primary image. It is a
+// product change, so the version is bumped.
+func setProductImageURL(ctx context.Context, tx *sqlx.Tx, productID, imageURL string) error {
+	_, err := tx.ExecContext(ctx, `
+        UPDATE "products"
+        SET image_url = $1, updated_at = CURRENT_TIMESTAMP, version = version + 1
+        WHERE id_product = $2
+    `, imageURL, productID)
+	if err != nil {
+		return msg.InternalServerError(err.Error())
+	}
+	return nil
+}
+
+func sameIDs(existing, ordered []int) bool {
+	if len(existing) != len(ordered) {
+		return false
+	}
+
+	seen := make(map[int]bool, len(existing))
+	for _, id := range existing {
+		seen[id] = true
+	}
+	for _, id := range ordered {
+		if !seen[id] {
+			return false
+		}
+		delete(seen, id)
+	}
+	return true
+}
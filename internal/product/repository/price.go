@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"projectsphere/eniqlo-store/internal/product/entity"
+	"projectsphere/eniqlo-store/pkg/database"
+	"projectsphere/eniqlo-store/pkg/money"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type PriceRepo struct {
+	dbConnector database.PostgresConnector
+}
+
+func NewPriceRepo(dbConnector database.PostgresConnector) PriceRepo {
+	return PriceRepo{
+		dbConnector: dbConnector,
+	}
+}
+
+const scheduleColumns = `id_schedule, id_product, id_variant, price, effective_at, status, note, created_by, applied_at, cancelled_by,
+            cancelled_at, created_at`
+
+// Prices returns the current price of a product with a page of its price
+// history and its pending price schedules.
+func (r PriceRepo) Prices(ctx context.Context, productID string, filter entity.PriceFilter) (entity.Prices, error) {
+	prices := entity.Prices{History: []entity.PriceChange{}, Scheduled: []entity.ScheduledPrice{}}
+	err := r.dbConnector.DB.GetContext(ctx, &prices.Price, `SELECT price FROM "products" WHERE id_product = $1`, productID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return entity.Prices{}, msg.NotFound(msg.ErrProductNotFound)
+		}
+		return entity.Prices{}, msg.InternalServerError(err.Error())
+	}
+
+	err = r.dbConnector.DB.SelectContext(ctx, &prices.History, `
+        SELECT id_price_change, id_product, id_variant, old_price, price, id_schedule, user_id, created_at
+        FROM "price_history"
+        WHERE id_product = $1
+        ORDER BY id_price_change DESC
+        LIMIT $2 OFFSET $3
+    `, productID, filter.Limit, filter.Offset)
+	if err != nil {
+		return entity.Prices{}, msg.InternalServerError(err.Error())
+	}
+
+	err = r.dbConnector.DB.SelectContext(ctx, &prices.Scheduled, `
+        SELECT `+scheduleColumns+`
+        FROM "price_schedules"
+        WHERE id_product = $1 AND status = 'pending'
+        ORDER BY effective_at, id_schedule
+    `, productID)
+	if err != nil {
+		return entity.Prices{}, msg.InternalServerError(err.Error())
+	}
+
+	return prices, nil
+}
+
+// SchedulePrice schedules a price change of a product, or of one of its
+// variants when param.VariantID is set.
+func (r PriceRepo) SchedulePrice(ctx context.Context, productID string, param entity.ScheduleParam, userID uint32) (entity.ScheduledPrice, error) {
+	var exists bool
+	err := r.dbConnector.DB.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM "products" WHERE id_product = $1)`, productID)
+	if err != nil {
+		return entity.ScheduledPrice{}, msg.InternalServerError(err.Error())
+	}
+	if !exists {
+		return entity.ScheduledPrice{}, msg.NotFound(msg.ErrProductNotFound)
+	}
+
+	var variantID *int
+	if param.VariantID != 0 {
+		err = r.dbConnector.DB.GetContext(ctx, &exists, `
+            SELECT EXISTS (SELECT 1 FROM "product_variants" WHERE id_variant = $1 AND id_product = $2)
+        `, param.VariantID, productID)
+		if err != nil {
+			return entity.ScheduledPrice{}, msg.InternalServerError(err.Error())
+		}
+		if !exists {
+			return entity.ScheduledPrice{}, msg.NotFound(msg.ErrVariantNotFound)
+		}
+		variantID = &param.VariantID
+	}
+
+	// effective_at is a local timestamp like CURRENT_TIMESTAMP, so the
+	// instant is converted in the session time zone
+	var schedule entity.ScheduledPrice
+	err = r.dbConnector.DB.GetContext(ctx, &schedule, `
+        INSERT INTO "price_schedules" (id_product, id_variant, price, effective_at, note, created_by)
+        VALUES ($1, $2, $3, $4::timestamptz, $5, $6)
+        RETURNING `+scheduleColumns,
+		productID, variantID, param.Price, param.EffectiveAt, param.Note, userID)
+	if err != nil {
+		return entity.ScheduledPrice{}, msg.InternalServerError(err.Error())
+	}
+
+	return schedule, nil
+}
+
+// CancelSchedule cancels a pending price schedule of a product.
+func (r PriceRepo) CancelSchedule(ctx context.Context, productID string, scheduleID int, userID uint32) (entity.ScheduledPrice, error) {
+	var schedule entity.ScheduledPrice
+	err := r.dbConnector.DB.GetContext(ctx, &schedule, `
+        UPDATE "price_schedules"
+        SET status = 'cancelled', cancelled_by = $1, cancelled_at = CURRENT_TIMESTAMP
+        WHERE id_schedule = $2 AND id_product = $3 AND status = 'pending'
+        RETURNING `+scheduleColumns,
+		userID, scheduleID, productID)
+	if err == nil {
+		return schedule, nil
+	}
+	if err != sql.ErrNoRows {
+		return entity.ScheduledPrice{}, msg.InternalServerError(err.Error())
+	}
+
+	var exists bool
+	err = r.dbConnector.DB.GetContext(ctx, &exists, `
+        SELECT EXISTS (SELECT 1 FROM "price_schedules" WHERE id_schedule = $1 AND id_product = $2)
+    `, scheduleID, productID)
+	if err != nil {
+		return entity.ScheduledPrice{}, msg.InternalServerError(err.Error())
+	}
+	if !exists {
+		return entity.ScheduledPrice{}, msg.NotFound(msg.ErrPriceScheduleNotFound)
+	}
+	return entity.ScheduledPrice{}, msg.Conflict(msg.ErrPriceScheduleClosed)
+}
+
+// DueProducts returns up to limit products with a price schedule that is
+// due, those due the longest first.
+func (r PriceRepo) DueProducts(ctx context.Context, limit int) ([]int, error) {
+	productIDs := []int{}
+	err := r.dbConnector.DB.SelectContext(ctx, &productIDs, `
+        SELECT id_product
+        FROM "price_schedules"
+        WHERE status = 'pending' AND effective_at <= CURRENT_TIMESTAMP
+        GROUP BY id_product
+        ORDER BY MIN(effective_at), id_product
+        LIMIT $1
+    `, limit)
+	if err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+	return productIDs, nil
+}
+
+// ApplyDue applies the due price schedules of one product and returns how
+// many were applied.
+func (r PriceRepo) ApplyDue(ctx context.Context, productID int) (int, error) {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `SELECT id_product FROM "products" WHERE id_product = $1 FOR UPDATE`, productID)
+	if err != nil {
+		return 0, msg.InternalServerError(err.Error())
+	}
+
+	applied, err := ApplyDuePrices(ctx, tx, []int{productID})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, msg.InternalServerError(err.Error())
+	}
+
+	return applied, nil
+}
+
+// ApplyDuePrices applies the due price schedules of productIDs in the order
+// they took effect, recording each in the price history, and returns how
+// many were applied. The products must be locked by tx, which keeps the
+// scheduler and a checkout from applying the same schedule twice.
+func ApplyDuePrices(ctx context.Context, tx *sqlx.Tx, productIDs []int) (int, error) {
+	var schedules []entity.ScheduledPrice
+	err := tx.SelectContext(ctx, &schedules, `
+        SELECT `+scheduleColumns+`
+        FROM "price_schedules"
+        WHERE id_product = ANY($1) AND status = 'pending' AND effective_at <= CURRENT_TIMESTAMP
+        ORDER BY effective_at, id_schedule
+        FOR UPDATE
+    `, pq.Array(productIDs))
+	if err != nil {
+		return 0, msg.InternalServerError(err.Error())
+	}
+
+	for _, schedule := range schedules {
+		change := entity.PriceChange{
+			ProductID:  schedule.ProductID,
+			VariantID:  schedule.VariantID,
+			Price:      &schedule.Price,
+			ScheduleID: &schedule.ID,
+			UserID:     schedule.CreatedBy,
+		}
+
+		if schedule.VariantID == nil {
+			err = tx.GetContext(ctx, &change.OldPrice, `
+                UPDATE "products" p
+                SET price = $1, updated_at = CURRENT_TIMESTAMP, version = p.version + 1
+                FROM (SELECT price FROM "products" WHERE id_product = $2) old
+                WHERE p.id_product = $2
+                RETURNING old.price
+            `, schedule.Price, schedule.ProductID)
+		} else {
+			err = tx.GetContext(ctx, &change.OldPrice, `
+                UPDATE "product_variants" v
+                SET price = $1, updated_at = CURRENT_TIMESTAMP
+                FROM (SELECT price FROM "product_variants" WHERE id_variant = $2) old
+                WHERE v.id_variant = $2
+                RETURNING old.price
+            `, schedule.Price, *schedule.VariantID)
+			if err == nil {
+				_, err = tx.ExecContext(ctx, `
+                    UPDATE "products" SET updated_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id_product = $1
+                `, schedule.ProductID)
+			}
+		}
+		if err != nil {
+			return 0, msg.InternalServerError(err.Error())
+		}
+
+		if err := recordPriceChange(ctx, tx, change); err != nil {
+			return 0, err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+            UPDATE "price_schedules" SET status = 'applied', applied_at = CURRENT_TIMESTAMP WHERE id_schedule = $1
+        `, schedule.ID)
+		if err != nil {
+			return 0, msg.InternalServerError(err.Error())
+		}
+	}
+
+	return len(schedules), nil
+}
+
+// recordPriceChange appends change to the price history unless the price
+// stayed the same.
+func recordPriceChange(ctx context.Context, tx *sqlx.Tx, change entity.PriceChange) error {
+	if samePrice(change.OldPrice, change.Price) {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO "price_history" (id_product, id_variant, old_price, price, id_schedule, user_id)
+        VALUES ($1, $2, $3, $4, $5, $6)
+    `, change.ProductID, change.VariantID, change.OldPrice, change.Price, change.ScheduleID, change.UserID)
+	if err != nil {
+		return msg.InternalServerError(err.Error())
+	}
+	return nil
+}
+
+func samePrice(a, b *money.Money) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Cmp(*b) == 0
+}
@@ -3,9 +3,18 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	inventoryEntity "projectsphere/eniqlo-store/internal/inventory/entity"
 	"projectsphere/eniqlo-store/internal/product/entity"
 	"projectsphere/eniqlo-store/pkg/database"
+	"projectsphere/eniqlo-store/pkg/money"
 	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 type ProductRepo struct {
@@ -18,78 +27,412 @@ func NewProductRepo(dbConnector database.PostgresConnector) ProductRepo {
 	}
 }
 
-func (r ProductRepo) UpdateProduct(product entity.Product) error {
+func (r ProductRepo) GetProduct(ctx context.Context, id string) (entity.Product, error) {
+	query := `
+        SELECT id_product AS id, name, sku, category, image_url, image_status, cached_image_url, notes, price, cost, stock, reorder_point, reorder_quantity, location, is_available, attributes, options, version, created_at, updated_at
+        FROM "products"
+        WHERE id_product = $1
+    `
+
+	var product entity.Product
+	err := r.dbConnector.DB.GetContext(ctx, &product, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return entity.Product{}, msg.NotFound(msg.ErrProductNotFound)
+		}
+		return entity.Product{}, msg.InternalServerError(err.Error())
+	}
+
+	return product, nil
+}
+
+// SearchProducts lists products matching filter. Filter.Categories holds the
+// category keys to match, already expanded to subcategories. SKU matches the
+// product or any of its variants; barcode and options match variants;
+// locationId matches the stock held at a location.
+func (r ProductRepo) SearchProducts(ctx context.Context, filter entity.ProductFilter) ([]entity.Product, error) {
+	var conditions []string
+	var args []interface{}
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Name != "" {
+		where("name ILIKE '%%' || $%d || '%%'", filter.Name)
+	}
+	if filter.SKU != "" {
+		where(`(sku = $%[1]d OR EXISTS (SELECT 1 FROM "product_variants" v WHERE v.id_product = products.id_product AND v.sku = $%[1]d))`, filter.SKU)
+	}
+	if filter.Barcode != "" {
+		where(`EXISTS (SELECT 1 FROM "product_variants" v WHERE v.id_product = products.id_product AND v.barcode = $%d)`, filter.Barcode)
+	}
+	if filter.Category != "" {
+		where("category = ANY($%d)", pq.Array(filter.Categories))
+	}
+	if filter.ImageStatus != "" {
+		where("image_status = $%d", filter.ImageStatus)
+	}
+	if filter.IsAvailable != nil {
+		where("is_available = $%d", *filter.IsAvailable)
+	}
+	if len(filter.Options) > 0 {
+		// with inStock=true the matching variant itself has to be in stock
+		condition := `EXISTS (SELECT 1 FROM "product_variants" v WHERE v.id_product = products.id_product AND v.options @> $%d`
+		if filter.InStock != nil && *filter.InStock {
+			condition += " AND v.stock > 0"
+		}
+		where(condition+")", filter.Options)
+	}
+	switch {
+	case filter.LocationID != 0:
+		// held at the location; with inStock=false, not held there
+		condition := `EXISTS (SELECT 1 FROM "variant_stocks" s JOIN "product_variants" v ON v.id_variant = s.id_variant
+            WHERE v.id_product = products.id_product AND s.id_location = $%d AND s.stock > 0)`
+		if filter.InStock != nil && !*filter.InStock {
+			condition = "NOT " + condition
+		}
+		where(condition, filter.LocationID)
+	case filter.InStock != nil && *filter.InStock:
+		conditions = append(conditions, "stock > 0")
+	case filter.InStock != nil:
+		conditions = append(conditions, "stock = 0")
+	}
+
+	query := `
+        SELECT id_product AS id, name, sku, category, image_url, image_status, cached_image_url, notes, price, cost, stock, reorder_point, reorder_quantity, location, is_available, attributes, options, version, created_at, updated_at
+        FROM "products"
+    `
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	column, _ := entity.SortColumn(filter.SortBy)
+	query += fmt.Sprintf(" ORDER BY %s %s, id_product %s LIMIT %d OFFSET %d", column, filter.OrderBy, filter.OrderBy, filter.Limit, filter.Offset)
+
+	products := []entity.Product{}
+	err := r.dbConnector.DB.SelectContext(ctx, &products, query, args...)
+	if err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+
+	return products, nil
+}
+
+// UpdateProduct only updates the product when its version still equals
+// expectedVersion, or unconditionally when expectedVersion is zero, and
+// returns the new version. A price change is recorded in the price history.
+// Stock is only changed through a stock adjustment, so a differing stock is
+// rejected; the stock of a product with options is the sum of its variants
+// and left alone.
+func (r ProductRepo) UpdateProduct(ctx context.Context, product entity.Product, expectedVersion int, userID uint32) (int, error) {
 	query := `
         UPDATE "products"
-        SET name = $1, sku = $2, category = $3, image_url = $4, notes = $5, price = $6, stock = $7, location = $8, is_available = $9, updated_at = $10
-        WHERE id_product = $11
+        SET name = $1, sku = $2, category = $3, image_url = $4, notes = $5, price = $6, location = $7, is_available = $8,
+            attributes = $9, reorder_point = $10, reorder_quantity = $11, updated_at = CURRENT_TIMESTAMP, version = version + 1
+        WHERE id_product = $12 AND ($13 = 0 OR version = $13)
+        RETURNING version
     `
 
-	_, err := r.dbConnector.DB.Exec(query,
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	var oldPrice money.Money
+	err = tx.GetContext(ctx, &oldPrice, `SELECT price FROM "products" WHERE id_product = $1 FOR UPDATE`, product.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, msg.NotFound(msg.ErrProductNotFound)
+		}
+		return 0, msg.InternalServerError(err.Error())
+	}
+
+	var version int
+	err = tx.QueryRowContext(ctx, query,
 		product.Name,
 		product.SKU,
 		product.Category,
 		product.ImageURL,
 		product.Notes,
 		product.Price,
-		product.Stock,
 		product.Location,
 		product.IsAvailable,
-		product.UpdatedAt,
-		product.ID)
+		product.Attributes,
+		product.ReorderPoint,
+		product.ReorderQuantity,
+		product.ID,
+		expectedVersion).Scan(&version)
 
 	if err != nil {
-		return msg.InternalServerError(err.Error())
+		if err == sql.ErrNoRows {
+			return 0, r.conflictOrNotFound(ctx, product.ID)
+		}
+		return 0, msg.InternalServerError(err.Error())
 	}
 
-	return nil
+	productID, _ := strconv.Atoi(product.ID)
+	err = recordPriceChange(ctx, tx, entity.PriceChange{
+		ProductID: productID,
+		OldPrice:  &oldPrice,
+		Price:     &product.Price,
+		UserID:    inventoryEntity.StaffID(userID),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := syncDefaultVariant(ctx, tx, product.ID); err != nil {
+		return 0, err
+	}
+	if err := requireDefaultVariantStock(ctx, tx, product.ID, product.Stock); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, msg.InternalServerError(err.Error())
+	}
+
+	return version, nil
 }
 
-func (r ProductRepo) DeleteProduct(id string, userId uint32) error {
+// DeleteProduct follows the same version check as UpdateProduct. It returns
+// the product's images, whose rows are deleted with it, so that their stored
+// objects can be removed too.
+func (r ProductRepo) DeleteProduct(ctx context.Context, id string, userId uint32, expectedVersion int) ([]entity.ProductImage, error) {
+	// the join sees product_images before the cascade, every statement of
+	// the query shares one snapshot
 	query := `
-        DELETE FROM "products"
-        WHERE id_product = $1 AND user_id = $2
+        WITH deleted AS (
+            DELETE FROM "products"
+            WHERE id_product = $1 AND user_id = $2 AND ($3 = 0 OR version = $3)
+            RETURNING id_product
+        )
+        SELECT COALESCE(i.storage_key, '') AS storage_key, COALESCE(i.thumbnail_key, '') AS thumbnail_key
+        FROM deleted d
+        LEFT JOIN "product_images" i ON i.id_product = d.id_product
     `
 
-	result, err := r.dbConnector.DB.Exec(query, id, userId)
+	var rows []entity.ProductImage
+	err := r.dbConnector.DB.SelectContext(ctx, &rows, query, id, userId, expectedVersion)
 	if err != nil {
-		return msg.InternalServerError(err.Error())
+		return nil, msg.InternalServerError(err.Error())
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return msg.NotFound("product not found")
+	if len(rows) == 0 {
+		var exists bool
+		err := r.dbConnector.DB.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM "products" WHERE id_product = $1 AND user_id = $2)`, id, userId)
+		if err != nil {
+			return nil, msg.InternalServerError(err.Error())
+		}
+		if !exists || expectedVersion == 0 {
+			return nil, msg.NotFound(msg.ErrProductNotFound)
+		}
+		return nil, msg.New(msg.CodePreconditionFailed, msg.ErrProductVersionMismatch)
+	}
+
+	images := make([]entity.ProductImage, 0, len(rows))
+	for _, image := range rows {
+		if image.StorageKey != "" {
+			images = append(images, image)
+		}
+	}
+
+	return images, nil
+}
+
+// PatchProduct sets only the changed columns, under the same version check
+// as UpdateProduct, and records the changes in product_changes. A price
+// change is added to the price history and a stock change is rejected.
+func (r ProductRepo) PatchProduct(ctx context.Context, id string, expectedVersion int, userID uint32, changes []entity.FieldChange) (int, error) {
+	sets := make([]string, 0, len(changes)+2)
+	args := make([]interface{}, 0, len(changes)+2)
+	diff := make(map[string]entity.FieldChange, len(changes))
+	for _, change := range changes {
+		diff[change.Field] = change
+		if change.Column == "stock" {
+			return 0, stockAdjustOnly()
+		}
+		args = append(args, change.New)
+		sets = append(sets, fmt.Sprintf("%s = $%d", change.Column, len(args)))
+	}
+	sets = append(sets, "updated_at = CURRENT_TIMESTAMP", "version = version + 1")
+	args = append(args, id, expectedVersion)
+
+	query := fmt.Sprintf(`
+        UPDATE "products"
+        SET %s
+        WHERE id_product = $%d AND ($%d = 0 OR version = $%d)
+        RETURNING version
+    `, strings.Join(sets, ", "), len(args)-1, len(args), len(args))
+
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	var version int
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, r.conflictOrNotFound(ctx, id)
+		}
+		return 0, msg.InternalServerError(err.Error())
+	}
+
+	diffJSON, err := json.Marshal(diff)
+	if err != nil {
+		return 0, msg.InternalServerError(err.Error())
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO "product_changes" (id_product, user_id, version, changes)
+        VALUES ($1, $2, $3, $4)
+    `, id, userID, version, diffJSON)
+	if err != nil {
+		return 0, msg.InternalServerError(err.Error())
+	}
+
+	if change, ok := diff["price"]; ok {
+		productID, _ := strconv.Atoi(id)
+		oldPrice, newPrice := change.Old.(money.Money), change.New.(money.Money)
+		err = recordPriceChange(ctx, tx, entity.PriceChange{
+			ProductID: productID,
+			OldPrice:  &oldPrice,
+			Price:     &newPrice,
+			UserID:    inventoryEntity.StaffID(userID),
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := syncDefaultVariant(ctx, tx, id); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, msg.InternalServerError(err.Error())
 	}
 
-	return nil
+	return version, nil
 }
 
+// CreateProduct inserts the product together with param.Variants. The
+// stock of a product without options is booked as its initial stock and
+// its price starts the price history.
 func (r ProductRepo) CreateProduct(ctx context.Context, param entity.Product, userID uint32) (entity.Product, error) {
 	var product entity.Product
 	query := `
-        INSERT INTO "products" (name, sku, category, image_url, notes, price, stock, location, is_available, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-        RETURNING id_product
+        INSERT INTO "products" (user_id, name, sku, category, image_url, notes, price, stock, location, is_available, attributes, options,
+            reorder_point, reorder_quantity)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, 0, $8, $9, $10, $11, $12, $13)
+        RETURNING id_product, version, created_at
     `
 
-	err := r.dbConnector.DB.QueryRowContext(ctx, query, param.Name,
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return entity.Product{}, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, query, userID,
+		param.Name,
 		param.SKU,
 		param.Category,
 		param.ImageURL,
 		param.Notes,
 		param.Price,
-		param.Stock,
 		param.Location,
 		param.IsAvailable,
-		param.CreatedAt,
-		param.UpdatedAt).Scan(&product.ID)
+		param.Attributes,
+		param.Options,
+		param.ReorderPoint,
+		param.ReorderQuantity).Scan(&product.ID, &product.Version, &product.CreatedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return entity.Product{}, msg.BadRequest("no rows were returned")
+			return entity.Product{}, msg.BadRequest(msg.ErrNoRowsReturned)
 		}
 		return entity.Product{}, msg.InternalServerError(err.Error())
 	}
 
+	if err := insertVariants(ctx, tx, product.ID, param.Variants); err != nil {
+		return entity.Product{}, err
+	}
+	productID, _ := strconv.Atoi(product.ID)
+	err = recordPriceChange(ctx, tx, entity.PriceChange{
+		ProductID: productID,
+		Price:     &param.Price,
+		UserID:    inventoryEntity.StaffID(userID),
+	})
+	if err != nil {
+		return entity.Product{}, err
+	}
+	if err := setDefaultVariantStock(ctx, tx, product.ID, param.Stock, userID, "initial stock"); err != nil {
+		return entity.Product{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.Product{}, msg.InternalServerError(err.Error())
+	}
+
 	return product, nil
 }
+
+// ImagesToVerify returns up to limit products whose image was never
+// checked or was last checked before checkedBefore, oldest first.
+func (r ProductRepo) ImagesToVerify(ctx context.Context, checkedBefore time.Time, limit int) ([]entity.ImageCheck, error) {
+	query := `
+        SELECT id_product AS id, image_url, image_status, COALESCE(cached_image_url, '') AS cached_image_url,
+            COALESCE(image_etag, '') AS image_etag, COALESCE(stale_cached_image_url, '') AS stale_cached_image_url
+        FROM "products"
+        WHERE image_checked_at IS NULL OR image_checked_at < $1
+        ORDER BY image_checked_at NULLS FIRST, id_product
+        LIMIT $2
+    `
+
+	var checks []entity.ImageCheck
+	err := r.dbConnector.DB.SelectContext(ctx, &checks, query, checkedBefore, limit)
+	if err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+
+	return checks, nil
+}
+
+// SaveImageCheck stores a verification result unless the product's image
+// changed in the meantime, reporting whether it did. It is server side
+// metadata, so the product version stays.
+func (r ProductRepo) SaveImageCheck(ctx context.Context, check entity.ImageCheck) (bool, error) {
+	query := `
+        UPDATE "products"
+        SET image_status = $1, image_error = NULLIF($2, ''), cached_image_url = NULLIF($3, ''), image_etag = NULLIF($4, ''),
+            stale_cached_image_url = NULL, image_checked_at = CURRENT_TIMESTAMP
+        WHERE id_product = $5 AND image_url = $6
+    `
+
+	result, err := r.dbConnector.DB.ExecContext(ctx, query, check.Status, check.Error, check.CachedImage, check.ETag, check.ProductID, check.ImageURL)
+	if err != nil {
+		return false, msg.InternalServerError(err.Error())
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+
+	return rowsAffected > 0, nil
+}
+
+// conflictOrNotFound explains why a versioned write matched no row.
+func (r ProductRepo) conflictOrNotFound(ctx context.Context, id string) error {
+	var exists bool
+	err := r.dbConnector.DB.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM "products" WHERE id_product = $1)`, id)
+	if err != nil {
+		return msg.InternalServerError(err.Error())
+	}
+	if !exists {
+		return msg.NotFound(msg.ErrProductNotFound)
+	}
+	return msg.New(msg.CodePreconditionFailed, msg.ErrProductVersionMismatch)
+}
@@ -0,0 +1,309 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	inventoryEntity "projectsphere/eniqlo-store/internal/inventory/entity"
+	inventoryRepository "projectsphere/eniqlo-store/internal/inventory/repository"
+	"projectsphere/eniqlo-store/internal/product/entity"
+	"projectsphere/eniqlo-store/pkg/database"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type VariantRepo struct {
+	dbConnector database.PostgresConnector
+}
+
+func NewVariantRepo(dbConnector database.PostgresConnector) VariantRepo {
+	return VariantRepo{
+		dbConnector: dbConnector,
+	}
+}
+
+const variantColumns = `v.id_variant, v.id_product, v.options, v.sku, v.barcode, v.price, COALESCE(v.price, p.price) AS effective_price,
+            v.stock, v.is_available, v.position, v.created_at, v.updated_at`
+
+func (r VariantRepo) ListVariants(ctx context.Context, productID string) ([]entity.Variant, error) {
+	return listVariants(ctx, r.dbConnector.DB, productID)
+}
+
+// SetOptions replaces the option definitions of a product and regenerates
+// its variants, keeping the variants whose combination still exists.
+// Variants that would be dropped must be out of stock and not in transit.
+// It returns the new product version.
+func (r VariantRepo) SetOptions(ctx context.Context, productID string, options entity.Options, expectedVersion int) (int, error) {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	product, err := lockProductVersion(ctx, tx, productID, expectedVersion)
+	if err != nil {
+		return 0, err
+	}
+
+	existing, err := listVariants(ctx, tx, productID)
+	if err != nil {
+		return 0, err
+	}
+
+	plan := entity.PlanVariants(product.SKU, options, existing)
+	for _, variant := range plan.Remove {
+		if variant.Stock > 0 {
+			return 0, msg.Conflict(msg.ErrVariantHasStock)
+		}
+		var inTransit bool
+		err = tx.GetContext(ctx, &inTransit, `
+            SELECT EXISTS (
+                SELECT 1
+                FROM "stock_transfer_items" i
+                JOIN "stock_transfers" t ON t.id_transfer = i.id_transfer
+                WHERE i.id_variant = $1 AND t.status = 'in_transit'
+            )
+        `, variant.ID)
+		if err != nil {
+			return 0, msg.InternalServerError(err.Error())
+		}
+		if inTransit {
+			return 0, msg.Conflict(msg.ErrVariantHasStock)
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM "product_variants" WHERE id_variant = $1`, variant.ID)
+		if err != nil {
+			return 0, msg.InternalServerError(err.Error())
+		}
+	}
+	for _, variant := range plan.Keep {
+		_, err = tx.ExecContext(ctx, `UPDATE "product_variants" SET position = $1 WHERE id_variant = $2`, variant.Position, variant.ID)
+		if err != nil {
+			return 0, msg.InternalServerError(err.Error())
+		}
+	}
+	if err := insertVariants(ctx, tx, productID, plan.Create); err != nil {
+		return 0, err
+	}
+
+	var version int
+	err = tx.QueryRowContext(ctx, `
+        UPDATE "products"
+        SET options = $1, updated_at = CURRENT_TIMESTAMP, version = version + 1
+        WHERE id_product = $2
+        RETURNING version
+    `, options, productID).Scan(&version)
+	if err != nil {
+		return 0, msg.InternalServerError(err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, msg.InternalServerError(err.Error())
+	}
+
+	return version, nil
+}
+
+// UpdateVariant writes a variant, recording a price change by userID in the
+// price history, and keeps the SKU of a product without options in step
+// with it. A stock differing from the variant's is rejected, stock is only
+// changed through a stock adjustment. It returns the new product version.
+func (r VariantRepo) UpdateVariant(ctx context.Context, productID string, variantID int, param entity.VariantParam, expectedVersion int, userID uint32) (int, error) {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	if _, err := lockProductVersion(ctx, tx, productID, expectedVersion); err != nil {
+		return 0, err
+	}
+
+	// the variant's old price is returned for the price history
+	var variant entity.Variant
+	err = tx.GetContext(ctx, &variant, `
+        UPDATE "product_variants" v
+        SET sku = $1, barcode = $2, price = $3, is_available = $4, updated_at = CURRENT_TIMESTAMP
+        FROM (SELECT price FROM "product_variants" WHERE id_variant = $5) old
+        WHERE v.id_variant = $5 AND v.id_product = $6
+        RETURNING v.id_product, v.stock, old.price
+    `, param.SKU, param.Barcode, param.Price, param.IsAvailable, variantID, productID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, msg.NotFound(msg.ErrVariantNotFound)
+		}
+		return 0, variantWriteError(err)
+	}
+
+	err = recordPriceChange(ctx, tx, entity.PriceChange{
+		ProductID: variant.ProductID,
+		VariantID: &variantID,
+		OldPrice:  variant.Price,
+		Price:     param.Price,
+		UserID:    inventoryEntity.StaffID(userID),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if param.Stock != nil && *param.Stock != variant.Stock {
+		return 0, stockAdjustOnly()
+	}
+
+	var version int
+	err = tx.QueryRowContext(ctx, `
+        UPDATE "products"
+        SET sku = CASE WHEN options = '[]' THEN $2 ELSE sku END,
+            updated_at = CURRENT_TIMESTAMP, version = version + 1
+        WHERE id_product = $1
+        RETURNING version
+    `, productID, param.SKU).Scan(&version)
+	if err != nil {
+		return 0, msg.InternalServerError(err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, msg.InternalServerError(err.Error())
+	}
+
+	return version, nil
+}
+
+func listVariants(ctx context.Context, db sqlx.QueryerContext, productID string) ([]entity.Variant, error) {
+	variants := []entity.Variant{}
+	err := sqlx.SelectContext(ctx, db, &variants, `
+        SELECT `+variantColumns+`
+        FROM "product_variants" v
+        JOIN "products" p ON p.id_product = v.id_product
+        WHERE v.id_product = $1
+        ORDER BY v.position, v.id_variant
+    `, productID)
+	if err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+
+	return variants, nil
+}
+
+func insertVariants(ctx context.Context, tx *sqlx.Tx, productID string, variants []entity.Variant) error {
+	for _, variant := range variants {
+		_, err := tx.ExecContext(ctx, `
+            INSERT INTO "product_variants" (id_product, options, sku, barcode, price, stock, is_available, position)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        `, productID, variant.Options, variant.SKU, variant.Barcode, variant.Price, variant.Stock, variant.IsAvailable, variant.Position)
+		if err != nil {
+			return variantWriteError(err)
+		}
+	}
+	return nil
+}
+
+// syncDefaultVariant keeps the default variant of a product without
+// options on the product's SKU.
+func syncDefaultVariant(ctx context.Context, tx *sqlx.Tx, productID string) error {
+	_, err := tx.ExecContext(ctx, `
+        UPDATE "product_variants" v
+        SET sku = p.sku, updated_at = CURRENT_TIMESTAMP
+        FROM "products" p
+        WHERE v.id_product = p.id_product AND p.id_product = $1 AND p.options = '[]' AND v.sku <> p.sku
+    `, productID)
+	if err != nil {
+		return msg.InternalServerError(err.Error())
+	}
+	return nil
+}
+
+// setDefaultVariantStock books the initial stock of the default variant of
+// a product without options on the selling location. Products with options
+// are skipped, their variants start without stock.
+func setDefaultVariantStock(ctx context.Context, tx *sqlx.Tx, productID string, stock int, userID uint32, reason string) error {
+	var variant entity.Variant
+	err := tx.GetContext(ctx, &variant, `
+        SELECT v.id_variant, v.id_product, v.stock
+        FROM "product_variants" v
+        JOIN "products" p ON p.id_product = v.id_product
+        WHERE v.id_product = $1 AND p.options = '[]'
+    `, productID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return msg.InternalServerError(err.Error())
+	}
+	if variant.Stock == stock {
+		return nil
+	}
+
+	_, err = inventoryRepository.RecordMovement(ctx, tx, inventoryEntity.StockMovement{
+		ProductID: variant.ProductID,
+		VariantID: variant.ID,
+		Type:      inventoryEntity.MovementAdjustment,
+		Quantity:  stock - variant.Stock,
+		Reason:    reason,
+		UserID:    inventoryEntity.StaffID(userID),
+	})
+	return err
+}
+
+// requireDefaultVariantStock rejects a stock differing from the one of the
+// default variant of a product without options.
+func requireDefaultVariantStock(ctx context.Context, tx *sqlx.Tx, productID string, stock int) error {
+	var current int
+	err := tx.GetContext(ctx, &current, `
+        SELECT v.stock
+        FROM "product_variants" v
+        JOIN "products" p ON p.id_product = v.id_product
+        WHERE v.id_product = $1 AND p.options = '[]'
+    `, productID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return msg.InternalServerError(err.Error())
+	}
+	if current != stock {
+		return stockAdjustOnly()
+	}
+	return nil
+}
+
+// stockAdjustOnly is the error for a stock change outside a stock
+// adjustment, which requires a manager and a reason code.
+func stockAdjustOnly() error {
+	return msg.Validation(msg.NewFieldError("stock", "readonly", msg.ValStockAdjustOnly, "stock", stockAdjustRoute))
+}
+
+// stockAdjustRoute is where stock is changed, named in stockAdjustOnly.
+const stockAdjustRoute = "POST /v1/product/:id/stock/adjust"
+
+// lockProductVersion locks a product for a write conditioned on
+// expectedVersion, zero meaning unconditional.
+func lockProductVersion(ctx context.Context, tx *sqlx.Tx, productID string, expectedVersion int) (entity.Product, error) {
+	var product entity.Product
+	err := tx.GetContext(ctx, &product, `
+        SELECT id_product AS id, sku, options, version
+        FROM "products"
+        WHERE id_product = $1
+        FOR UPDATE
+    `, productID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return entity.Product{}, msg.NotFound(msg.ErrProductNotFound)
+		}
+		return entity.Product{}, msg.InternalServerError(err.Error())
+	}
+	if expectedVersion != 0 && product.Version != expectedVersion {
+		return entity.Product{}, msg.New(msg.CodePreconditionFailed, msg.ErrProductVersionMismatch)
+	}
+
+	return product, nil
+}
+
+func variantWriteError(err error) error {
+	switch {
+	case strings.Contains(err.Error(), "product_variants_barcode"):
+		return msg.Conflict(msg.ErrBarcodeExists)
+	default:
+		return msg.InternalServerError(err.Error())
+	}
+}
@@ -0,0 +1,178 @@
+package svc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"projectsphere/eniqlo-store/internal/product/entity"
+	"projectsphere/eniqlo-store/internal/product/repository"
+	"projectsphere/eniqlo-store/pkg/imaging"
+	"projectsphere/eniqlo-store/pkg/middleware/logger"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"projectsphere/eniqlo-store/pkg/storage"
+
+	"github.com/google/uuid"
+)
+
+type ImageService struct {
+	imageRepo     repository.ImageRepo
+	storage       storage.Storage
+	maxSize       int64
+	thumbnailSize int
+}
+
+func NewImageService(imageRepo repository.ImageRepo, storage storage.Storage, maxSize int64, thumbnailSize int) ImageService {
+	return ImageService{
+		imageRepo:     imageRepo,
+		storage:       storage,
+		maxSize:       maxSize,
+		thumbnailSize: thumbnailSize,
+	}
+}
+
+func (s ImageService) List(ctx context.Context, productID string) ([]entity.ProductImage, error) {
+	return s.imageRepo.ListImages(ctx, productID)
+}
+
+// Upload validates the header of every file before storing any, then stores
+// originals and thumbnails one file at a time, so only one decoded image is
+// held in memory, and appends them to the product's images.
+func (s ImageService) Upload(ctx context.Context, productID string, uploads []entity.ImageUpload) ([]entity.ProductImage, error) {
+	infos := make([]imaging.Info, len(uploads))
+	for i, upload := range uploads {
+		info, err := s.inspect(upload)
+		if err != nil {
+			return nil, err
+		}
+		infos[i] = info
+	}
+
+	images := make([]entity.ProductImage, 0, len(uploads))
+	for i, upload := range uploads {
+		image, err := s.store(ctx, productID, upload, infos[i])
+		if err != nil {
+			s.deleteObjects(ctx, images...)
+			return nil, err
+		}
+		images = append(images, image)
+	}
+
+	created, err := s.imageRepo.AddImages(ctx, productID, images)
+	if err != nil {
+		s.deleteObjects(ctx, images...)
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (s ImageService) Reorder(ctx context.Context, productID string, imageIDs []int) error {
+	return s.imageRepo.ReorderImages(ctx, productID, imageIDs)
+}
+
+func (s ImageService) SetPrimary(ctx context.Context, productID string, imageID int) error {
+	return s.imageRepo.SetPrimaryImage(ctx, productID, imageID)
+}
+
+func (s ImageService) Delete(ctx context.Context, productID string, imageID int) error {
+	image, err := s.imageRepo.DeleteImage(ctx, productID, imageID)
+	if err != nil {
+		return err
+	}
+
+	s.deleteObjects(ctx, image)
+	return nil
+}
+
+func (s ImageService) inspect(upload entity.ImageUpload) (imaging.Info, error) {
+	if upload.Size > s.maxSize {
+		return imaging.Info{}, msg.New(msg.CodePayloadTooLarge, msg.ErrImageTooLarge)
+	}
+
+	file, err := upload.Open()
+	if err != nil {
+		return imaging.Info{}, msg.BadRequest(msg.ErrInvalidFormatFile)
+	}
+	defer file.Close()
+
+	info, err := imaging.Inspect(file)
+	switch {
+	case errors.Is(err, imaging.ErrUnsupportedFormat):
+		return imaging.Info{}, msg.New(msg.CodeUnsupportedMediaType, msg.ErrUnsupportedImgFormat)
+	case errors.Is(err, imaging.ErrTooManyPixels):
+		return imaging.Info{}, msg.New(msg.CodePayloadTooLarge, msg.ErrImageTooLarge)
+	case err != nil:
+		return imaging.Info{}, msg.BadRequest(msg.ErrInvalidFormatFile)
+	}
+
+	return info, nil
+}
+
+// read loads an upload that passed inspect.
+func (s ImageService) read(upload entity.ImageUpload) ([]byte, error) {
+	file, err := upload.Open()
+	if err != nil {
+		return nil, msg.BadRequest(msg.ErrInvalidFormatFile)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, s.maxSize+1))
+	if err != nil {
+		return nil, msg.BadRequest(msg.ErrInvalidFormatFile)
+	}
+	if int64(len(data)) > s.maxSize {
+		return nil, msg.New(msg.CodePayloadTooLarge, msg.ErrImageTooLarge)
+	}
+	return data, nil
+}
+
+func (s ImageService) store(ctx context.Context, productID string, upload entity.ImageUpload, info imaging.Info) (entity.ProductImage, error) {
+	data, err := s.read(upload)
+	if err != nil {
+		return entity.ProductImage{}, err
+	}
+
+	thumbnail, err := imaging.Thumbnail(bytes.NewReader(data), s.thumbnailSize)
+	if err != nil {
+		return entity.ProductImage{}, msg.BadRequest(msg.ErrInvalidFormatFile)
+	}
+
+	name := uuid.NewString()
+	image := entity.ProductImage{
+		StorageKey:   fmt.Sprintf("products/%s/%s%s", productID, name, info.Extension),
+		ThumbnailKey: fmt.Sprintf("products/%s/%s_thumb.jpg", productID, name),
+		ContentType:  info.ContentType,
+		Size:         int64(len(data)),
+		Width:        info.Width,
+		Height:       info.Height,
+	}
+	image.ImageURL = s.storage.URL(image.StorageKey)
+	image.ThumbnailURL = s.storage.URL(image.ThumbnailKey)
+
+	err = s.storage.Put(ctx, image.StorageKey, bytes.NewReader(data), image.Size, info.ContentType)
+	if err != nil {
+		return entity.ProductImage{}, msg.InternalServerError(err.Error())
+	}
+
+	err = s.storage.Put(ctx, image.ThumbnailKey, bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/jpeg")
+	if err != nil {
+		s.deleteObjects(ctx, image)
+		return entity.ProductImage{}, msg.InternalServerError(err.Error())
+	}
+
+	return image, nil
+}
+
+// deleteObjects removes stored files on a best effort basis; leftovers only
+// cost storage.
+func (s ImageService) deleteObjects(ctx context.Context, images ...entity.ProductImage) {
+	for _, image := range images {
+		for _, key := range []string{image.StorageKey, image.ThumbnailKey} {
+			if err := s.storage.Delete(ctx, key); err != nil {
+				logger.FromContext(ctx).Warn().Err(err).Str("key", key).Msg("cannot delete stored image")
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package svc
+
+import (
+	"context"
+	"projectsphere/eniqlo-store/internal/product/entity"
+	"projectsphere/eniqlo-store/internal/product/repository"
+	"projectsphere/eniqlo-store/pkg/middleware/logger"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"time"
+)
+
+type PriceService struct {
+	priceRepo repository.PriceRepo
+}
+
+func NewPriceService(priceRepo repository.PriceRepo) PriceService {
+	return PriceService{
+		priceRepo: priceRepo,
+	}
+}
+
+func (s PriceService) Prices(ctx context.Context, productID string, filter entity.PriceFilter) (entity.Prices, error) {
+	return s.priceRepo.Prices(ctx, productID, filter)
+}
+
+// Schedule schedules a price change, which must lie in the future.
+func (s PriceService) Schedule(ctx context.Context, productID string, param entity.ScheduleParam, userID uint32) (entity.ScheduledPrice, error) {
+	if !param.EffectiveAt.After(time.Now()) {
+		return entity.ScheduledPrice{}, msg.Validation(msg.NewFieldError("effectiveAt", "future", msg.ValNotFuture, "effectiveAt"))
+	}
+
+	schedule, err := s.priceRepo.SchedulePrice(ctx, productID, param, userID)
+	if err != nil {
+		return entity.ScheduledPrice{}, err
+	}
+
+	logger.FromContext(ctx).Info().
+		Str("productId", productID).
+		Int("scheduleId", schedule.ID).
+		Str("price", schedule.Price.String()).
+		Time("effectiveAt", param.EffectiveAt).
+		Msg("price change scheduled")
+
+	return schedule, nil
+}
+
+func (s PriceService) Cancel(ctx context.Context, productID string, scheduleID int, userID uint32) (entity.ScheduledPrice, error) {
+	schedule, err := s.priceRepo.CancelSchedule(ctx, productID, scheduleID, userID)
+	if err != nil {
+		return entity.ScheduledPrice{}, err
+	}
+
+	logger.FromContext(ctx).Info().
+		Str("productId", productID).
+		Int("scheduleId", schedule.ID).
+		Msg("price change cancelled")
+
+	return schedule, nil
+}
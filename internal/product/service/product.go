@@ -1,144 +1,246 @@
 package svc
 
 import (
+	"bytes"
 	"context"
-	"fmt"
-	"net/http"
+	"encoding/json"
+	"errors"
+	categoryService "projectsphere/eniqlo-store/internal/category/service"
 	"projectsphere/eniqlo-store/internal/product/entity"
 	"projectsphere/eniqlo-store/internal/product/repository"
+	"projectsphere/eniqlo-store/pkg/jsonpatch"
+	"projectsphere/eniqlo-store/pkg/middleware/logger"
 	"projectsphere/eniqlo-store/pkg/protocol/msg"
-	"strings"
+	"projectsphere/eniqlo-store/pkg/validator"
+	"reflect"
 )
 
 type ProductService struct {
 	productRepo repository.ProductRepo
+	variantRepo repository.VariantRepo
+	categorySvc categoryService.CategoryService
+	imageSvc    ImageService
 }
 
-func NewProductService(productRepo repository.ProductRepo) ProductService {
+func NewProductService(productRepo repository.ProductRepo, variantRepo repository.VariantRepo, categorySvc categoryService.CategoryService, imageSvc ImageService) ProductService {
 	return ProductService{
 		productRepo: productRepo,
+		variantRepo: variantRepo,
+		categorySvc: categorySvc,
+		imageSvc:    imageSvc,
 	}
 }
 
-func (s ProductService) Update(ctx context.Context, product entity.Product) error {
-	if err := s.validateProduct(product); err != nil {
-		return &msg.RespError{
-			Code:    http.StatusBadRequest,
-			Message: "request doesn't pass validation",
-		}
+// Get returns a product with its variants.
+func (s ProductService) Get(ctx context.Context, productID string) (entity.Product, error) {
+	product, err := s.productRepo.GetProduct(ctx, productID)
+	if err != nil {
+		return entity.Product{}, err
 	}
 
-	err := s.productRepo.UpdateProduct(product)
+	product.Variants, err = s.variantRepo.ListVariants(ctx, productID)
 	if err != nil {
-		return err
+		return entity.Product{}, err
 	}
 
-	return nil
+	return product, nil
+}
+
+// Search lists products, expanding the category filter to its
+// subcategories. An unknown category matches nothing.
+func (s ProductService) Search(ctx context.Context, filter entity.ProductFilter) ([]entity.Product, error) {
+	if filter.Category != "" {
+		tree, err := s.categorySvc.Tree(ctx)
+		if err != nil {
+			return nil, err
+		}
+		filter.Categories = tree.Keys(filter.Category)
+		if len(filter.Categories) == 0 {
+			return []entity.Product{}, nil
+		}
+	}
+
+	return s.productRepo.SearchProducts(ctx, filter)
 }
 
-func (s ProductService) Delete(ctx context.Context, productID string, userID uint32) error {
-	err := s.productRepo.DeleteProduct(productID, userID)
+// validateAttributes checks product.Attributes against its category.
+func (s ProductService) validateAttributes(ctx context.Context, product entity.Product) error {
+	tree, err := s.categorySvc.Tree(ctx)
 	if err != nil {
 		return err
 	}
 
+	if details := tree.ValidateAttributes(product.Category, product.Attributes); len(details) > 0 {
+		return msg.Validation(details...)
+	}
 	return nil
 }
 
-func (s ProductService) Create(ctx context.Context, productParam entity.Product, userId uint32) (entity.ProductResponse, error) {
-	if err := s.validateProduct(productParam); err != nil {
-		return entity.ProductResponse{}, &msg.RespError{
-			Code:    http.StatusBadRequest,
-			Message: "request doesn't pass validation",
-		}
+// Update writes product if it is still at expectedVersion (zero skips the
+// check) and returns its new version. userID is recorded on price changes.
+func (s ProductService) Update(ctx context.Context, product entity.Product, expectedVersion int, userID uint32) (int, error) {
+	if err := s.validateAttributes(ctx, product); err != nil {
+		return 0, err
 	}
-	product, err := s.productRepo.CreateProduct(ctx, productParam, userId)
+
+	version, err := s.productRepo.UpdateProduct(ctx, product, expectedVersion, userID)
 	if err != nil {
-		return entity.ProductResponse{}, err
+		return 0, err
 	}
 
-	return entity.ProductResponse{
-		Message: "success",
-		Data: entity.Product{
-			ID:        product.ID,
-			CreatedAt: product.CreatedAt,
-		},
-	}, nil
+	return version, nil
 }
 
-func (s ProductService) validateProduct(product entity.Product) error {
-	validationErrors := make(map[string]string)
-
-	if product.Name == "" {
-		validationErrors["name"] = "name cannot be empty"
+// maxPatchAttempts bounds the retries of an unconditional patch that lost a
+// race with another write.
+const maxPatchAttempts = 3
+
+// Patch applies a merge patch or JSON Patch document to the stored product,
+// validates the result and writes only the fields that changed. Without
+// expectedVersion the patch is re-applied if the product changed meanwhile.
+func (s ProductService) Patch(ctx context.Context, productID, patchType string, patch []byte, expectedVersion int, userID uint32) (entity.ProductPatchResult, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := s.patch(ctx, productID, patchType, patch, expectedVersion, userID)
+		if expectedVersion == 0 && attempt < maxPatchAttempts && msg.UnwrapRespError(err).ErrorCode == msg.CodeOf(msg.ErrProductVersionMismatch) {
+			continue
+		}
+		return result, err
 	}
-	if product.SKU == "" {
-		validationErrors["sku"] = "sku cannot be empty"
+}
+
+func (s ProductService) patch(ctx context.Context, productID, patchType string, patch []byte, expectedVersion int, userID uint32) (entity.ProductPatchResult, error) {
+	current, err := s.productRepo.GetProduct(ctx, productID)
+	if err != nil {
+		return entity.ProductPatchResult{}, err
 	}
-	if product.Category == "" {
-		validationErrors["category"] = "category cannot be empty"
+	if expectedVersion != 0 && current.Version != expectedVersion {
+		return entity.ProductPatchResult{}, msg.New(msg.CodePreconditionFailed, msg.ErrProductVersionMismatch)
 	}
-	if product.ImageURL == "" {
-		validationErrors["imageUrl"] = "imageUrl cannot be empty"
+
+	patched, err := applyPatch(current, patchType, patch)
+	if err != nil {
+		return entity.ProductPatchResult{}, err
 	}
-	if product.Notes == "" {
-		validationErrors["notes"] = "notes cannot be empty"
+	if err := validator.Struct(patched); err != nil {
+		return entity.ProductPatchResult{}, err
 	}
-	if product.Price == 0 {
-		validationErrors["price"] = "price cannot be zero"
+	if err := s.validateAttributes(ctx, patched); err != nil {
+		return entity.ProductPatchResult{}, err
 	}
-	if product.Stock == 0 {
-		validationErrors["stock"] = "stock cannot be zero"
+
+	result := entity.ProductPatchResult{
+		ID:            productID,
+		Version:       current.Version,
+		ChangedFields: []string{},
 	}
-	if product.Location == "" {
-		validationErrors["location"] = "location cannot be empty"
+
+	changes := current.Changes(patched)
+	if len(changes) == 0 {
+		return result, nil
 	}
 
-	if len(product.Name) < 1 || len(product.Name) > 30 {
-		validationErrors["name"] = "name must be between 1 and 30 characters"
+	result.Version, err = s.productRepo.PatchProduct(ctx, productID, current.Version, userID, changes)
+	if err != nil {
+		return entity.ProductPatchResult{}, err
 	}
+	for _, change := range changes {
+		result.ChangedFields = append(result.ChangedFields, change.Field)
+	}
+
+	logger.FromContext(ctx).Info().
+		Str("productId", productID).
+		Int("version", result.Version).
+		Strs("changedFields", result.ChangedFields).
+		Msg("product patched")
+
+	return result, nil
+}
 
-	if len(product.SKU) < 1 || len(product.SKU) > 30 {
-		validationErrors["sku"] = "sku must be between 1 and 30 characters"
+// applyPatch runs patch against the JSON form of product. Read only fields
+// must come out unchanged and unknown fields are rejected.
+func applyPatch(product entity.Product, patchType string, patch []byte) (entity.Product, error) {
+	doc, err := json.Marshal(product)
+	if err != nil {
+		return entity.Product{}, msg.InternalServerError(err.Error())
 	}
 
-	validCategories := map[string]bool{
-		"Clothing":    true,
-		"Accessories": true,
-		"Footwear":    true,
-		"Beverages":   true,
+	var patchedDoc []byte
+	switch patchType {
+	case entity.MergePatchType:
+		patchedDoc, err = jsonpatch.MergePatch(doc, patch)
+	case entity.JSONPatchType:
+		patchedDoc, err = jsonpatch.Apply(doc, patch)
+	default:
+		return entity.Product{}, msg.New(msg.CodeUnsupportedMediaType, msg.ErrUnsupportedPatchType)
+	}
+	if errors.Is(err, jsonpatch.ErrTestFailed) {
+		return entity.Product{}, msg.Wrap(msg.CodeConflict, msg.ErrPatchTestFailed, err)
 	}
-	if !validCategories[product.Category] {
-		validationErrors["category"] = "invalid category"
+	if err != nil {
+		return entity.Product{}, msg.Wrap(msg.CodeBadRequest, msg.ErrInvalidPatch, err)
 	}
 
-	if !strings.HasPrefix(product.ImageURL, "http://") && !strings.HasPrefix(product.ImageURL, "https://") {
-		validationErrors["imageUrl"] = "invalid URL"
+	var before, after map[string]interface{}
+	if err := json.Unmarshal(doc, &before); err != nil {
+		return entity.Product{}, msg.InternalServerError(err.Error())
+	}
+	if err := json.Unmarshal(patchedDoc, &after); err != nil {
+		return entity.Product{}, msg.BadRequest(msg.ErrInvalidPatch)
 	}
 
-	if len(product.Notes) < 1 || len(product.Notes) > 200 {
-		validationErrors["notes"] = "notes must be between 1 and 200 characters"
+	var details []msg.FieldError
+	for _, field := range entity.ReadOnlyFields {
+		if !reflect.DeepEqual(before[field], after[field]) {
+			details = append(details, msg.NewFieldError(field, "readonly", msg.ValReadOnly, field))
+		}
+	}
+	if len(details) > 0 {
+		return entity.Product{}, msg.Validation(details...)
 	}
 
-	if product.Price < 1 {
-		validationErrors["price"] = "price must be greater than 0"
+	var patched entity.Product
+	decoder := json.NewDecoder(bytes.NewReader(patchedDoc))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patched); err != nil {
+		return entity.Product{}, msg.BadRequest(err.Error())
 	}
 
-	if product.Stock < 0 || product.Stock > 100000 {
-		validationErrors["stock"] = "stock must be between 0 and 100000"
+	return patched, nil
+}
+
+// Delete removes the product and the stored files of its images.
+func (s ProductService) Delete(ctx context.Context, productID string, userID uint32, expectedVersion int) error {
+	images, err := s.productRepo.DeleteProduct(ctx, productID, userID, expectedVersion)
+	if err != nil {
+		return err
 	}
 
-	if len(product.Location) < 1 || len(product.Location) > 200 {
-		validationErrors["location"] = "location must be between 1 and 200 characters"
+	s.imageSvc.deleteObjects(ctx, images...)
+	return nil
+}
+
+// Create stores a product with a variant for every combination of its
+// options, or a single default variant carrying its SKU and stock.
+func (s ProductService) Create(ctx context.Context, productParam entity.Product, userId uint32) (entity.ProductResponse, error) {
+	if err := s.validateAttributes(ctx, productParam); err != nil {
+		return entity.ProductResponse{}, err
+	}
+	if err := validateOptions(productParam.Options); err != nil {
+		return entity.ProductResponse{}, err
 	}
+	productParam.Variants = entity.PlanVariants(productParam.SKU, productParam.Options, nil).Create
 
-	if len(validationErrors) > 0 {
-		var errorMsgs []string
-		for field, msg := range validationErrors {
-			errorMsgs = append(errorMsgs, fmt.Sprintf("%s: %s", field, msg))
-		}
-		return fmt.Errorf(strings.Join(errorMsgs, "; "))
+	product, err := s.productRepo.CreateProduct(ctx, productParam, userId)
+	if err != nil {
+		return entity.ProductResponse{}, err
 	}
 
-	return nil
+	return entity.ProductResponse{
+		Message: msg.SuccessResponse,
+		Data: entity.Product{
+			ID:        product.ID,
+			Version:   product.Version,
+			CreatedAt: product.CreatedAt,
+		},
+	}, nil
 }
@@ -0,0 +1,101 @@
+package svc
+
+import (
+	"context"
+	"projectsphere/eniqlo-store/internal/product/repository"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type SchedulerConfig struct {
+	// Interval between polls for due price schedules.
+	Interval time.Duration
+	// BatchSize bounds the products repriced per poll.
+	BatchSize int
+}
+
+// PriceScheduler periodically applies the price schedules that are due.
+// Checkout applies the due schedules of the products it sells itself, so a
+// sale never waits for the next poll to get the new price.
+type PriceScheduler struct {
+	priceRepo repository.PriceRepo
+	config    SchedulerConfig
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+func NewPriceScheduler(priceRepo repository.PriceRepo, config SchedulerConfig) *PriceScheduler {
+	return &PriceScheduler{
+		priceRepo: priceRepo,
+		config:    config,
+		done:      make(chan struct{}),
+	}
+}
+
+// Start runs the scheduler in the background until Stop or until ctx is
+// cancelled.
+func (s *PriceScheduler) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	go s.run(ctx)
+	return nil
+}
+
+// Stop cancels the running poll and waits for it until ctx expires.
+func (s *PriceScheduler) Stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.once.Do(s.cancel)
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *PriceScheduler) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *PriceScheduler) poll(ctx context.Context) {
+	productIDs, err := s.priceRepo.DueProducts(ctx, s.config.BatchSize)
+	if err != nil {
+		log.Error().Err(err).Msg("cannot list products with due price changes")
+		return
+	}
+
+	for _, productID := range productIDs {
+		if ctx.Err() != nil {
+			return
+		}
+
+		applied, err := s.priceRepo.ApplyDue(ctx, productID)
+		if err != nil {
+			log.Error().Err(err).Int("productId", productID).Msg("cannot apply scheduled price changes")
+			continue
+		}
+		if applied > 0 {
+			log.Info().Int("productId", productID).Int("applied", applied).Msg("scheduled price changes applied")
+		}
+	}
+}
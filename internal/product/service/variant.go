@@ -0,0 +1,84 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"projectsphere/eniqlo-store/internal/product/entity"
+	"projectsphere/eniqlo-store/internal/product/repository"
+	"projectsphere/eniqlo-store/pkg/middleware/logger"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+)
+
+type VariantService struct {
+	variantRepo repository.VariantRepo
+}
+
+func NewVariantService(variantRepo repository.VariantRepo) VariantService {
+	return VariantService{
+		variantRepo: variantRepo,
+	}
+}
+
+func (s VariantService) List(ctx context.Context, productID string) ([]entity.Variant, error) {
+	return s.variantRepo.ListVariants(ctx, productID)
+}
+
+// SetOptions replaces the product's options and regenerates its variants.
+// It returns the new product version.
+func (s VariantService) SetOptions(ctx context.Context, productID string, options entity.Options, expectedVersion int) (int, error) {
+	if err := validateOptions(options); err != nil {
+		return 0, err
+	}
+
+	version, err := s.variantRepo.SetOptions(ctx, productID, options, expectedVersion)
+	if err != nil {
+		return 0, err
+	}
+
+	logger.FromContext(ctx).Info().
+		Str("productId", productID).
+		Int("version", version).
+		Int("variants", len(options.Combinations())).
+		Msg("product options changed")
+
+	return version, nil
+}
+
+// Update writes a variant and returns the new product version. userID is
+// recorded on a price change.
+func (s VariantService) Update(ctx context.Context, productID string, variantID int, param entity.VariantParam, expectedVersion int, userID uint32) (int, error) {
+	return s.variantRepo.UpdateVariant(ctx, productID, variantID, param, expectedVersion, userID)
+}
+
+// validateOptions rejects duplicate option names and values, and options
+// generating more than entity.MaxVariants combinations.
+func validateOptions(options entity.Options) error {
+	var details []msg.FieldError
+	names := make(map[string]bool, len(options))
+	combinations := 1
+	for i, option := range options {
+		field := fmt.Sprintf("options[%d].name", i)
+		if names[option.Name] {
+			details = append(details, msg.NewFieldError(field, "unique", msg.ValDuplicate, field))
+		}
+		names[option.Name] = true
+
+		values := make(map[string]bool, len(option.Values))
+		for j, value := range option.Values {
+			if values[value] {
+				field := fmt.Sprintf("options[%d].values[%d]", i, j)
+				details = append(details, msg.NewFieldError(field, "unique", msg.ValDuplicate, field))
+			}
+			values[value] = true
+		}
+		combinations *= len(option.Values)
+	}
+	if len(details) > 0 {
+		return msg.Validation(details...)
+	}
+
+	if combinations > entity.MaxVariants {
+		return msg.BadRequest(msg.ErrTooManyVariants)
+	}
+	return nil
+}
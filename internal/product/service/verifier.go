@@ -0,0 +1,259 @@
+package svc
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"projectsphere/eniqlo-store/internal/product/entity"
+	"projectsphere/eniqlo-store/internal/product/repository"
+	"projectsphere/eniqlo-store/pkg/imaging"
+	"projectsphere/eniqlo-store/pkg/storage"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type VerifierConfig struct {
+	// Interval between polls for products to verify.
+	Interval time.Duration
+	// Recheck is how long a result is trusted before verifying again.
+	Recheck time.Duration
+	// BatchSize bounds the products verified per poll.
+	BatchSize int
+	// MaxSize bounds the image download.
+	MaxSize int64
+}
+
+// ImageVerifier periodically downloads product image URLs through an SSRF
+// safe client, checks they are images, keeps a cached copy in storage and
+// flags products whose image is broken.
+type ImageVerifier struct {
+	productRepo repository.ProductRepo
+	storage     storage.Storage
+	client      *http.Client
+	config      VerifierConfig
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+func NewImageVerifier(productRepo repository.ProductRepo, storage storage.Storage, client *http.Client, config VerifierConfig) *ImageVerifier {
+	return &ImageVerifier{
+		productRepo: productRepo,
+		storage:     storage,
+		client:      client,
+		config:      config,
+		done:        make(chan struct{}),
+	}
+}
+
+// errNotModified reports that the cached copy is still current.
+var errNotModified = errors.New("image not modified")
+
+// Start runs the verifier in the background until Stop or until ctx is
+// cancelled.
+func (v *ImageVerifier) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	v.cancel = cancel
+	go v.run(ctx)
+	return nil
+}
+
+// Stop cancels the running poll and waits for it until ctx expires.
+func (v *ImageVerifier) Stop(ctx context.Context) error {
+	if v.cancel == nil {
+		return nil
+	}
+	v.once.Do(v.cancel)
+
+	select {
+	case <-v.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (v *ImageVerifier) run(ctx context.Context) {
+	defer close(v.done)
+
+	ticker := time.NewTicker(v.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		v.poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (v *ImageVerifier) poll(ctx context.Context) {
+	checks, err := v.productRepo.ImagesToVerify(ctx, time.Now().Add(-v.config.Recheck), v.config.BatchSize)
+	if err != nil {
+		log.Error().Err(err).Msg("cannot list product images to verify")
+		return
+	}
+
+	for _, check := range checks {
+		if ctx.Err() != nil {
+			return
+		}
+
+		result := v.Verify(ctx, check)
+		if ctx.Err() != nil {
+			return
+		}
+		if result.Status == entity.ImageStatusBroken && check.Status != entity.ImageStatusBroken {
+			log.Warn().Str("productId", check.ProductID).Str("imageUrl", check.ImageURL).Str("reason", result.Error).Msg("product image is broken")
+		}
+
+		saved, err := v.productRepo.SaveImageCheck(ctx, result)
+		if err != nil {
+			log.Error().Err(err).Str("productId", check.ProductID).Msg("cannot save image check")
+			continue
+		}
+		v.removeUnused(ctx, check, result, saved)
+	}
+}
+
+// Verify checks one image URL. Images already in our storage are trusted.
+// The cached copy is revalidated with its ETag and only stored again when
+// the content changed; a broken image keeps the last good copy.
+func (v *ImageVerifier) Verify(ctx context.Context, check entity.ImageCheck) entity.ImageCheck {
+	result := entity.ImageCheck{
+		ProductID:   check.ProductID,
+		ImageURL:    check.ImageURL,
+		Status:      entity.ImageStatusOK,
+		CachedImage: check.CachedImage,
+		ETag:        check.ETag,
+	}
+	if strings.HasPrefix(check.ImageURL, v.storage.URL("")) {
+		return result
+	}
+
+	etag := ""
+	if check.CachedImage != "" {
+		etag = check.ETag
+	}
+	data, contentType, newETag, err := v.fetch(ctx, check.ImageURL, etag)
+	if errors.Is(err, errNotModified) {
+		return result
+	}
+	if err != nil {
+		result.Status = entity.ImageStatusBroken
+		result.Error = err.Error()
+		return result
+	}
+
+	key := fmt.Sprintf("cache/products/%s/%x%s", check.ProductID, sha256.Sum256(data), extension(contentType))
+	if v.storage.URL(key) == check.CachedImage {
+		result.ETag = newETag
+		return result
+	}
+	if err := v.storage.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		// the image itself is fine, only caching failed
+		log.Error().Err(err).Str("productId", check.ProductID).Msg("cannot cache product image")
+		return result
+	}
+	result.CachedImage = v.storage.URL(key)
+	result.ETag = newETag
+
+	return result
+}
+
+// removeUnused deletes the cached copies nothing refers to once result was
+// saved, or was not saved because the image URL changed meanwhile.
+func (v *ImageVerifier) removeUnused(ctx context.Context, check, result entity.ImageCheck, saved bool) {
+	if !saved {
+		if result.CachedImage != check.CachedImage {
+			v.deleteCached(ctx, check.ProductID, result.CachedImage)
+		}
+		return
+	}
+
+	if check.CachedImage != "" && check.CachedImage != result.CachedImage {
+		v.deleteCached(ctx, check.ProductID, check.CachedImage)
+	}
+	if check.StaleCachedImage != "" {
+		v.deleteCached(ctx, check.ProductID, check.StaleCachedImage)
+	}
+}
+
+func (v *ImageVerifier) deleteCached(ctx context.Context, productID, url string) {
+	key := strings.TrimPrefix(url, v.storage.URL(""))
+	if key == url {
+		return
+	}
+	if err := v.storage.Delete(ctx, key); err != nil {
+		log.Error().Err(err).Str("productId", productID).Str("url", url).Msg("cannot delete cached product image")
+	}
+}
+
+// fetch downloads url and returns its body and ETag if both the declared and
+// the sniffed content types are images. With etag it returns errNotModified
+// if the image did not change.
+func (v *ImageVerifier) fetch(ctx context.Context, url, etag string) ([]byte, string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", "", err
+	}
+	req.Header.Set("Accept", "image/*")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, "", "", err
+	}
+	defer resp.Body.Close()
+
+	if etag != "" && resp.StatusCode == http.StatusNotModified {
+		return nil, "", "", errNotModified
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	declared, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !strings.HasPrefix(declared, "image/") {
+		return nil, "", "", fmt.Errorf("content type %q is not an image", declared)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, v.config.MaxSize+1))
+	if err != nil {
+		return nil, "", "", err
+	}
+	if int64(len(data)) > v.config.MaxSize {
+		return nil, "", "", errors.New("image is larger than allowed")
+	}
+
+	sniffed := http.DetectContentType(data)
+	if !strings.HasPrefix(sniffed, "image/") {
+		return nil, "", "", fmt.Errorf("content is %q, not an image", sniffed)
+	}
+
+	return data, sniffed, resp.Header.Get("ETag"), nil
+}
+
+func extension(contentType string) string {
+	if extension, ok := imaging.ContentTypes[contentType]; ok {
+		return extension
+	}
+	if extensions, _ := mime.ExtensionsByType(contentType); len(extensions) > 0 {
+		return extensions[0]
+	}
+	return ""
+}
@@ -0,0 +1,226 @@
+package svc
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"projectsphere/eniqlo-store/internal/product/entity"
+	"projectsphere/eniqlo-store/pkg/safehttp"
+	"projectsphere/eniqlo-store/pkg/storage"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryStorage is an in memory storage.Storage counting its writes.
+type memoryStorage struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	puts    int
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{objects: make(map[string][]byte)}
+}
+
+func (m *memoryStorage) Put(_ context.Context, key string, body io.Reader, _ int64, _ string) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = data
+	m.puts++
+	return nil
+}
+
+func (m *memoryStorage) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memoryStorage) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+	return nil
+}
+
+func (m *memoryStorage) URL(key string) string {
+	return "https://cdn.example.com/" + key
+}
+
+func (m *memoryStorage) has(url string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.objects[strings.TrimPrefix(url, m.URL(""))]
+	return ok
+}
+
+func pngImage(t *testing.T, size int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, size, size))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// fixtureServer serves the image fixtures the verifier is tested against.
+func fixtureServer(t *testing.T, picture []byte) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/image.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(picture)
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/image.png", http.StatusFound)
+	})
+	mux.HandleFunc("/page.html", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html></html>"))
+	})
+	mux.HandleFunc("/disguised.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("<html></html>"))
+	})
+	mux.HandleFunc("/large.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(pngImage(t, 512))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestVerifier(store storage.Storage, allowPrivate bool) *ImageVerifier {
+	return &ImageVerifier{
+		storage: store,
+		client:  safehttp.NewClient(2*time.Second, allowPrivate),
+		config:  VerifierConfig{MaxSize: 4 << 10},
+	}
+}
+
+func TestVerify(t *testing.T) {
+	server := fixtureServer(t, pngImage(t, 2))
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus string
+		wantError  string
+	}{
+		{name: "image", path: "/image.png", wantStatus: entity.ImageStatusOK},
+		{name: "redirect to image", path: "/redirect", wantStatus: entity.ImageStatusOK},
+		{name: "not found", path: "/missing.png", wantStatus: entity.ImageStatusBroken, wantError: "404"},
+		{name: "declared content type is not an image", path: "/page.html", wantStatus: entity.ImageStatusBroken, wantError: "is not an image"},
+		{name: "sniffed content is not an image", path: "/disguised.png", wantStatus: entity.ImageStatusBroken, wantError: "not an image"},
+		{name: "larger than allowed", path: "/large.png", wantStatus: entity.ImageStatusBroken, wantError: "larger than allowed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryStorage()
+			v := newTestVerifier(store, true)
+
+			result := v.Verify(context.Background(), entity.ImageCheck{ProductID: "1", ImageURL: server.URL + tt.path})
+			if result.Status != tt.wantStatus {
+				t.Fatalf("Status = %q (%s), want %q", result.Status, result.Error, tt.wantStatus)
+			}
+			if !strings.Contains(result.Error, tt.wantError) {
+				t.Errorf("Error = %q, want it to contain %q", result.Error, tt.wantError)
+			}
+
+			cached := result.CachedImage != ""
+			if wantCached := tt.wantStatus == entity.ImageStatusOK; cached != wantCached || cached && !store.has(result.CachedImage) {
+				t.Errorf("CachedImage = %q, stored %v, want a cached copy: %v", result.CachedImage, store.has(result.CachedImage), wantCached)
+			}
+		})
+	}
+}
+
+func TestVerifyBlocksPrivateAddresses(t *testing.T) {
+	server := fixtureServer(t, pngImage(t, 2))
+	store := newMemoryStorage()
+	v := newTestVerifier(store, false)
+
+	result := v.Verify(context.Background(), entity.ImageCheck{ProductID: "1", ImageURL: server.URL + "/image.png"})
+	if result.Status != entity.ImageStatusBroken || !strings.Contains(result.Error, "not publicly routable") {
+		t.Errorf("Verify() = %q (%s), want it blocked", result.Status, result.Error)
+	}
+	if store.puts != 0 {
+		t.Errorf("stored %d objects from a private address", store.puts)
+	}
+}
+
+func TestVerifyReusesCachedCopy(t *testing.T) {
+	server := fixtureServer(t, pngImage(t, 2))
+	store := newMemoryStorage()
+	v := newTestVerifier(store, true)
+	check := entity.ImageCheck{ProductID: "1", ImageURL: server.URL + "/image.png"}
+
+	first := v.Verify(context.Background(), check)
+	if first.CachedImage == "" || first.ETag != `"v1"` {
+		t.Fatalf("first Verify() = %+v, want a cached copy and its ETag", first)
+	}
+
+	// revalidated with If-None-Match
+	second := v.Verify(context.Background(), first)
+	if second.Status != entity.ImageStatusOK || second.CachedImage != first.CachedImage {
+		t.Errorf("second Verify() = %+v, want the first cached copy", second)
+	}
+
+	// without an ETag the content hash matches the cached copy
+	first.ETag = ""
+	third := v.Verify(context.Background(), first)
+	if third.CachedImage != first.CachedImage {
+		t.Errorf("third Verify() = %+v, want the first cached copy", third)
+	}
+
+	if store.puts != 1 {
+		t.Errorf("stored %d times, want 1", store.puts)
+	}
+}
+
+func TestRemoveUnusedCachedCopies(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStorage()
+	v := newTestVerifier(store, true)
+	for _, key := range []string{"cache/products/1/old.png", "cache/products/1/new.png", "cache/products/1/stale.png"} {
+		store.Put(ctx, key, strings.NewReader("x"), 1, "image/png")
+	}
+	old, replacement, stale := store.URL("cache/products/1/old.png"), store.URL("cache/products/1/new.png"), store.URL("cache/products/1/stale.png")
+
+	check := entity.ImageCheck{ProductID: "1", CachedImage: old, StaleCachedImage: stale}
+	result := entity.ImageCheck{ProductID: "1", CachedImage: replacement}
+
+	// the image URL changed before the result was saved
+	v.removeUnused(ctx, check, result, false)
+	if store.has(replacement) || !store.has(old) || !store.has(stale) {
+		t.Fatalf("unsaved result: old %v, new %v, stale %v, want only the new copy deleted", store.has(old), store.has(replacement), store.has(stale))
+	}
+
+	store.Put(ctx, "cache/products/1/new.png", strings.NewReader("x"), 1, "image/png")
+	v.removeUnused(ctx, check, result, true)
+	if store.has(old) || store.has(stale) || !store.has(replacement) {
+		t.Errorf("saved result: old %v, new %v, stale %v, want only the new copy kept", store.has(old), store.has(replacement), store.has(stale))
+	}
+}
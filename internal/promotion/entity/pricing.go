@@ -0,0 +1,303 @@
+package entity
+
+import (
+	"projectsphere/eniqlo-store/pkg/money"
+	"sort"
+	"time"
+)
+
+// Line is one line of a cart: Quantity units of a product at UnitPrice.
+type Line struct {
+	ProductID int
+	Category  string
+	Quantity  int
+	UnitPrice money.Money
+}
+
+// Cart is what gets priced. Codes are the promotion codes given, At is the
+// time the promotions have to run at.
+type Cart struct {
+	Lines []Line
+	Codes []string
+	At    time.Time
+}
+
+// Discount explains a promotion applied to a line: Quantity units of the
+// line were discounted by Amount in total.
+type Discount struct {
+	PromotionID int         `json:"promotionId"`
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Quantity    int         `json:"quantity"`
+	Amount      money.Money `json:"amount"`
+}
+
+// PricedLine is a line at list price, Subtotal, less the Discounts applied
+// to it. All of them are whole major units, rupiah are charged without sen.
+type PricedLine struct {
+	Subtotal  money.Money
+	Discount  money.Money
+	Total     money.Money
+	Discounts []Discount
+}
+
+// Pricing holds the priced lines of a cart in cart order and their totals.
+type Pricing struct {
+	Lines    []PricedLine
+	Subtotal money.Money
+	Discount money.Money
+	Total    money.Money
+}
+
+// share is the part of a promotion's discount that falls on one line.
+type share struct {
+	line     int
+	quantity int
+	amount   money.Money
+}
+
+// Price applies promotions to cart. Promotions that do not run at cart.At,
+// or have a code that was not given, are left out. The rest apply by
+// descending priority, then by id, each to what earlier promotions left of
+// a line, so no line goes below zero. A promotion that is not stackable
+// only discounts lines nothing discounted yet, and the lines it discounts
+// take no further promotions. Subtotals round half up to whole major units
+// and every discount is whole major units too.
+func Price(cart Cart, promotions []Promotion) Pricing {
+	pricing := Pricing{Lines: make([]PricedLine, len(cart.Lines))}
+	remaining := make([]money.Money, len(cart.Lines))
+	closed := make([]bool, len(cart.Lines))
+	for i, line := range cart.Lines {
+		subtotal := line.UnitPrice.Mul(int64(line.Quantity)).RoundMajor(money.HalfUp)
+		pricing.Lines[i] = PricedLine{Subtotal: subtotal, Discounts: []Discount{}}
+		remaining[i] = subtotal
+	}
+
+	for _, promotion := range applicable(cart, promotions) {
+		var eligible []int
+		for i, line := range cart.Lines {
+			if closed[i] || remaining[i].IsZero() || !promotion.Covers(line) {
+				continue
+			}
+			if !promotion.Stackable && len(pricing.Lines[i].Discounts) > 0 {
+				continue
+			}
+			eligible = append(eligible, i)
+		}
+		if len(eligible) == 0 {
+			continue
+		}
+
+		for _, part := range promotion.shares(cart.Lines, remaining, eligible) {
+			if part.amount.IsZero() || part.amount.IsNegative() {
+				continue
+			}
+			if part.amount.Cmp(remaining[part.line]) > 0 {
+				part.amount = remaining[part.line]
+			}
+			remaining[part.line] = remaining[part.line].Sub(part.amount)
+			if !promotion.Stackable {
+				closed[part.line] = true
+			}
+
+			priced := &pricing.Lines[part.line]
+			priced.Discounts = append(priced.Discounts, Discount{
+				PromotionID: promotion.ID,
+				Name:        promotion.Name,
+				Type:        promotion.Type,
+				Quantity:    part.quantity,
+				Amount:      part.amount,
+			})
+		}
+	}
+
+	for i := range pricing.Lines {
+		priced := &pricing.Lines[i]
+		priced.Total = remaining[i]
+		priced.Discount = priced.Subtotal.Sub(priced.Total)
+		pricing.Subtotal = pricing.Subtotal.Add(priced.Subtotal)
+		pricing.Discount = pricing.Discount.Add(priced.Discount)
+		pricing.Total = pricing.Total.Add(priced.Total)
+	}
+	return pricing
+}
+
+// applicable returns the promotions running at cart.At whose code, if any,
+// was given, in the order they apply.
+func applicable(cart Cart, promotions []Promotion) []Promotion {
+	codes := make(map[string]bool, len(cart.Codes))
+	for _, code := range cart.Codes {
+		codes[NormalizeCode(code)] = true
+	}
+
+	var running []Promotion
+	for _, promotion := range promotions {
+		if !promotion.RunsAt(cart.At) {
+			continue
+		}
+		if promotion.Code != nil && !codes[NormalizeCode(*promotion.Code)] {
+			continue
+		}
+		running = append(running, promotion)
+	}
+
+	sort.SliceStable(running, func(i, j int) bool {
+		if running[i].Priority != running[j].Priority {
+			return running[i].Priority > running[j].Priority
+		}
+		return running[i].ID < running[j].ID
+	})
+	return running
+}
+
+// shares works out the discount of the promotion on the eligible lines,
+// given what is left of every line.
+func (p Promotion) shares(lines []Line, remaining []money.Money, eligible []int) []share {
+	switch p.Type {
+	case TypePercentage:
+		if p.Percent == nil {
+			return nil
+		}
+		shares := make([]share, 0, len(eligible))
+		for _, i := range eligible {
+			shares = append(shares, share{line: i, quantity: lines[i].Quantity, amount: remaining[i].MulRatMajor(int64(*p.Percent), 100, money.HalfUp)})
+		}
+		return shares
+
+	case TypeFixed:
+		if p.Amount == nil {
+			return nil
+		}
+		return spread(lines, remaining, eligible, *p.Amount)
+
+	case TypeMinSpend:
+		if p.MinSpend == nil {
+			return nil
+		}
+		base := sumOf(remaining, eligible)
+		if base.Cmp(*p.MinSpend) < 0 {
+			return nil
+		}
+		switch {
+		case p.Percent != nil:
+			return spread(lines, remaining, eligible, base.MulRatMajor(int64(*p.Percent), 100, money.HalfUp))
+		case p.Amount != nil:
+			return spread(lines, remaining, eligible, *p.Amount)
+		}
+		return nil
+
+	case TypeBOGO:
+		if p.BuyQuantity == nil || p.GetQuantity == nil {
+			return nil
+		}
+		units := unitsOf(lines, eligible)
+		free := units / (*p.BuyQuantity + *p.GetQuantity) * *p.GetQuantity
+		taken := take(lines, eligible, free, true)
+
+		shares := make([]share, 0, len(taken))
+		for _, i := range eligible {
+			if taken[i] > 0 {
+				amount := remaining[i].MulRatMajor(int64(taken[i]), int64(lines[i].Quantity), money.HalfUp)
+				shares = append(shares, share{line: i, quantity: taken[i], amount: amount})
+			}
+		}
+		return shares
+
+	case TypeBundle:
+		if p.BundleQuantity == nil || p.Amount == nil {
+			return nil
+		}
+		size := *p.BundleQuantity
+		bundles := unitsOf(lines, eligible) / size
+		if bundles == 0 {
+			return nil
+		}
+		taken := take(lines, eligible, bundles*size, false)
+
+		var bundled []int
+		values := make([]money.Money, len(lines))
+		for _, i := range eligible {
+			if taken[i] > 0 {
+				bundled = append(bundled, i)
+				values[i] = remaining[i].MulRatMajor(int64(taken[i]), int64(lines[i].Quantity), money.HalfUp)
+			}
+		}
+		discount := sumOf(values, bundled).Sub(p.Amount.Mul(int64(bundles)))
+		if discount.IsZero() || discount.IsNegative() {
+			return nil
+		}
+
+		shares := spread(lines, values, bundled, discount)
+		for k := range shares {
+			shares[k].quantity = taken[shares[k].line]
+		}
+		return shares
+	}
+	return nil
+}
+
+// spread splits amount, rounded down to whole major units, over the
+// eligible lines in proportion to what is left of them, never more than is
+// left in total.
+func spread(lines []Line, remaining []money.Money, eligible []int, amount money.Money) []share {
+	amount = amount.RoundMajor(money.Down)
+	base := sumOf(remaining, eligible)
+	if amount.Cmp(base) > 0 {
+		amount = base
+	}
+
+	ratios := make([]int64, len(eligible))
+	for k, i := range eligible {
+		ratios[k] = remaining[i].Amount()
+	}
+	parts := amount.AllocateMajor(ratios...)
+
+	shares := make([]share, 0, len(eligible))
+	for k, i := range eligible {
+		shares = append(shares, share{line: i, quantity: lines[i].Quantity, amount: parts[k]})
+	}
+	return shares
+}
+
+// take picks count units of the eligible lines, the cheapest first or the
+// dearest first, and returns how many it took of each line.
+func take(lines []Line, eligible []int, count int, cheapest bool) map[int]int {
+	order := append([]int(nil), eligible...)
+	sort.SliceStable(order, func(a, b int) bool {
+		cmp := lines[order[a]].UnitPrice.Cmp(lines[order[b]].UnitPrice)
+		if cheapest {
+			return cmp < 0
+		}
+		return cmp > 0
+	})
+
+	taken := make(map[int]int, len(order))
+	for _, i := range order {
+		if count == 0 {
+			break
+		}
+		n := lines[i].Quantity
+		if n > count {
+			n = count
+		}
+		taken[i] = n
+		count -= n
+	}
+	return taken
+}
+
+func unitsOf(lines []Line, eligible []int) int {
+	units := 0
+	for _, i := range eligible {
+		units += lines[i].Quantity
+	}
+	return units
+}
+
+func sumOf(amounts []money.Money, indexes []int) money.Money {
+	var sum money.Money
+	for _, i := range indexes {
+		sum = sum.Add(amounts[i])
+	}
+	return sum
+}
@@ -0,0 +1,269 @@
+package entity
+
+import (
+	"projectsphere/eniqlo-store/pkg/money"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func rp(major int64) money.Money {
+	return money.FromMajor(major, money.IDR)
+}
+
+func rpPtr(major int64) *money.Money {
+	amount := rp(major)
+	return &amount
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+// promo returns a running stackable cart promotion of the given type.
+func promo(id int, promotionType string) Promotion {
+	return Promotion{
+		ID:        id,
+		Name:      promotionType,
+		Type:      promotionType,
+		Scope:     ScopeCart,
+		Stackable: true,
+		IsActive:  true,
+		StartsAt:  now.Add(-time.Hour),
+	}
+}
+
+func line(productID, quantity int, unitPrice int64) Line {
+	return Line{ProductID: productID, Category: "clothing", Quantity: quantity, UnitPrice: rp(unitPrice)}
+}
+
+// checkExplained verifies that every line's discounts explain its discount
+// and that the lines add up to the cart, all in whole rupiah.
+func checkExplained(t *testing.T, pricing Pricing) {
+	t.Helper()
+	var subtotal, discount, total money.Money
+	for i, priced := range pricing.Lines {
+		var explained money.Money
+		for _, d := range priced.Discounts {
+			explained = explained.Add(d.Amount)
+		}
+		if explained.Cmp(priced.Discount) != 0 {
+			t.Errorf("line %d: discounts add up to %v, want %v", i, explained, priced.Discount)
+		}
+		if priced.Subtotal.Sub(priced.Discount).Cmp(priced.Total) != 0 || priced.Total.IsNegative() {
+			t.Errorf("line %d: %v - %v != %v", i, priced.Subtotal, priced.Discount, priced.Total)
+		}
+		for _, amount := range []money.Money{priced.Subtotal, priced.Discount, priced.Total} {
+			if amount.RoundMajor(money.Down).Cmp(amount) != 0 {
+				t.Errorf("line %d: %v is not whole rupiah", i, amount)
+			}
+		}
+		subtotal, discount, total = subtotal.Add(priced.Subtotal), discount.Add(priced.Discount), total.Add(priced.Total)
+	}
+	if subtotal.Cmp(pricing.Subtotal) != 0 || discount.Cmp(pricing.Discount) != 0 || total.Cmp(pricing.Total) != 0 {
+		t.Errorf("cart = %v - %v = %v, lines add up to %v - %v = %v",
+			pricing.Subtotal, pricing.Discount, pricing.Total, subtotal, discount, total)
+	}
+}
+
+func TestPrice(t *testing.T) {
+	percent := func(id, value int) Promotion {
+		p := promo(id, TypePercentage)
+		p.Percent = intPtr(value)
+		return p
+	}
+	fixed := func(id int, amount int64) Promotion {
+		p := promo(id, TypeFixed)
+		p.Amount = rpPtr(amount)
+		return p
+	}
+	withCode := func(p Promotion, code string) Promotion {
+		p.Code = &code
+		return p
+	}
+	prioritized := func(p Promotion, priority int, stackable bool) Promotion {
+		p.Priority, p.Stackable = priority, stackable
+		return p
+	}
+	productScope := func(p Promotion, ids ...int) Promotion {
+		p.Scope, p.ProductIDs = ScopeProduct, ids
+		return p
+	}
+	categoryScope := func(p Promotion, keys ...string) Promotion {
+		p.Scope, p.CategoryKeys = ScopeCategory, keys
+		return p
+	}
+	bogo := promo(1, TypeBOGO)
+	bogo.BuyQuantity, bogo.GetQuantity = intPtr(1), intPtr(1)
+	bundle := promo(1, TypeBundle)
+	bundle.BundleQuantity, bundle.Amount = intPtr(3), rpPtr(25000)
+	minSpend := promo(1, TypeMinSpend)
+	minSpend.MinSpend, minSpend.Percent = rpPtr(50000), intPtr(10)
+	ended := percent(1, 50)
+	ended.EndsAt = &now
+	inactive := percent(1, 50)
+	inactive.IsActive = false
+
+	tests := []struct {
+		name       string
+		lines      []Line
+		codes      []string
+		promotions []Promotion
+		// wantDiscounts is the discount of every line in rupiah
+		wantDiscounts []int64
+	}{
+		{
+			name:          "no promotions",
+			lines:         []Line{line(1, 2, 15000)},
+			wantDiscounts: []int64{0},
+		},
+		{
+			name:          "percentage",
+			lines:         []Line{line(1, 2, 15000), line(2, 1, 8000)},
+			promotions:    []Promotion{percent(1, 10)},
+			wantDiscounts: []int64{3000, 800},
+		},
+		{
+			name:          "percentage rounds to whole rupiah",
+			lines:         []Line{line(1, 3, 3333)},
+			promotions:    []Promotion{percent(1, 15)},
+			wantDiscounts: []int64{1500},
+		},
+		{
+			name:          "percentage on the products in scope",
+			lines:         []Line{line(1, 1, 10000), line(2, 1, 10000)},
+			promotions:    []Promotion{productScope(percent(1, 20), 2)},
+			wantDiscounts: []int64{0, 2000},
+		},
+		{
+			name:          "percentage on a category with its subcategories",
+			lines:         []Line{line(1, 1, 10000), {ProductID: 2, Category: "food", Quantity: 1, UnitPrice: rp(10000)}},
+			promotions:    []Promotion{categoryScope(percent(1, 20), "apparel", "clothing")},
+			wantDiscounts: []int64{2000, 0},
+		},
+		{
+			name:          "fixed is spread by what is left of the lines",
+			lines:         []Line{line(1, 1, 20000), line(2, 1, 30000)},
+			promotions:    []Promotion{fixed(1, 10000)},
+			wantDiscounts: []int64{4000, 6000},
+		},
+		{
+			name:          "fixed never goes below zero",
+			lines:         []Line{line(1, 1, 20000)},
+			promotions:    []Promotion{fixed(1, 50000)},
+			wantDiscounts: []int64{20000},
+		},
+		{
+			name:          "bogo gives the cheapest units",
+			lines:         []Line{line(1, 3, 10000), line(2, 1, 5000)},
+			promotions:    []Promotion{bogo},
+			wantDiscounts: []int64{10000, 5000},
+		},
+		{
+			name:          "bogo needs a full set",
+			lines:         []Line{line(1, 1, 10000)},
+			promotions:    []Promotion{bogo},
+			wantDiscounts: []int64{0},
+		},
+		{
+			name:  "bundle takes the dearest units",
+			lines: []Line{line(1, 2, 10000), line(2, 2, 12000)},
+			// 10000 + 24000 for 25000, the 9000 spread 10:24 with the
+			// leftover rupiah on the first line
+			promotions:    []Promotion{bundle},
+			wantDiscounts: []int64{2648, 6352},
+		},
+		{
+			name:          "bundle dearer than the units",
+			lines:         []Line{line(1, 3, 5000)},
+			promotions:    []Promotion{bundle},
+			wantDiscounts: []int64{0},
+		},
+		{
+			name:          "min spend not reached",
+			lines:         []Line{line(1, 4, 12000)},
+			promotions:    []Promotion{minSpend},
+			wantDiscounts: []int64{0},
+		},
+		{
+			name:          "min spend reached",
+			lines:         []Line{line(1, 4, 12000), line(2, 1, 2000)},
+			promotions:    []Promotion{minSpend},
+			wantDiscounts: []int64{4800, 200},
+		},
+		{
+			name:          "stackable promotions combine by priority",
+			lines:         []Line{line(1, 1, 20000)},
+			promotions:    []Promotion{prioritized(fixed(2, 1000), 5, true), prioritized(percent(1, 10), 10, true)},
+			wantDiscounts: []int64{3000},
+		},
+		{
+			name:  "lower priority applies to what is left",
+			lines: []Line{line(1, 1, 20000)},
+			// 1000 first, then 10% of 19000
+			promotions:    []Promotion{prioritized(fixed(2, 1000), 10, true), prioritized(percent(1, 10), 5, true)},
+			wantDiscounts: []int64{2900},
+		},
+		{
+			name:          "equal priority applies by id",
+			lines:         []Line{line(1, 1, 20000)},
+			promotions:    []Promotion{percent(2, 10), fixed(1, 1000)},
+			wantDiscounts: []int64{2900},
+		},
+		{
+			name:          "non stackable first closes the line",
+			lines:         []Line{line(1, 1, 20000)},
+			promotions:    []Promotion{prioritized(percent(1, 10), 10, false), prioritized(fixed(2, 1000), 5, true)},
+			wantDiscounts: []int64{2000},
+		},
+		{
+			name:          "non stackable skips discounted lines",
+			lines:         []Line{line(1, 1, 20000), line(2, 1, 20000)},
+			promotions:    []Promotion{prioritized(productScope(percent(1, 10), 1), 10, true), prioritized(percent(2, 50), 5, false)},
+			wantDiscounts: []int64{2000, 10000},
+		},
+		{
+			name:          "code not given",
+			lines:         []Line{line(1, 1, 20000)},
+			promotions:    []Promotion{withCode(percent(1, 10), "HEMAT10")},
+			wantDiscounts: []int64{0},
+		},
+		{
+			name:          "code given",
+			lines:         []Line{line(1, 1, 20000)},
+			codes:         []string{"hemat10"},
+			promotions:    []Promotion{withCode(percent(1, 10), "HEMAT10")},
+			wantDiscounts: []int64{2000},
+		},
+		{
+			name:          "not running",
+			lines:         []Line{line(1, 1, 20000)},
+			promotions:    []Promotion{ended, inactive},
+			wantDiscounts: []int64{0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pricing := Price(Cart{Lines: tt.lines, Codes: tt.codes, At: now}, tt.promotions)
+			checkExplained(t, pricing)
+
+			got := make([]int64, len(pricing.Lines))
+			for i, priced := range pricing.Lines {
+				got[i] = priced.Discount.Amount() / 100
+			}
+			if !reflect.DeepEqual(got, tt.wantDiscounts) {
+				t.Errorf("line discounts = %v, want %v", got, tt.wantDiscounts)
+			}
+		})
+	}
+}
+
+func TestPriceRoundsSubtotalsToWholeRupiah(t *testing.T) {
+	pricing := Price(Cart{Lines: []Line{{ProductID: 1, Quantity: 3, UnitPrice: money.New(1000050, money.IDR)}}, At: now}, nil)
+	checkExplained(t, pricing)
+	if want := rp(30002); pricing.Total.Cmp(want) != 0 {
+		t.Errorf("Total = %v, want %v", pricing.Total, want)
+	}
+}
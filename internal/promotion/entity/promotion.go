@@ -0,0 +1,187 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"projectsphere/eniqlo-store/pkg/money"
+	"strings"
+	"time"
+)
+
+// Promotion types. Percentage takes Percent off every unit in scope and
+// fixed takes Amount off the units in scope together. BOGO gives
+// GetQuantity units free for every BuyQuantity bought, the cheapest units
+// first; bundle sells every BundleQuantity units for Amount, the dearest
+// units first. Minimum spend takes Percent or Amount off once the units in
+// scope add up to MinSpend.
+const (
+	TypePercentage = "percentage"
+	TypeFixed      = "fixed"
+	TypeBOGO       = "bogo"
+	TypeBundle     = "bundle"
+	TypeMinSpend   = "min_spend"
+)
+
+// Promotion scopes: the listed products, the listed categories with their
+// subcategories, or the whole cart.
+const (
+	ScopeProduct  = "product"
+	ScopeCategory = "category"
+	ScopeCart     = "cart"
+)
+
+// Promotion statuses, derived from IsActive and the validity window.
+const (
+	StatusScheduled = "scheduled"
+	StatusRunning   = "running"
+	StatusEnded     = "ended"
+	StatusInactive  = "inactive"
+)
+
+// ProductIDs is stored as a jsonb array.
+type ProductIDs []int
+
+func (p ProductIDs) Value() (driver.Value, error) {
+	if p == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(p)
+}
+
+func (p *ProductIDs) Scan(src interface{}) error {
+	return scanJSON(src, p, "promotion product ids must be jsonb")
+}
+
+// Categories is stored as a jsonb array of category slugs.
+type Categories []string
+
+func (c Categories) Value() (driver.Value, error) {
+	if c == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(c)
+}
+
+func (c *Categories) Scan(src interface{}) error {
+	return scanJSON(src, c, "promotion categories must be jsonb")
+}
+
+func scanJSON(src, dest interface{}, mismatch string) error {
+	switch value := src.(type) {
+	case []byte:
+		return json.Unmarshal(value, dest)
+	case string:
+		return json.Unmarshal([]byte(value), dest)
+	default:
+		return errors.New(mismatch)
+	}
+}
+
+// Promotion is a discount rule. Promotions with a Code only apply when the
+// code is given at checkout. They run from StartsAt until EndsAt, or
+// without end when EndsAt is nil. Promotions apply by descending Priority;
+// one that is not Stackable does not combine with others on a line.
+type Promotion struct {
+	ID             int          `db:"id_promotion" json:"promotionId"`
+	Name           string       `db:"name" json:"name"`
+	Code           *string      `db:"code" json:"code"`
+	Type           string       `db:"type" json:"type"`
+	Scope          string       `db:"scope" json:"scope"`
+	ProductIDs     ProductIDs   `db:"product_ids" json:"productIds"`
+	Categories     Categories   `db:"categories" json:"categories"`
+	Percent        *int         `db:"percent" json:"percent"`
+	Amount         *money.Money `db:"amount" json:"amount"`
+	BuyQuantity    *int         `db:"buy_quantity" json:"buyQuantity"`
+	GetQuantity    *int         `db:"get_quantity" json:"getQuantity"`
+	BundleQuantity *int         `db:"bundle_quantity" json:"bundleQuantity"`
+	MinSpend       *money.Money `db:"min_spend" json:"minSpend"`
+	Priority       int          `db:"priority" json:"priority"`
+	Stackable      bool         `db:"stackable" json:"stackable"`
+	StartsAt       time.Time    `db:"starts_at" json:"startsAt"`
+	EndsAt         *time.Time   `db:"ends_at" json:"endsAt"`
+	IsActive       bool         `db:"is_active" json:"isActive"`
+	Status         string       `db:"status" json:"status"`
+	CreatedBy      *int         `db:"created_by" json:"createdBy"`
+	CreatedAt      time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time    `db:"updated_at" json:"updated_at"`
+
+	// CategoryKeys are Categories with their subcategories, filled in
+	// before pricing.
+	CategoryKeys []string `db:"-" json:"-"`
+}
+
+// RunsAt reports whether the promotion is active and within its validity
+// window at.
+func (p Promotion) RunsAt(at time.Time) bool {
+	return p.IsActive && !at.Before(p.StartsAt) && (p.EndsAt == nil || at.Before(*p.EndsAt))
+}
+
+// Covers reports whether line is in the promotion's scope.
+func (p Promotion) Covers(line Line) bool {
+	switch p.Scope {
+	case ScopeProduct:
+		for _, id := range p.ProductIDs {
+			if id == line.ProductID {
+				return true
+			}
+		}
+		return false
+	case ScopeCategory:
+		for _, category := range p.CategoryKeys {
+			if category == line.Category {
+				return true
+			}
+		}
+		for _, category := range p.Categories {
+			if category == line.Category {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// PromotionParam creates or replaces a promotion. Only the fields its type
+// uses may be set, see the Type constants.
+type PromotionParam struct {
+	Name           string       `json:"name" validate:"required,min=1,max=100"`
+	Code           *string      `json:"code" validate:"omitempty,min=3,max=30,alphanum"`
+	Type           string       `json:"type" validate:"required,oneof=percentage fixed bogo bundle min_spend"`
+	Scope          string       `json:"scope" validate:"required,oneof=product category cart"`
+	ProductIDs     []int        `json:"productIds" validate:"max=100,dive,min=1"`
+	Categories     []string     `json:"categories" validate:"max=20,dive,category"`
+	Percent        *int         `json:"percent" validate:"omitempty,min=1,max=100"`
+	Amount         *money.Money `json:"amount" validate:"omitempty,min=1,max=1000000000"`
+	BuyQuantity    *int         `json:"buyQuantity" validate:"omitempty,min=1,max=1000"`
+	GetQuantity    *int         `json:"getQuantity" validate:"omitempty,min=1,max=1000"`
+	BundleQuantity *int         `json:"bundleQuantity" validate:"omitempty,min=2,max=1000"`
+	MinSpend       *money.Money `json:"minSpend" validate:"omitempty,min=1"`
+	Priority       int          `json:"priority" validate:"min=-1000,max=1000"`
+	Stackable      bool         `json:"stackable"`
+	StartsAt       time.Time    `json:"startsAt" validate:"required"`
+	EndsAt         *time.Time   `json:"endsAt"`
+	IsActive       bool         `json:"isActive"`
+}
+
+// PromotionFilter narrows down the promotion list.
+type PromotionFilter struct {
+	Status string
+	Limit  int
+	Offset int
+}
+
+// NormalizeCode makes promotion codes case insensitive.
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func IsStatus(status string) bool {
+	switch status {
+	case StatusScheduled, StatusRunning, StatusEnded, StatusInactive:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"net/http"
+	"projectsphere/eniqlo-store/internal/promotion/entity"
+	"projectsphere/eniqlo-store/internal/promotion/service"
+	"projectsphere/eniqlo-store/pkg/middleware/auth"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"projectsphere/eniqlo-store/pkg/validator"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+type PromotionHandler struct {
+	promotionSvc service.PromotionService
+}
+
+func NewPromotionHandler(promotionSvc service.PromotionService) PromotionHandler {
+	return PromotionHandler{
+		promotionSvc: promotionSvc,
+	}
+}
+
+// List returns promotions, latest start first, e.g.
+// ?status=running&limit=20&offset=0.
+func (h PromotionHandler) List(c *gin.Context) {
+	filter := entity.PromotionFilter{Status: c.Query("status")}
+	if filter.Status != "" && !entity.IsStatus(filter.Status) {
+		c.Error(msg.BadRequest(msg.ErrInvalidFilterRequest))
+		return
+	}
+
+	var err error
+	if filter.Limit, filter.Offset, err = pageParams(c); err != nil {
+		c.Error(err)
+		return
+	}
+
+	promotions, err := h.promotionSvc.List(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetAllResponse), promotions))
+}
+
+func (h PromotionHandler) Get(c *gin.Context) {
+	promotionID, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	promotion, err := h.promotionSvc.Get(c.Request.Context(), promotionID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetByIDResponse), promotion))
+}
+
+// Create adds a promotion, e.g. {"name": "10% off footwear", "type":
+// "percentage", "scope": "category", "categories": ["footwear"], "percent":
+// 10, "startsAt": "2024-06-01T00:00:00+07:00", "isActive": true}.
+func (h PromotionHandler) Create(c *gin.Context) {
+	payload := new(entity.PromotionParam)
+	if err := c.ShouldBindJSON(payload); err != nil {
+		c.Error(validator.BindError(err))
+		return
+	}
+
+	userID, err := auth.GetUserIdInsideCtx(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	promotion, err := h.promotionSvc.Create(c.Request.Context(), *payload, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, msg.ReturnResult(msg.T(c.Request.Context(), msg.PromotionCreatedResponse), promotion))
+}
+
+// Update replaces a promotion; setting isActive to false stops it.
+func (h PromotionHandler) Update(c *gin.Context) {
+	promotionID, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	payload := new(entity.PromotionParam)
+	if err := c.ShouldBindJSON(payload); err != nil {
+		c.Error(validator.BindError(err))
+		return
+	}
+
+	promotion, err := h.promotionSvc.Update(c.Request.Context(), promotionID, *payload)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.PromotionUpdatedResponse), promotion))
+}
+
+// pageParams reads ?limit=20&offset=0.
+func pageParams(c *gin.Context) (limit, offset int, err error) {
+	limit = defaultPageLimit
+	if raw := c.Query("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil {
+			return 0, 0, msg.BadRequest(msg.ErrLimitNotNumber)
+		}
+		if limit < 1 || limit > maxPageLimit {
+			return 0, 0, msg.BadRequest(msg.ErrLimitMustBetween0Until100)
+		}
+	}
+	if raw := c.Query("offset"); raw != "" {
+		offset, err = strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return 0, 0, msg.BadRequest(msg.ErrInvalidFilterRequest)
+		}
+	}
+	return limit, offset, nil
+}
+
+// pathID reads a positive id path parameter.
+func pathID(c *gin.Context, key string) (int, error) {
+	id, err := strconv.Atoi(c.Param(key))
+	if err != nil || id <= 0 {
+		return 0, msg.BadRequest(msg.ErrConvertIdToInt)
+	}
+	return id, nil
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"projectsphere/eniqlo-store/internal/promotion/entity"
+	"projectsphere/eniqlo-store/pkg/database"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type PromotionRepo struct {
+	dbConnector database.PostgresConnector
+}
+
+func NewPromotionRepo(dbConnector database.PostgresConnector) PromotionRepo {
+	return PromotionRepo{
+		dbConnector: dbConnector,
+	}
+}
+
+const promotionColumns = `id_promotion, name, code, type, scope, product_ids, categories, percent, amount, buy_quantity, get_quantity,
+            bundle_quantity, min_spend, priority, stackable, starts_at, ends_at, is_active, created_by, created_at, updated_at,
+            CASE
+                WHEN NOT is_active THEN 'inactive'
+                WHEN starts_at > LOCALTIMESTAMP THEN 'scheduled'
+                WHEN ends_at <= LOCALTIMESTAMP THEN 'ended'
+                ELSE 'running'
+            END AS status`
+
+// ListPromotions returns a page of promotions, the latest first, only those
+// in filter.Status when set.
+func (r PromotionRepo) ListPromotions(ctx context.Context, filter entity.PromotionFilter) ([]entity.Promotion, error) {
+	promotions := []entity.Promotion{}
+	err := r.dbConnector.DB.SelectContext(ctx, &promotions, `
+        SELECT *
+        FROM (SELECT `+promotionColumns+` FROM "promotions") p
+        WHERE ($1 = '' OR status = $1)
+        ORDER BY starts_at DESC, id_promotion DESC
+        LIMIT $2 OFFSET $3
+    `, filter.Status, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+	return promotions, nil
+}
+
+func (r PromotionRepo) GetPromotion(ctx context.Context, promotionID int) (entity.Promotion, error) {
+	var promotion entity.Promotion
+	err := r.dbConnector.DB.GetContext(ctx, &promotion, `SELECT `+promotionColumns+` FROM "promotions" WHERE id_promotion = $1`, promotionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return entity.Promotion{}, msg.NotFound(msg.ErrPromotionNotFound)
+		}
+		return entity.Promotion{}, msg.InternalServerError(err.Error())
+	}
+	return promotion, nil
+}
+
+// RunningPromotions returns the promotions running now together with the
+// database time they were selected at, which is in the same local time as
+// their validity windows.
+func (r PromotionRepo) RunningPromotions(ctx context.Context) ([]entity.Promotion, time.Time, error) {
+	var now time.Time
+	if err := r.dbConnector.DB.GetContext(ctx, &now, `SELECT LOCALTIMESTAMP`); err != nil {
+		return nil, time.Time{}, msg.InternalServerError(err.Error())
+	}
+
+	promotions := []entity.Promotion{}
+	err := r.dbConnector.DB.SelectContext(ctx, &promotions, `
+        SELECT `+promotionColumns+`
+        FROM "promotions"
+        WHERE is_active AND starts_at <= $1 AND (ends_at IS NULL OR ends_at > $1)
+    `, now)
+	if err != nil {
+		return nil, time.Time{}, msg.InternalServerError(err.Error())
+	}
+	return promotions, now, nil
+}
+
+// CreatePromotion stores param. Its validity window is converted to local
+// time in the session time zone, like CURRENT_TIMESTAMP.
+func (r PromotionRepo) CreatePromotion(ctx context.Context, param entity.Promotion) (entity.Promotion, error) {
+	if err := r.checkProducts(ctx, param.ProductIDs); err != nil {
+		return entity.Promotion{}, err
+	}
+
+	var promotion entity.Promotion
+	err := r.dbConnector.DB.GetContext(ctx, &promotion, `
+        INSERT INTO "promotions" (name, code, type, scope, product_ids, categories, percent, amount, buy_quantity, get_quantity,
+            bundle_quantity, min_spend, priority, stackable, starts_at, ends_at, is_active, created_by)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15::timestamptz, $16::timestamptz, $17, $18)
+        RETURNING `+promotionColumns,
+		param.Name, param.Code, param.Type, param.Scope, param.ProductIDs, param.Categories, param.Percent, param.Amount,
+		param.BuyQuantity, param.GetQuantity, param.BundleQuantity, param.MinSpend, param.Priority, param.Stackable,
+		param.StartsAt, param.EndsAt, param.IsActive, param.CreatedBy)
+	if err != nil {
+		return entity.Promotion{}, promotionWriteError(err)
+	}
+	return promotion, nil
+}
+
+// UpdatePromotion replaces a promotion. Sales already made keep the
+// discounts they were given.
+func (r PromotionRepo) UpdatePromotion(ctx context.Context, param entity.Promotion) (entity.Promotion, error) {
+	if err := r.checkProducts(ctx, param.ProductIDs); err != nil {
+		return entity.Promotion{}, err
+	}
+
+	var promotion entity.Promotion
+	err := r.dbConnector.DB.GetContext(ctx, &promotion, `
+        UPDATE "promotions"
+        SET name = $1, code = $2, type = $3, scope = $4, product_ids = $5, categories = $6, percent = $7, amount = $8,
+            buy_quantity = $9, get_quantity = $10, bundle_quantity = $11, min_spend = $12, priority = $13, stackable = $14,
+            starts_at = $15::timestamptz, ends_at = $16::timestamptz, is_active = $17, updated_at = CURRENT_TIMESTAMP
+        WHERE id_promotion = $18
+        RETURNING `+promotionColumns,
+		param.Name, param.Code, param.Type, param.Scope, param.ProductIDs, param.Categories, param.Percent, param.Amount,
+		param.BuyQuantity, param.GetQuantity, param.BundleQuantity, param.MinSpend, param.Priority, param.Stackable,
+		param.StartsAt, param.EndsAt, param.IsActive, param.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return entity.Promotion{}, msg.NotFound(msg.ErrPromotionNotFound)
+		}
+		return entity.Promotion{}, promotionWriteError(err)
+	}
+	return promotion, nil
+}
+
+// checkProducts makes sure every product a promotion targets exists.
+func (r PromotionRepo) checkProducts(ctx context.Context, productIDs entity.ProductIDs) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+
+	var found int
+	err := r.dbConnector.DB.GetContext(ctx, &found, `SELECT COUNT(*) FROM "products" WHERE id_product = ANY($1)`, pq.Array(productIDs))
+	if err != nil {
+		return msg.InternalServerError(err.Error())
+	}
+	if found != len(productIDs) {
+		return msg.NotFound(msg.ErrProductNotFound)
+	}
+	return nil
+}
+
+func promotionWriteError(err error) error {
+	switch {
+	case strings.Contains(err.Error(), "promotions_code_key"):
+		return msg.Conflict(msg.ErrPromotionCodeExists)
+	default:
+		return msg.InternalServerError(err.Error())
+	}
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	categoryService "projectsphere/eniqlo-store/internal/category/service"
+	"projectsphere/eniqlo-store/internal/promotion/entity"
+	"projectsphere/eniqlo-store/internal/promotion/repository"
+	"projectsphere/eniqlo-store/pkg/middleware/logger"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"time"
+)
+
+type PromotionService struct {
+	promotionRepo repository.PromotionRepo
+	categorySvc   categoryService.CategoryService
+}
+
+func NewPromotionService(promotionRepo repository.PromotionRepo, categorySvc categoryService.CategoryService) PromotionService {
+	return PromotionService{
+		promotionRepo: promotionRepo,
+		categorySvc:   categorySvc,
+	}
+}
+
+func (s PromotionService) List(ctx context.Context, filter entity.PromotionFilter) ([]entity.Promotion, error) {
+	return s.promotionRepo.ListPromotions(ctx, filter)
+}
+
+func (s PromotionService) Get(ctx context.Context, promotionID int) (entity.Promotion, error) {
+	return s.promotionRepo.GetPromotion(ctx, promotionID)
+}
+
+// Running returns the promotions running now, with their categories
+// expanded to their subcategories, and the time to price at.
+func (s PromotionService) Running(ctx context.Context) ([]entity.Promotion, time.Time, error) {
+	promotions, now, err := s.promotionRepo.RunningPromotions(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var tree *categoryService.Tree
+	for i, promotion := range promotions {
+		if promotion.Scope != entity.ScopeCategory {
+			continue
+		}
+		if tree == nil {
+			if tree, err = s.categorySvc.Tree(ctx); err != nil {
+				return nil, time.Time{}, err
+			}
+		}
+		for _, category := range promotion.Categories {
+			promotions[i].CategoryKeys = append(promotions[i].CategoryKeys, tree.Keys(category)...)
+		}
+	}
+
+	return promotions, now, nil
+}
+
+func (s PromotionService) Create(ctx context.Context, param entity.PromotionParam, userID uint32) (entity.Promotion, error) {
+	promotion, err := fromParam(param)
+	if err != nil {
+		return entity.Promotion{}, err
+	}
+	if userID != 0 {
+		createdBy := int(userID)
+		promotion.CreatedBy = &createdBy
+	}
+
+	promotion, err = s.promotionRepo.CreatePromotion(ctx, promotion)
+	if err != nil {
+		return entity.Promotion{}, err
+	}
+
+	logger.FromContext(ctx).Info().
+		Int("promotionId", promotion.ID).
+		Str("type", promotion.Type).
+		Str("scope", promotion.Scope).
+		Msg("promotion created")
+
+	return promotion, nil
+}
+
+func (s PromotionService) Update(ctx context.Context, promotionID int, param entity.PromotionParam) (entity.Promotion, error) {
+	promotion, err := fromParam(param)
+	if err != nil {
+		return entity.Promotion{}, err
+	}
+	promotion.ID = promotionID
+
+	promotion, err = s.promotionRepo.UpdatePromotion(ctx, promotion)
+	if err != nil {
+		return entity.Promotion{}, err
+	}
+
+	logger.FromContext(ctx).Info().
+		Int("promotionId", promotion.ID).
+		Str("status", promotion.Status).
+		Msg("promotion updated")
+
+	return promotion, nil
+}
+
+// fromParam validates the fields param's type and scope need, and rejects
+// those they do not use.
+func fromParam(param entity.PromotionParam) (entity.Promotion, error) {
+	var details []msg.FieldError
+	require := func(field string, set bool) {
+		if !set {
+			details = append(details, msg.NewFieldError(field, "required", msg.ValRequiredFor, field, param.Type))
+		}
+	}
+	reject := func(field string, set bool) {
+		if set {
+			details = append(details, msg.NewFieldError(field, "excluded", msg.ValNotFor, field, param.Type))
+		}
+	}
+
+	switch param.Type {
+	case entity.TypePercentage:
+		require("percent", param.Percent != nil)
+		reject("amount", param.Amount != nil)
+	case entity.TypeFixed:
+		require("amount", param.Amount != nil)
+		reject("percent", param.Percent != nil)
+	case entity.TypeBundle:
+		require("bundleQuantity", param.BundleQuantity != nil)
+		require("amount", param.Amount != nil)
+		reject("percent", param.Percent != nil)
+	case entity.TypeMinSpend:
+		require("minSpend", param.MinSpend != nil)
+		if (param.Percent == nil) == (param.Amount == nil) {
+			details = append(details, msg.NewFieldError("percent", "required_without", msg.ValRequiredFor, "percent or amount", param.Type))
+		}
+	case entity.TypeBOGO:
+		require("buyQuantity", param.BuyQuantity != nil)
+		require("getQuantity", param.GetQuantity != nil)
+		reject("percent", param.Percent != nil)
+		reject("amount", param.Amount != nil)
+	}
+	if param.Type != entity.TypeBOGO {
+		reject("buyQuantity", param.BuyQuantity != nil)
+		reject("getQuantity", param.GetQuantity != nil)
+	}
+	if param.Type != entity.TypeBundle {
+		reject("bundleQuantity", param.BundleQuantity != nil)
+	}
+	if param.Type != entity.TypeMinSpend {
+		reject("minSpend", param.MinSpend != nil)
+	}
+
+	switch param.Scope {
+	case entity.ScopeProduct:
+		if len(param.ProductIDs) == 0 {
+			details = append(details, msg.NewFieldError("productIds", "required", msg.ValRequiredFor, "productIds", param.Scope))
+		}
+	case entity.ScopeCategory:
+		if len(param.Categories) == 0 {
+			details = append(details, msg.NewFieldError("categories", "required", msg.ValRequiredFor, "categories", param.Scope))
+		}
+	}
+	if param.Scope != entity.ScopeProduct && len(param.ProductIDs) > 0 {
+		details = append(details, msg.NewFieldError("productIds", "excluded", msg.ValNotFor, "productIds", param.Scope))
+	}
+	if param.Scope != entity.ScopeCategory && len(param.Categories) > 0 {
+		details = append(details, msg.NewFieldError("categories", "excluded", msg.ValNotFor, "categories", param.Scope))
+	}
+
+	if param.EndsAt != nil && !param.EndsAt.After(param.StartsAt) {
+		details = append(details, msg.NewFieldError("endsAt", "gtfield", msg.ValAfter, "endsAt", "startsAt"))
+	}
+	if len(details) > 0 {
+		return entity.Promotion{}, msg.Validation(details...)
+	}
+
+	promotion := entity.Promotion{
+		Name:           param.Name,
+		Type:           param.Type,
+		Scope:          param.Scope,
+		ProductIDs:     entity.ProductIDs{},
+		Categories:     entity.Categories{},
+		Percent:        param.Percent,
+		Amount:         param.Amount,
+		BuyQuantity:    param.BuyQuantity,
+		GetQuantity:    param.GetQuantity,
+		BundleQuantity: param.BundleQuantity,
+		MinSpend:       param.MinSpend,
+		Priority:       param.Priority,
+		Stackable:      param.Stackable,
+		StartsAt:       param.StartsAt,
+		EndsAt:         param.EndsAt,
+		IsActive:       param.IsActive,
+	}
+	if param.Code != nil {
+		code := entity.NormalizeCode(*param.Code)
+		promotion.Code = &code
+	}
+
+	seen := make(map[int]bool, len(param.ProductIDs))
+	for _, id := range param.ProductIDs {
+		if !seen[id] {
+			seen[id] = true
+			promotion.ProductIDs = append(promotion.ProductIDs, id)
+		}
+	}
+	seenCategory := make(map[string]bool, len(param.Categories))
+	for _, category := range param.Categories {
+		if !seenCategory[category] {
+			seenCategory[category] = true
+			promotion.Categories = append(promotion.Categories, category)
+		}
+	}
+
+	return promotion, nil
+}
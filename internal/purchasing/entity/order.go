@@ -0,0 +1,160 @@
+package entity
+
+import (
+	"projectsphere/eniqlo-store/pkg/money"
+	"time"
+)
+
+// Purchase order statuses. A draft can still be changed; once sent, goods
+// receipts move it to partially received and received. Drafts and orders
+// not fully received can be cancelled, which keeps what was received.
+const (
+	OrderDraft             = "draft"
+	OrderSent              = "sent"
+	OrderPartiallyReceived = "partially_received"
+	OrderReceived          = "received"
+	OrderCancelled         = "cancelled"
+)
+
+// MaxOrderLines bounds the lines of one purchase order.
+const MaxOrderLines = 100
+
+// OrderLineParam orders Quantity of a variant at UnitCost. VariantID may be
+// left out for products without options.
+type OrderLineParam struct {
+	ProductID int         `json:"productId" validate:"required,min=1"`
+	VariantID int         `json:"variantId" validate:"omitempty,min=1"`
+	Quantity  int         `json:"quantity" validate:"required,min=1,max=100000"`
+	UnitCost  money.Money `json:"unitCost" validate:"min=0"`
+}
+
+// OrderParam creates or replaces a draft purchase order. Its goods are
+// received into LocationID, the selling location when zero.
+type OrderParam struct {
+	SupplierID int              `json:"supplierId" validate:"required,min=1"`
+	LocationID int              `json:"locationId" validate:"omitempty,min=1"`
+	Note       string           `json:"note" validate:"max=200"`
+	Lines      []OrderLineParam `json:"lines" validate:"required,min=1,max=100,dive"`
+}
+
+type OrderLine struct {
+	ID        int         `db:"id_line" json:"lineId"`
+	ProductID int         `db:"id_product" json:"productId"`
+	VariantID int         `db:"id_variant" json:"variantId"`
+	Name      string      `db:"name" json:"name"`
+	SKU       string      `db:"sku" json:"sku"`
+	Quantity  int         `db:"quantity" json:"quantity"`
+	Received  int         `db:"received" json:"received"`
+	UnitCost  money.Money `db:"unit_cost" json:"unitCost"`
+}
+
+// Outstanding is the quantity still to receive.
+func (l OrderLine) Outstanding() int {
+	return l.Quantity - l.Received
+}
+
+// PurchaseOrder is an order of stock from a supplier. Total is the ordered
+// quantity at the ordered cost.
+type PurchaseOrder struct {
+	ID          int            `db:"id_purchase_order" json:"purchaseOrderId"`
+	SupplierID  int            `db:"id_supplier" json:"supplierId"`
+	LocationID  int            `db:"id_location" json:"locationId"`
+	Status      string         `db:"status" json:"status"`
+	Note        string         `db:"note" json:"note"`
+	Total       money.Money    `db:"total" json:"total"`
+	CreatedBy   *int           `db:"created_by" json:"createdBy"`
+	SentBy      *int           `db:"sent_by" json:"sentBy"`
+	SentAt      *time.Time     `db:"sent_at" json:"sentAt"`
+	CancelledBy *int           `db:"cancelled_by" json:"cancelledBy"`
+	CancelledAt *time.Time     `db:"cancelled_at" json:"cancelledAt"`
+	CreatedAt   time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at" json:"updated_at"`
+	Lines       []OrderLine    `db:"-" json:"lines,omitempty"`
+	Receipts    []GoodsReceipt `db:"-" json:"receipts,omitempty"`
+}
+
+// ReceiveItemParam receives Quantity of a line. UnitCost is what was
+// actually paid, the ordered cost when left out.
+type ReceiveItemParam struct {
+	LineID   int          `json:"lineId" validate:"required,min=1"`
+	Quantity int          `json:"quantity" validate:"required,min=1,max=100000"`
+	UnitCost *money.Money `json:"unitCost" validate:"omitempty,min=0"`
+}
+
+// ReceiveParam records a delivery. Without items, everything still
+// outstanding is received at the ordered cost.
+type ReceiveParam struct {
+	Note  string             `json:"note" validate:"max=200"`
+	Items []ReceiveItemParam `json:"items" validate:"max=100,dive"`
+}
+
+type GoodsReceiptItem struct {
+	ID        int         `db:"id_item" json:"itemId"`
+	LineID    int         `db:"id_line" json:"lineId"`
+	ProductID int         `db:"id_product" json:"productId"`
+	VariantID int         `db:"id_variant" json:"variantId"`
+	Quantity  int         `db:"quantity" json:"quantity"`
+	UnitCost  money.Money `db:"unit_cost" json:"unitCost"`
+}
+
+// GoodsReceipt is one delivery booked into stock.
+type GoodsReceipt struct {
+	ID              int                `db:"id_receipt" json:"receiptId"`
+	PurchaseOrderID int                `db:"id_purchase_order" json:"purchaseOrderId"`
+	LocationID      int                `db:"id_location" json:"locationId"`
+	Note            string             `db:"note" json:"note"`
+	ReceivedBy      *int               `db:"received_by" json:"receivedBy"`
+	CreatedAt       time.Time          `db:"created_at" json:"created_at"`
+	Items           []GoodsReceiptItem `db:"-" json:"items"`
+}
+
+// OrderFilter narrows down the purchase order list.
+type OrderFilter struct {
+	Status     string
+	SupplierID int
+	Limit      int
+	Offset     int
+}
+
+// Suggestion is a product at or below its reorder point once the stock
+// already on order is counted. Quantity brings it back above the reorder
+// point, and at least its reorder quantity. VariantID is nil for products
+// with several variants, which have to be ordered per variant by hand.
+// SupplierID and UnitCost come from the last order of the product.
+type Suggestion struct {
+	ProductID       int          `db:"id_product" json:"productId"`
+	VariantID       *int         `db:"id_variant" json:"variantId"`
+	Name            string       `db:"name" json:"name"`
+	SKU             string       `db:"sku" json:"sku"`
+	Stock           int          `db:"stock" json:"stock"`
+	OnOrder         int          `db:"on_order" json:"onOrder"`
+	ReorderPoint    int          `db:"reorder_point" json:"reorderPoint"`
+	ReorderQuantity int          `db:"reorder_quantity" json:"reorderQuantity"`
+	Quantity        int          `db:"quantity" json:"quantity"`
+	SupplierID      *int         `db:"id_supplier" json:"supplierId"`
+	UnitCost        *money.Money `db:"unit_cost" json:"unitCost"`
+}
+
+// SuggestionFilter pages through the suggestions, only those last ordered
+// from SupplierID when set.
+type SuggestionFilter struct {
+	SupplierID int
+	Limit      int
+	Offset     int
+}
+
+// GenerateParam drafts a purchase order from the suggestions for a
+// supplier.
+type GenerateParam struct {
+	SupplierID int    `json:"supplierId" validate:"required,min=1"`
+	LocationID int    `json:"locationId" validate:"omitempty,min=1"`
+	Note       string `json:"note" validate:"max=200"`
+}
+
+func IsOrderStatus(status string) bool {
+	switch status {
+	case OrderDraft, OrderSent, OrderPartiallyReceived, OrderReceived, OrderCancelled:
+		return true
+	}
+	return false
+}
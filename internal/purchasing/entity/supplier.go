@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Supplier is who stock is bought from. LeadTimeDays is how long its
+// deliveries usually take.
+type Supplier struct {
+	ID           int          `db:"id_supplier" json:"supplierId"`
+	Name         string       `db:"name" json:"name"`
+	ContactName  string       `db:"contact_name" json:"contactName"`
+	Email        string       `db:"email" json:"email"`
+	PhoneNumber  string       `db:"phone_number" json:"phoneNumber"`
+	Address      string       `db:"address" json:"address"`
+	LeadTimeDays int          `db:"lead_time_days" json:"leadTimeDays"`
+	IsActive     bool         `db:"is_active" json:"isActive"`
+	CreatedAt    time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt    sql.NullTime `db:"updated_at" json:"updated_at"`
+}
+
+// SupplierParam creates or updates a supplier; IsActive defaults to true.
+type SupplierParam struct {
+	Name         string `json:"name" validate:"required,min=1,max=100"`
+	ContactName  string `json:"contactName" validate:"max=100"`
+	Email        string `json:"email" validate:"omitempty,email,max=100"`
+	PhoneNumber  string `json:"phoneNumber" validate:"max=20"`
+	Address      string `json:"address" validate:"max=200"`
+	LeadTimeDays int    `json:"leadTimeDays" validate:"min=0,max=365"`
+	IsActive     *bool  `json:"isActive"`
+}
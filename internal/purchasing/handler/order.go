@@ -0,0 +1,277 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"projectsphere/eniqlo-store/internal/purchasing/entity"
+	"projectsphere/eniqlo-store/internal/purchasing/service"
+	"projectsphere/eniqlo-store/pkg/middleware/auth"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"projectsphere/eniqlo-store/pkg/validator"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+type OrderHandler struct {
+	orderSvc service.OrderService
+}
+
+func NewOrderHandler(orderSvc service.OrderService) OrderHandler {
+	return OrderHandler{
+		orderSvc: orderSvc,
+	}
+}
+
+// List returns purchase orders, newest first, e.g.
+// ?status=sent&supplierId=2&limit=20&offset=0.
+func (h OrderHandler) List(c *gin.Context) {
+	filter := entity.OrderFilter{Status: c.Query("status")}
+	if filter.Status != "" && !entity.IsOrderStatus(filter.Status) {
+		c.Error(msg.BadRequest(msg.ErrInvalidFilterRequest))
+		return
+	}
+
+	var err error
+	if filter.SupplierID, err = idQuery(c, "supplierId"); err != nil {
+		c.Error(err)
+		return
+	}
+	if filter.Limit, filter.Offset, err = pageParams(c); err != nil {
+		c.Error(err)
+		return
+	}
+
+	orders, err := h.orderSvc.List(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetAllResponse), orders))
+}
+
+// Get returns a purchase order with its lines and goods receipts.
+func (h OrderHandler) Get(c *gin.Context) {
+	orderID, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	order, err := h.orderSvc.Get(c.Request.Context(), orderID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetByIDResponse), order))
+}
+
+// Create drafts a purchase order.
+func (h OrderHandler) Create(c *gin.Context) {
+	payload := new(entity.OrderParam)
+	err := c.ShouldBindJSON(payload)
+	if err != nil {
+		c.Error(validator.BindError(err))
+		return
+	}
+
+	userID, err := auth.GetUserIdInsideCtx(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	order, err := h.orderSvc.Create(c.Request.Context(), *payload, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, msg.ReturnResult(msg.T(c.Request.Context(), msg.PurchaseOrderCreatedResponse), order))
+}
+
+// Update replaces a draft purchase order.
+func (h OrderHandler) Update(c *gin.Context) {
+	orderID, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	payload := new(entity.OrderParam)
+	err = c.ShouldBindJSON(payload)
+	if err != nil {
+		c.Error(validator.BindError(err))
+		return
+	}
+
+	order, err := h.orderSvc.Update(c.Request.Context(), orderID, *payload)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.UpdateResponse), order))
+}
+
+// Send marks a draft as sent to the supplier.
+func (h OrderHandler) Send(c *gin.Context) {
+	h.change(c, h.orderSvc.Send, msg.PurchaseOrderSentResponse)
+}
+
+// Cancel cancels what is still outstanding on a purchase order.
+func (h OrderHandler) Cancel(c *gin.Context) {
+	h.change(c, h.orderSvc.Cancel, msg.PurchaseOrderCancelledResponse)
+}
+
+func (h OrderHandler) change(c *gin.Context, change func(context.Context, int, uint32) (entity.PurchaseOrder, error), message string) {
+	orderID, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	userID, err := auth.GetUserIdInsideCtx(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	order, err := change(c.Request.Context(), orderID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), message), order))
+}
+
+// Receive books a delivery into stock, e.g.
+// {"items": [{"lineId": 4, "quantity": 10, "unitCost": 12500}]}. An empty
+// body receives everything outstanding.
+func (h OrderHandler) Receive(c *gin.Context) {
+	orderID, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	payload := new(entity.ReceiveParam)
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(payload); err != nil {
+			c.Error(validator.BindError(err))
+			return
+		}
+	}
+
+	userID, err := auth.GetUserIdInsideCtx(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	order, err := h.orderSvc.Receive(c.Request.Context(), orderID, *payload, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GoodsReceivedResponse), order))
+}
+
+// Suggestions lists the products that need reordering, e.g.
+// ?supplierId=2&limit=20&offset=0.
+func (h OrderHandler) Suggestions(c *gin.Context) {
+	var filter entity.SuggestionFilter
+	var err error
+	if filter.SupplierID, err = idQuery(c, "supplierId"); err != nil {
+		c.Error(err)
+		return
+	}
+	if filter.Limit, filter.Offset, err = pageParams(c); err != nil {
+		c.Error(err)
+		return
+	}
+
+	suggestions, err := h.orderSvc.Suggestions(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetAllResponse), suggestions))
+}
+
+// Generate drafts a purchase order from the suggestions for a supplier.
+func (h OrderHandler) Generate(c *gin.Context) {
+	payload := new(entity.GenerateParam)
+	err := c.ShouldBindJSON(payload)
+	if err != nil {
+		c.Error(validator.BindError(err))
+		return
+	}
+
+	userID, err := auth.GetUserIdInsideCtx(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	order, err := h.orderSvc.Generate(c.Request.Context(), *payload, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, msg.ReturnResult(msg.T(c.Request.Context(), msg.PurchaseOrderCreatedResponse), order))
+}
+
+// pageParams reads ?limit=20&offset=0.
+func pageParams(c *gin.Context) (limit, offset int, err error) {
+	limit = defaultPageLimit
+	if raw := c.Query("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil {
+			return 0, 0, msg.BadRequest(msg.ErrLimitNotNumber)
+		}
+		if limit < 1 || limit > maxPageLimit {
+			return 0, 0, msg.BadRequest(msg.ErrLimitMustBetween0Until100)
+		}
+	}
+	if raw := c.Query("offset"); raw != "" {
+		offset, err = strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return 0, 0, msg.BadRequest(msg.ErrInvalidFilterRequest)
+		}
+	}
+	return limit, offset, nil
+}
+
+// idQuery reads an optional positive id query parameter, zero when absent.
+func idQuery(c *gin.Context, key string) (int, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return 0, nil
+	}
+	id, err := strconv.Atoi(raw)
+	if err != nil || id <= 0 {
+		return 0, msg.BadRequest(msg.ErrConvertIdToInt)
+	}
+	return id, nil
+}
+
+// pathID reads a positive id path parameter.
+func pathID(c *gin.Context, key string) (int, error) {
+	id, err := strconv.Atoi(c.Param(key))
+	if err != nil || id <= 0 {
+		return 0, msg.BadRequest(msg.ErrConvertIdToInt)
+	}
+	return id, nil
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"net/http"
+	"projectsphere/eniqlo-store/internal/purchasing/entity"
+	"projectsphere/eniqlo-store/internal/purchasing/service"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"projectsphere/eniqlo-store/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SupplierHandler struct {
+	supplierSvc service.SupplierService
+}
+
+func NewSupplierHandler(supplierSvc service.SupplierService) SupplierHandler {
+	return SupplierHandler{
+		supplierSvc: supplierSvc,
+	}
+}
+
+func (h SupplierHandler) List(c *gin.Context) {
+	suppliers, err := h.supplierSvc.List(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetAllResponse), suppliers))
+}
+
+func (h SupplierHandler) Get(c *gin.Context) {
+	supplierID, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	supplier, err := h.supplierSvc.Get(c.Request.Context(), supplierID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetByIDResponse), supplier))
+}
+
+func (h SupplierHandler) Create(c *gin.Context) {
+	payload := new(entity.SupplierParam)
+	err := c.ShouldBindJSON(payload)
+	if err != nil {
+		c.Error(validator.BindError(err))
+		return
+	}
+
+	supplier, err := h.supplierSvc.Create(c.Request.Context(), *payload)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, msg.ReturnResult(msg.T(c.Request.Context(), msg.CreateResponse), supplier))
+}
+
+func (h SupplierHandler) Update(c *gin.Context) {
+	supplierID, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	payload := new(entity.SupplierParam)
+	err = c.ShouldBindJSON(payload)
+	if err != nil {
+		c.Error(validator.BindError(err))
+		return
+	}
+
+	supplier, err := h.supplierSvc.Update(c.Request.Context(), supplierID, *payload)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.UpdateResponse), supplier))
+}
@@ -0,0 +1,638 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	inventoryEntity "projectsphere/eniqlo-store/internal/inventory/entity"
+	inventoryRepository "projectsphere/eniqlo-store/internal/inventory/repository"
+	"projectsphere/eniqlo-store/internal/purchasing/entity"
+	"projectsphere/eniqlo-store/pkg/database"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type OrderRepo struct {
+	dbConnector database.PostgresConnector
+}
+
+func NewOrderRepo(dbConnector database.PostgresConnector) OrderRepo {
+	return OrderRepo{
+		dbConnector: dbConnector,
+	}
+}
+
+const orderColumns = `o.id_purchase_order, o.id_supplier, o.id_location, o.status, o.note,
+            (SELECT COALESCE(SUM(l.quantity * l.unit_cost), 0) FROM "purchase_order_lines" l WHERE l.id_purchase_order = o.id_purchase_order) AS total,
+            o.created_by, o.sent_by, o.sent_at, o.cancelled_by, o.cancelled_at, o.created_at, o.updated_at`
+
+func (r OrderRepo) CreatePurchaseOrder(ctx context.Context, param entity.OrderParam, userID uint32) (entity.PurchaseOrder, error) {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return entity.PurchaseOrder{}, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	orderID, err := createOrder(ctx, tx, param, userID)
+	if err != nil {
+		return entity.PurchaseOrder{}, err
+	}
+	return commitOrder(ctx, tx, orderID)
+}
+
+// UpdatePurchaseOrder replaces a draft with param.
+func (r OrderRepo) UpdatePurchaseOrder(ctx context.Context, orderID int, param entity.OrderParam) (entity.PurchaseOrder, error) {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return entity.PurchaseOrder{}, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	order, err := getOrder(ctx, tx, orderID, "FOR UPDATE OF o")
+	if err != nil {
+		return entity.PurchaseOrder{}, err
+	}
+	if order.Status != entity.OrderDraft {
+		return entity.PurchaseOrder{}, msg.Conflict(msg.ErrPurchaseOrderNotDraft)
+	}
+
+	if err := activeSupplier(ctx, tx, param.SupplierID, "FOR SHARE"); err != nil {
+		return entity.PurchaseOrder{}, err
+	}
+	locationID, err := inventoryRepository.ResolveLocation(ctx, tx, param.LocationID)
+	if err != nil {
+		return entity.PurchaseOrder{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE "purchase_orders"
+        SET id_supplier = $2, id_location = $3, note = $4, updated_at = CURRENT_TIMESTAMP
+        WHERE id_purchase_order = $1
+    `, orderID, param.SupplierID, locationID, param.Note)
+	if err != nil {
+		return entity.PurchaseOrder{}, msg.InternalServerError(err.Error())
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM "purchase_order_lines" WHERE id_purchase_order = $1`, orderID); err != nil {
+		return entity.PurchaseOrder{}, msg.InternalServerError(err.Error())
+	}
+	if err := insertLines(ctx, tx, orderID, param.Lines); err != nil {
+		return entity.PurchaseOrder{}, err
+	}
+
+	return commitOrder(ctx, tx, orderID)
+}
+
+// SendPurchaseOrder marks a draft as sent to the supplier, after which it
+// can be received but no longer changed.
+func (r OrderRepo) SendPurchaseOrder(ctx context.Context, orderID int, userID uint32) (entity.PurchaseOrder, error) {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return entity.PurchaseOrder{}, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	order, err := getOrder(ctx, tx, orderID, "FOR UPDATE OF o")
+	if err != nil {
+		return entity.PurchaseOrder{}, err
+	}
+	if order.Status != entity.OrderDraft {
+		return entity.PurchaseOrder{}, msg.Conflict(msg.ErrPurchaseOrderNotDraft)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE "purchase_orders"
+        SET status = 'sent', sent_by = $2, sent_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+        WHERE id_purchase_order = $1
+    `, orderID, inventoryEntity.StaffID(userID))
+	if err != nil {
+		return entity.PurchaseOrder{}, msg.InternalServerError(err.Error())
+	}
+
+	return commitOrder(ctx, tx, orderID)
+}
+
+// CancelPurchaseOrder cancels what is still outstanding on an order. Stock
+// already received stays.
+func (r OrderRepo) CancelPurchaseOrder(ctx context.Context, orderID int, userID uint32) (entity.PurchaseOrder, error) {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return entity.PurchaseOrder{}, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	order, err := getOrder(ctx, tx, orderID, "FOR UPDATE OF o")
+	if err != nil {
+		return entity.PurchaseOrder{}, err
+	}
+	if order.Status == entity.OrderReceived || order.Status == entity.OrderCancelled {
+		return entity.PurchaseOrder{}, msg.Conflict(msg.ErrPurchaseOrderClosed)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE "purchase_orders"
+        SET status = 'cancelled', cancelled_by = $2, cancelled_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+        WHERE id_purchase_order = $1
+    `, orderID, inventoryEntity.StaffID(userID))
+	if err != nil {
+		return entity.PurchaseOrder{}, msg.InternalServerError(err.Error())
+	}
+
+	return commitOrder(ctx, tx, orderID)
+}
+
+// ReceivePurchaseOrder books a delivery into the order's location as
+// receiving movements and records it as a goods receipt with the cost
+// paid. Each product's cost becomes the weighted average of its stock on
+// hand and the delivery, or the cost paid while its cost is unknown. The
+// order is received once every line is.
+func (r OrderRepo) ReceivePurchaseOrder(ctx context.Context, orderID int, param entity.ReceiveParam, userID uint32) (entity.PurchaseOrder, error) {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return entity.PurchaseOrder{}, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	order, err := getOrder(ctx, tx, orderID, "FOR UPDATE OF o")
+	if err != nil {
+		return entity.PurchaseOrder{}, err
+	}
+	if order.Status != entity.OrderSent && order.Status != entity.OrderPartiallyReceived {
+		return entity.PurchaseOrder{}, msg.Conflict(msg.ErrPurchaseOrderNotOpen)
+	}
+
+	lines, err := orderLines(ctx, tx, orderID)
+	if err != nil {
+		return entity.PurchaseOrder{}, err
+	}
+	items, err := receiptItems(lines, param.Items)
+	if err != nil {
+		return entity.PurchaseOrder{}, err
+	}
+
+	productIDs := make([]int, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	_, err = tx.ExecContext(ctx, `SELECT id_product FROM "products" WHERE id_product = ANY($1) ORDER BY id_product FOR UPDATE`, pq.Array(productIDs))
+	if err != nil {
+		return entity.PurchaseOrder{}, msg.InternalServerError(err.Error())
+	}
+
+	var receiptID int
+	err = tx.GetContext(ctx, &receiptID, `
+        INSERT INTO "goods_receipts" (id_purchase_order, id_location, note, received_by)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id_receipt
+    `, orderID, order.LocationID, param.Note, inventoryEntity.StaffID(userID))
+	if err != nil {
+		return entity.PurchaseOrder{}, msg.InternalServerError(err.Error())
+	}
+
+	for _, item := range items {
+		_, err = tx.ExecContext(ctx, `
+            INSERT INTO "goods_receipt_items" (id_receipt, id_line, id_product, id_variant, quantity, unit_cost)
+            VALUES ($1, $2, $3, $4, $5, $6)
+        `, receiptID, item.LineID, item.ProductID, item.VariantID, item.Quantity, item.UnitCost)
+		if err != nil {
+			return entity.PurchaseOrder{}, msg.InternalServerError(err.Error())
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE "purchase_order_lines" SET received = received + $2 WHERE id_line = $1`, item.LineID, item.Quantity)
+		if err != nil {
+			return entity.PurchaseOrder{}, msg.InternalServerError(err.Error())
+		}
+
+		// the stock is read before the movement adds to it
+		_, err = tx.ExecContext(ctx, `
+            UPDATE "products"
+            SET cost = CASE
+                    WHEN cost = 0 OR stock <= 0 THEN $3::numeric
+                    ELSE ROUND((stock * cost + $2 * $3::numeric) / (stock + $2), 2)
+                END
+            WHERE id_product = $1
+        `, item.ProductID, item.Quantity, item.UnitCost)
+		if err != nil {
+			return entity.PurchaseOrder{}, msg.InternalServerError(err.Error())
+		}
+
+		_, err = inventoryRepository.RecordMovement(ctx, tx, inventoryEntity.StockMovement{
+			ProductID:  item.ProductID,
+			VariantID:  item.VariantID,
+			LocationID: order.LocationID,
+			Type:       inventoryEntity.MovementReceiving,
+			Quantity:   item.Quantity,
+			Reference:  fmt.Sprintf("po:%d", orderID),
+			UserID:     inventoryEntity.StaffID(userID),
+		})
+		if err != nil {
+			return entity.PurchaseOrder{}, err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE "products"
+        SET version = version + 1
+        WHERE id_product = ANY($1)
+    `, pq.Array(productIDs))
+	if err != nil {
+		return entity.PurchaseOrder{}, msg.InternalServerError(err.Error())
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE "purchase_orders"
+        SET status = CASE
+                WHEN EXISTS (SELECT 1 FROM "purchase_order_lines" WHERE id_purchase_order = $1 AND received < quantity) THEN 'partially_received'
+                ELSE 'received'
+            END,
+            updated_at = CURRENT_TIMESTAMP
+        WHERE id_purchase_order = $1
+    `, orderID)
+	if err != nil {
+		return entity.PurchaseOrder{}, msg.InternalServerError(err.Error())
+	}
+
+	return commitOrder(ctx, tx, orderID)
+}
+
+// receiptItems turns the received items into receipt items, checking that
+// no line receives more than is outstanding. Without items everything
+// outstanding is received at the ordered cost.
+func receiptItems(lines []entity.OrderLine, params []entity.ReceiveItemParam) ([]entity.GoodsReceiptItem, error) {
+	items := make([]entity.GoodsReceiptItem, 0, len(lines))
+	if len(params) == 0 {
+		for _, line := range lines {
+			if line.Outstanding() > 0 {
+				items = append(items, entity.GoodsReceiptItem{
+					LineID:    line.ID,
+					ProductID: line.ProductID,
+					VariantID: line.VariantID,
+					Quantity:  line.Outstanding(),
+					UnitCost:  line.UnitCost,
+				})
+			}
+		}
+		return items, nil
+	}
+
+	byID := make(map[int]entity.OrderLine, len(lines))
+	for _, line := range lines {
+		byID[line.ID] = line
+	}
+
+	received := make(map[int]int, len(params))
+	var details []msg.FieldError
+	for i, param := range params {
+		line, ok := byID[param.LineID]
+		if !ok {
+			field := fmt.Sprintf("items[%d].lineId", i)
+			details = append(details, msg.NewFieldError(field, "purchase_order", msg.ValNotInPurchaseOrder, field))
+			continue
+		}
+
+		received[line.ID] += param.Quantity
+		if received[line.ID] > line.Outstanding() {
+			field := fmt.Sprintf("items[%d].quantity", i)
+			outstanding := strconv.Itoa(line.Outstanding())
+			details = append(details, msg.NewFieldError(field, "max", msg.ValOverReceived, field, outstanding))
+			continue
+		}
+
+		item := entity.GoodsReceiptItem{
+			LineID:    line.ID,
+			ProductID: line.ProductID,
+			VariantID: line.VariantID,
+			Quantity:  param.Quantity,
+			UnitCost:  line.UnitCost,
+		}
+		if param.UnitCost != nil {
+			item.UnitCost = *param.UnitCost
+		}
+		items = append(items, item)
+	}
+	if len(details) > 0 {
+		return nil, msg.Validation(details...)
+	}
+	return items, nil
+}
+
+func (r OrderRepo) GetPurchaseOrder(ctx context.Context, orderID int) (entity.PurchaseOrder, error) {
+	return fullOrder(ctx, r.dbConnector.DB, orderID)
+}
+
+// ListPurchaseOrders returns a page of purchase orders, newest first,
+// without their lines.
+func (r OrderRepo) ListPurchaseOrders(ctx context.Context, filter entity.OrderFilter) ([]entity.PurchaseOrder, error) {
+	conditions := "TRUE"
+	var args []interface{}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions += fmt.Sprintf(" AND o.status = $%d", len(args))
+	}
+	if filter.SupplierID != 0 {
+		args = append(args, filter.SupplierID)
+		conditions += fmt.Sprintf(" AND o.id_supplier = $%d", len(args))
+	}
+
+	orders := []entity.PurchaseOrder{}
+	err := r.dbConnector.DB.SelectContext(ctx, &orders, fmt.Sprintf(`
+        SELECT `+orderColumns+`
+        FROM "purchase_orders" o
+        WHERE %s
+        ORDER BY o.id_purchase_order DESC
+        LIMIT %d OFFSET %d
+    `, conditions, filter.Limit, filter.Offset), args...)
+	if err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+	return orders, nil
+}
+
+// Suggestions returns a page of the products that need reordering.
+func (r OrderRepo) Suggestions(ctx context.Context, filter entity.SuggestionFilter) ([]entity.Suggestion, error) {
+	return suggestions(ctx, r.dbConnector.DB, filter, false)
+}
+
+// GeneratePurchaseOrder drafts a purchase order with the suggestions last
+// ordered from a supplier, at the cost last paid. Generating is serialised
+// per supplier, so the stock a draft puts on order keeps the next one from
+// ordering it again.
+func (r OrderRepo) GeneratePurchaseOrder(ctx context.Context, param entity.GenerateParam, userID uint32) (entity.PurchaseOrder, error) {
+	tx, err := r.dbConnector.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return entity.PurchaseOrder{}, msg.InternalServerError(err.Error())
+	}
+	defer tx.Rollback()
+
+	if err := activeSupplier(ctx, tx, param.SupplierID, "FOR UPDATE"); err != nil {
+		return entity.PurchaseOrder{}, err
+	}
+
+	filter := entity.SuggestionFilter{SupplierID: param.SupplierID, Limit: entity.MaxOrderLines}
+	suggested, err := suggestions(ctx, tx, filter, true)
+	if err != nil {
+		return entity.PurchaseOrder{}, err
+	}
+	if len(suggested) == 0 {
+		return entity.PurchaseOrder{}, msg.Conflict(msg.ErrNothingToReorder)
+	}
+
+	orderParam := entity.OrderParam{
+		SupplierID: param.SupplierID,
+		LocationID: param.LocationID,
+		Note:       param.Note,
+		Lines:      make([]entity.OrderLineParam, 0, len(suggested)),
+	}
+	for _, suggestion := range suggested {
+		orderParam.Lines = append(orderParam.Lines, entity.OrderLineParam{
+			ProductID: suggestion.ProductID,
+			VariantID: *suggestion.VariantID,
+			Quantity:  suggestion.Quantity,
+			UnitCost:  *suggestion.UnitCost,
+		})
+	}
+
+	orderID, err := createOrder(ctx, tx, orderParam, userID)
+	if err != nil {
+		return entity.PurchaseOrder{}, err
+	}
+	return commitOrder(ctx, tx, orderID)
+}
+
+// suggestions finds the products whose stock plus what is on order is at
+// or below their reorder point. On order counts the outstanding lines of
+// drafts too, so a draft is not suggested twice. With orderable, products
+// with several variants are left out.
+func suggestions(ctx context.Context, db sqlx.QueryerContext, filter entity.SuggestionFilter, orderable bool) ([]entity.Suggestion, error) {
+	conditions := ""
+	if orderable {
+		conditions = " AND v.variants = 1"
+	}
+
+	suggested := []entity.Suggestion{}
+	err := sqlx.SelectContext(ctx, db, &suggested, fmt.Sprintf(`
+        SELECT p.id_product, CASE WHEN v.variants = 1 THEN v.id_variant END AS id_variant, p.name, p.sku, p.stock,
+            COALESCE(ordered.quantity, 0) AS on_order, p.reorder_point, p.reorder_quantity,
+            GREATEST(p.reorder_quantity, p.reorder_point + 1 - p.stock - COALESCE(ordered.quantity, 0)) AS quantity,
+            last.id_supplier, last.unit_cost
+        FROM "products" p
+        JOIN (
+            SELECT id_product, COUNT(*) AS variants, MIN(id_variant) AS id_variant
+            FROM "product_variants"
+            GROUP BY id_product
+        ) v ON v.id_product = p.id_product
+        LEFT JOIN (
+            SELECT l.id_product, SUM(l.quantity - l.received) AS quantity
+            FROM "purchase_order_lines" l
+            JOIN "purchase_orders" o ON o.id_purchase_order = l.id_purchase_order
+            WHERE o.status IN ('draft', 'sent', 'partially_received')
+            GROUP BY l.id_product
+        ) ordered ON ordered.id_product = p.id_product
+        LEFT JOIN LATERAL (
+            SELECT o.id_supplier, l.unit_cost
+            FROM "purchase_order_lines" l
+            JOIN "purchase_orders" o ON o.id_purchase_order = l.id_purchase_order
+            WHERE l.id_product = p.id_product AND o.sent_at IS NOT NULL
+            ORDER BY l.id_line DESC
+            LIMIT 1
+        ) last ON TRUE
+        WHERE p.reorder_point > 0 AND p.stock + COALESCE(ordered.quantity, 0) <= p.reorder_point
+            AND ($1 = 0 OR last.id_supplier = $1)%s
+        ORDER BY p.name, p.id_product
+        LIMIT %d OFFSET %d
+    `, conditions, filter.Limit, filter.Offset), filter.SupplierID)
+	if err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+	return suggested, nil
+}
+
+// createOrder inserts a draft with its lines and returns its id.
+func createOrder(ctx context.Context, tx *sqlx.Tx, param entity.OrderParam, userID uint32) (int, error) {
+	if err := activeSupplier(ctx, tx, param.SupplierID, "FOR SHARE"); err != nil {
+		return 0, err
+	}
+	locationID, err := inventoryRepository.ResolveLocation(ctx, tx, param.LocationID)
+	if err != nil {
+		return 0, err
+	}
+
+	var orderID int
+	err = tx.GetContext(ctx, &orderID, `
+        INSERT INTO "purchase_orders" (id_supplier, id_location, note, created_by)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id_purchase_order
+    `, param.SupplierID, locationID, param.Note, inventoryEntity.StaffID(userID))
+	if err != nil {
+		return 0, msg.InternalServerError(err.Error())
+	}
+
+	if err := insertLines(ctx, tx, orderID, param.Lines); err != nil {
+		return 0, err
+	}
+	return orderID, nil
+}
+
+func insertLines(ctx context.Context, tx *sqlx.Tx, orderID int, lines []entity.OrderLineParam) error {
+	for i, line := range lines {
+		variantID, err := resolveVariant(ctx, tx, line.ProductID, line.VariantID, i)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+            INSERT INTO "purchase_order_lines" (id_purchase_order, id_product, id_variant, quantity, unit_cost)
+            VALUES ($1, $2, $3, $4, $5)
+        `, orderID, line.ProductID, variantID, line.Quantity, line.UnitCost)
+		if err != nil {
+			if strings.Contains(err.Error(), "purchase_order_lines_id_purchase_order_id_variant_key") {
+				field := fmt.Sprintf("lines[%d].variantId", i)
+				return msg.Validation(msg.NewFieldError(field, "unique", msg.ValDuplicate, field))
+			}
+			return msg.InternalServerError(err.Error())
+		}
+	}
+	return nil
+}
+
+// resolveVariant returns variantID after checking it belongs to the
+// product, or the product's only variant when variantID is zero.
+func resolveVariant(ctx context.Context, tx *sqlx.Tx, productID, variantID, line int) (int, error) {
+	var variantIDs []int
+	err := tx.SelectContext(ctx, &variantIDs, `
+        SELECT id_variant
+        FROM "product_variants"
+        WHERE id_product = $1 AND ($2 = 0 OR id_variant = $2)
+    `, productID, variantID)
+	if err != nil {
+		return 0, msg.InternalServerError(err.Error())
+	}
+
+	switch {
+	case len(variantIDs) == 1:
+		return variantIDs[0], nil
+	case variantID != 0:
+		return 0, msg.NotFound(msg.ErrVariantNotFound)
+	case len(variantIDs) == 0:
+		return 0, msg.NotFound(msg.ErrProductNotFound)
+	default:
+		field := fmt.Sprintf("lines[%d].variantId", line)
+		return 0, msg.Validation(msg.NewFieldError(field, "required", msg.ValVariantRequired, field))
+	}
+}
+
+// activeSupplier locks a supplier with lock and checks it is active.
+func activeSupplier(ctx context.Context, tx *sqlx.Tx, supplierID int, lock string) error {
+	var active bool
+	err := tx.GetContext(ctx, &active, `SELECT is_active FROM "suppliers" WHERE id_supplier = $1 `+lock, supplierID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return msg.NotFound(msg.ErrSupplierNotFound)
+		}
+		return msg.InternalServerError(err.Error())
+	}
+	if !active {
+		return msg.Conflict(msg.ErrSupplierInactive)
+	}
+	return nil
+}
+
+// commitOrder reads back the order changed in tx and commits.
+func commitOrder(ctx context.Context, tx *sqlx.Tx, orderID int) (entity.PurchaseOrder, error) {
+	order, err := fullOrder(ctx, tx, orderID)
+	if err != nil {
+		return entity.PurchaseOrder{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.PurchaseOrder{}, msg.InternalServerError(err.Error())
+	}
+	return order, nil
+}
+
+func getOrder(ctx context.Context, db sqlx.QueryerContext, orderID int, lock string) (entity.PurchaseOrder, error) {
+	var order entity.PurchaseOrder
+	err := sqlx.GetContext(ctx, db, &order, `
+        SELECT `+orderColumns+`
+        FROM "purchase_orders" o
+        WHERE o.id_purchase_order = $1
+        `+lock, orderID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return entity.PurchaseOrder{}, msg.NotFound(msg.ErrPurchaseOrderNotFound)
+		}
+		return entity.PurchaseOrder{}, msg.InternalServerError(err.Error())
+	}
+	return order, nil
+}
+
+// fullOrder reads an order with its lines and goods receipts.
+func fullOrder(ctx context.Context, db sqlx.QueryerContext, orderID int) (entity.PurchaseOrder, error) {
+	order, err := getOrder(ctx, db, orderID, "")
+	if err != nil {
+		return entity.PurchaseOrder{}, err
+	}
+
+	if order.Lines, err = orderLines(ctx, db, orderID); err != nil {
+		return entity.PurchaseOrder{}, err
+	}
+
+	order.Receipts = []entity.GoodsReceipt{}
+	err = sqlx.SelectContext(ctx, db, &order.Receipts, `
+        SELECT id_receipt, id_purchase_order, id_location, note, received_by, created_at
+        FROM "goods_receipts"
+        WHERE id_purchase_order = $1
+        ORDER BY id_receipt
+    `, orderID)
+	if err != nil {
+		return entity.PurchaseOrder{}, msg.InternalServerError(err.Error())
+	}
+
+	var items []struct {
+		ReceiptID int `db:"id_receipt"`
+		entity.GoodsReceiptItem
+	}
+	err = sqlx.SelectContext(ctx, db, &items, `
+        SELECT i.id_receipt, i.id_item, i.id_line, i.id_product, i.id_variant, i.quantity, i.unit_cost
+        FROM "goods_receipt_items" i
+        JOIN "goods_receipts" r ON r.id_receipt = i.id_receipt
+        WHERE r.id_purchase_order = $1
+        ORDER BY i.id_item
+    `, orderID)
+	if err != nil {
+		return entity.PurchaseOrder{}, msg.InternalServerError(err.Error())
+	}
+
+	receipts := make(map[int]int, len(order.Receipts))
+	for i := range order.Receipts {
+		order.Receipts[i].Items = []entity.GoodsReceiptItem{}
+		receipts[order.Receipts[i].ID] = i
+	}
+	for _, item := range items {
+		receipt := &order.Receipts[receipts[item.ReceiptID]]
+		receipt.Items = append(receipt.Items, item.GoodsReceiptItem)
+	}
+
+	return order, nil
+}
+
+func orderLines(ctx context.Context, db sqlx.QueryerContext, orderID int) ([]entity.OrderLine, error) {
+	lines := []entity.OrderLine{}
+	err := sqlx.SelectContext(ctx, db, &lines, `
+        SELECT l.id_line, l.id_product, l.id_variant, COALESCE(p.name, '') AS name, COALESCE(v.sku, '') AS sku,
+            l.quantity, l.received, l.unit_cost
+        FROM "purchase_order_lines" l
+        LEFT JOIN "products" p ON p.id_product = l.id_product
+        LEFT JOIN "product_variants" v ON v.id_variant = l.id_variant
+        WHERE l.id_purchase_order = $1
+        ORDER BY l.id_line
+    `, orderID)
+	if err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+	return lines, nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"projectsphere/eniqlo-store/internal/purchasing/entity"
+	"projectsphere/eniqlo-store/pkg/database"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"strings"
+)
+
+type SupplierRepo struct {
+	dbConnector database.PostgresConnector
+}
+
+func NewSupplierRepo(dbConnector database.PostgresConnector) SupplierRepo {
+	return SupplierRepo{
+		dbConnector: dbConnector,
+	}
+}
+
+const supplierColumns = `id_supplier, name, contact_name, email, phone_number, address, lead_time_days, is_active, created_at, updated_at`
+
+func (r SupplierRepo) ListSuppliers(ctx context.Context) ([]entity.Supplier, error) {
+	suppliers := []entity.Supplier{}
+	err := r.dbConnector.DB.SelectContext(ctx, &suppliers, `SELECT `+supplierColumns+` FROM "suppliers" ORDER BY name, id_supplier`)
+	if err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+	return suppliers, nil
+}
+
+func (r SupplierRepo) GetSupplier(ctx context.Context, supplierID int) (entity.Supplier, error) {
+	var supplier entity.Supplier
+	err := r.dbConnector.DB.GetContext(ctx, &supplier, `SELECT `+supplierColumns+` FROM "suppliers" WHERE id_supplier = $1`, supplierID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return entity.Supplier{}, msg.NotFound(msg.ErrSupplierNotFound)
+		}
+		return entity.Supplier{}, msg.InternalServerError(err.Error())
+	}
+	return supplier, nil
+}
+
+func (r SupplierRepo) CreateSupplier(ctx context.Context, param entity.Supplier) (entity.Supplier, error) {
+	var supplier entity.Supplier
+	err := r.dbConnector.DB.GetContext(ctx, &supplier, `
+        INSERT INTO "suppliers" (name, contact_name, email, phone_number, address, lead_time_days, is_active)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING `+supplierColumns,
+		param.Name, param.ContactName, param.Email, param.PhoneNumber, param.Address, param.LeadTimeDays, param.IsActive)
+	if err != nil {
+		return entity.Supplier{}, supplierWriteError(err)
+	}
+	return supplier, nil
+}
+
+func (r SupplierRepo) UpdateSupplier(ctx context.Context, param entity.Supplier) (entity.Supplier, error) {
+	var supplier entity.Supplier
+	err := r.dbConnector.DB.GetContext(ctx, &supplier, `
+        UPDATE "suppliers"
+        SET name = $1, contact_name = $2, email = $3, phone_number = $4, address = $5, lead_time_days = $6, is_active = $7,
+            updated_at = CURRENT_TIMESTAMP
+        WHERE id_supplier = $8
+        RETURNING `+supplierColumns,
+		param.Name, param.ContactName, param.Email, param.PhoneNumber, param.Address, param.LeadTimeDays, param.IsActive, param.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return entity.Supplier{}, msg.NotFound(msg.ErrSupplierNotFound)
+		}
+		return entity.Supplier{}, supplierWriteError(err)
+	}
+	return supplier, nil
+}
+
+func supplierWriteError(err error) error {
+	switch {
+	case strings.Contains(err.Error(), "suppliers_name_key"):
+		return msg.Conflict(msg.ErrSupplierNameExists)
+	default:
+		return msg.InternalServerError(err.Error())
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"projectsphere/eniqlo-store/internal/purchasing/entity"
+	"projectsphere/eniqlo-store/internal/purchasing/repository"
+	"projectsphere/eniqlo-store/pkg/middleware/logger"
+)
+
+type OrderService struct {
+	orderRepo repository.OrderRepo
+}
+
+func NewOrderService(orderRepo repository.OrderRepo) OrderService {
+	return OrderService{
+		orderRepo: orderRepo,
+	}
+}
+
+func (s OrderService) List(ctx context.Context, filter entity.OrderFilter) ([]entity.PurchaseOrder, error) {
+	return s.orderRepo.ListPurchaseOrders(ctx, filter)
+}
+
+func (s OrderService) Get(ctx context.Context, orderID int) (entity.PurchaseOrder, error) {
+	return s.orderRepo.GetPurchaseOrder(ctx, orderID)
+}
+
+func (s OrderService) Create(ctx context.Context, param entity.OrderParam, userID uint32) (entity.PurchaseOrder, error) {
+	order, err := s.orderRepo.CreatePurchaseOrder(ctx, param, userID)
+	if err != nil {
+		return entity.PurchaseOrder{}, err
+	}
+
+	logger.FromContext(ctx).Info().
+		Int("purchaseOrderId", order.ID).
+		Int("supplierId", order.SupplierID).
+		Int("lines", len(order.Lines)).
+		Msg("purchase order created")
+	return order, nil
+}
+
+func (s OrderService) Update(ctx context.Context, orderID int, param entity.OrderParam) (entity.PurchaseOrder, error) {
+	return s.orderRepo.UpdatePurchaseOrder(ctx, orderID, param)
+}
+
+func (s OrderService) Send(ctx context.Context, orderID int, userID uint32) (entity.PurchaseOrder, error) {
+	order, err := s.orderRepo.SendPurchaseOrder(ctx, orderID, userID)
+	if err != nil {
+		return entity.PurchaseOrder{}, err
+	}
+
+	logger.FromContext(ctx).Info().Int("purchaseOrderId", orderID).Msg("purchase order sent")
+	return order, nil
+}
+
+func (s OrderService) Cancel(ctx context.Context, orderID int, userID uint32) (entity.PurchaseOrder, error) {
+	order, err := s.orderRepo.CancelPurchaseOrder(ctx, orderID, userID)
+	if err != nil {
+		return entity.PurchaseOrder{}, err
+	}
+
+	logger.FromContext(ctx).Info().Int("purchaseOrderId", orderID).Msg("purchase order cancelled")
+	return order, nil
+}
+
+func (s OrderService) Receive(ctx context.Context, orderID int, param entity.ReceiveParam, userID uint32) (entity.PurchaseOrder, error) {
+	order, err := s.orderRepo.ReceivePurchaseOrder(ctx, orderID, param, userID)
+	if err != nil {
+		return entity.PurchaseOrder{}, err
+	}
+
+	logger.FromContext(ctx).Info().
+		Int("purchaseOrderId", orderID).
+		Int("receipts", len(order.Receipts)).
+		Str("status", order.Status).
+		Msg("goods received")
+	return order, nil
+}
+
+func (s OrderService) Suggestions(ctx context.Context, filter entity.SuggestionFilter) ([]entity.Suggestion, error) {
+	return s.orderRepo.Suggestions(ctx, filter)
+}
+
+// Generate drafts a purchase order for a supplier from the reorder
+// suggestions.
+func (s OrderService) Generate(ctx context.Context, param entity.GenerateParam, userID uint32) (entity.PurchaseOrder, error) {
+	order, err := s.orderRepo.GeneratePurchaseOrder(ctx, param, userID)
+	if err != nil {
+		return entity.PurchaseOrder{}, err
+	}
+
+	logger.FromContext(ctx).Info().
+		Int("purchaseOrderId", order.ID).
+		Int("supplierId", order.SupplierID).
+		Int("lines", len(order.Lines)).
+		Msg("purchase order generated")
+	return order, nil
+}
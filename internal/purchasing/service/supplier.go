@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"projectsphere/eniqlo-store/internal/purchasing/entity"
+	"projectsphere/eniqlo-store/internal/purchasing/repository"
+)
+
+type SupplierService struct {
+	supplierRepo repository.SupplierRepo
+}
+
+func NewSupplierService(supplierRepo repository.SupplierRepo) SupplierService {
+	return SupplierService{
+		supplierRepo: supplierRepo,
+	}
+}
+
+func (s SupplierService) List(ctx context.Context) ([]entity.Supplier, error) {
+	return s.supplierRepo.ListSuppliers(ctx)
+}
+
+func (s SupplierService) Get(ctx context.Context, supplierID int) (entity.Supplier, error) {
+	return s.supplierRepo.GetSupplier(ctx, supplierID)
+}
+
+func (s SupplierService) Create(ctx context.Context, param entity.SupplierParam) (entity.Supplier, error) {
+	return s.supplierRepo.CreateSupplier(ctx, toSupplier(param))
+}
+
+func (s SupplierService) Update(ctx context.Context, supplierID int, param entity.SupplierParam) (entity.Supplier, error) {
+	supplier := toSupplier(param)
+	supplier.ID = supplierID
+	return s.supplierRepo.UpdateSupplier(ctx, supplier)
+}
+
+func toSupplier(param entity.SupplierParam) entity.Supplier {
+	supplier := entity.Supplier{
+		Name:         param.Name,
+		ContactName:  param.ContactName,
+		Email:        param.Email,
+		PhoneNumber:  param.PhoneNumber,
+		Address:      param.Address,
+		LeadTimeDays: param.LeadTimeDays,
+		IsActive:     true,
+	}
+	if param.IsActive != nil {
+		supplier.IsActive = *param.IsActive
+	}
+	return supplier
+}
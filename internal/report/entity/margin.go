@@ -0,0 +1,109 @@
+package entity
+
+import (
+	"math"
+	"projectsphere/eniqlo-store/pkg/money"
+	"time"
+)
+
+// DateLayout is how report dates are written, e.g. 2024-05-31.
+const DateLayout = "2006-01-02"
+
+// What the margin report groups sales by.
+const (
+	GroupProduct  = "product"
+	GroupCategory = "category"
+	GroupPeriod   = "period"
+)
+
+// Periods of a report grouped by period.
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// MarginFilter selects the sales from From to To, both dates inclusive, of
+// Categories when set.
+type MarginFilter struct {
+	GroupBy    string
+	Period     string
+	From       time.Time
+	To         time.Time
+	Category   string
+	Categories []string
+}
+
+// MarginRow is the gross margin of one group: revenue at the price sold,
+// after discounts, minus the cost the product had at the time. Key is the
+// product id, the category or the start of the period.
+type MarginRow struct {
+	Key           string      `db:"key" json:"key"`
+	Name          string      `db:"name" json:"name"`
+	Quantity      int         `db:"quantity" json:"quantity"`
+	Revenue       money.Money `db:"revenue" json:"revenue"`
+	Cost          money.Money `db:"cost" json:"cost"`
+	Margin        money.Money `db:"-" json:"margin"`
+	MarginPercent float64     `db:"-" json:"marginPercent"`
+}
+
+type MarginReport struct {
+	GroupBy string      `json:"groupBy"`
+	Period  string      `json:"period,omitempty"`
+	From    string      `json:"from"`
+	To      string      `json:"to"`
+	Rows    []MarginRow `json:"rows"`
+	Total   MarginRow   `json:"total"`
+}
+
+// NewMarginReport fills in the margins of rows and their total.
+func NewMarginReport(filter MarginFilter, rows []MarginRow) MarginReport {
+	report := MarginReport{
+		GroupBy: filter.GroupBy,
+		From:    filter.From.Format(DateLayout),
+		To:      filter.To.Format(DateLayout),
+		Rows:    rows,
+		Total:   MarginRow{Key: "total", Name: "Total"},
+	}
+	if filter.GroupBy == GroupPeriod {
+		report.Period = filter.Period
+	}
+
+	revenues := make([]money.Money, 0, len(rows))
+	costs := make([]money.Money, 0, len(rows))
+	for i := range report.Rows {
+		report.Rows[i].margin()
+		report.Total.Quantity += report.Rows[i].Quantity
+		revenues = append(revenues, report.Rows[i].Revenue)
+		costs = append(costs, report.Rows[i].Cost)
+	}
+	report.Total.Revenue = money.Sum(revenues...)
+	report.Total.Cost = money.Sum(costs...)
+	report.Total.margin()
+	return report
+}
+
+func (r *MarginRow) margin() {
+	r.Margin = r.Revenue.Sub(r.Cost)
+	r.MarginPercent = 0
+	if !r.Revenue.IsZero() {
+		percent := r.Margin.Float64() / r.Revenue.Float64() * 100
+		r.MarginPercent = math.Round(percent*100) / 100
+	}
+}
+
+func IsGroup(group string) bool {
+	switch group {
+	case GroupProduct, GroupCategory, GroupPeriod:
+		return true
+	}
+	return false
+}
+
+func IsPeriod(period string) bool {
+	switch period {
+	case PeriodDay, PeriodWeek, PeriodMonth:
+		return true
+	}
+	return false
+}
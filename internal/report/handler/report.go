@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"projectsphere/eniqlo-store/internal/report/entity"
+	"projectsphere/eniqlo-store/internal/report/service"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultReportDays is the range reported when from is left out.
+const defaultReportDays = 30
+
+type ReportHandler struct {
+	reportSvc service.ReportService
+}
+
+func NewReportHandler(reportSvc service.ReportService) ReportHandler {
+	return ReportHandler{
+		reportSvc: reportSvc,
+	}
+}
+
+// Margin reports gross margin by product, category or period, e.g.
+// ?groupBy=period&period=week&from=2024-05-01&to=2024-05-31&category=footwear.
+// to defaults to today and from to 30 days before it. format=csv returns
+// the report as a CSV download.
+func (h ReportHandler) Margin(c *gin.Context) {
+	filter, err := marginFilter(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.Error(msg.BadRequest(msg.ErrInvalidFilterRequest))
+		return
+	}
+
+	report, err := h.reportSvc.Margin(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if format == "csv" {
+		filename := fmt.Sprintf("margin-%s-%s-%s.csv", report.GroupBy, report.From, report.To)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Status(http.StatusOK)
+		if err := writeMarginCSV(c.Writer, report); err != nil {
+			c.Error(msg.InternalServerError(err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.GetDataResponse), report))
+}
+
+func marginFilter(c *gin.Context) (entity.MarginFilter, error) {
+	filter := entity.MarginFilter{
+		GroupBy:  c.DefaultQuery("groupBy", entity.GroupProduct),
+		Period:   c.DefaultQuery("period", entity.PeriodDay),
+		Category: c.Query("category"),
+	}
+	if !entity.IsGroup(filter.GroupBy) || !entity.IsPeriod(filter.Period) {
+		return entity.MarginFilter{}, msg.BadRequest(msg.ErrInvalidFilterRequest)
+	}
+
+	var err error
+	filter.To, err = time.Parse(entity.DateLayout, c.DefaultQuery("to", time.Now().Format(entity.DateLayout)))
+	if err != nil {
+		return entity.MarginFilter{}, msg.BadRequest(msg.ErrInvalidFilterRequest)
+	}
+	filter.From = filter.To.AddDate(0, 0, 1-defaultReportDays)
+	if raw := c.Query("from"); raw != "" {
+		if filter.From, err = time.Parse(entity.DateLayout, raw); err != nil {
+			return entity.MarginFilter{}, msg.BadRequest(msg.ErrInvalidFilterRequest)
+		}
+	}
+	if filter.From.After(filter.To) {
+		return entity.MarginFilter{}, msg.BadRequest(msg.ErrInvalidFilterRequest)
+	}
+	return filter, nil
+}
+
+// writeMarginCSV writes the rows of report and its total.
+func writeMarginCSV(w http.ResponseWriter, report entity.MarginReport) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{report.GroupBy, "name", "quantity", "revenue", "cost", "margin", "marginPercent"})
+	for _, row := range append(report.Rows, report.Total) {
+		writer.Write([]string{
+			csvText(row.Key),
+			csvText(row.Name),
+			strconv.Itoa(row.Quantity),
+			row.Revenue.String(),
+			row.Cost.String(),
+			row.Margin.String(),
+			strconv.FormatFloat(row.MarginPercent, 'f', 2, 64),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvText keeps spreadsheets from running text that looks like a formula.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
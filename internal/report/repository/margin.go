@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"projectsphere/eniqlo-store/internal/report/entity"
+	"projectsphere/eniqlo-store/pkg/database"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+
+	"github.com/lib/pq"
+)
+
+type ReportRepo struct {
+	dbConnector database.PostgresConnector
+}
+
+func NewReportRepo(dbConnector database.PostgresConnector) ReportRepo {
+	return ReportRepo{
+		dbConnector: dbConnector,
+	}
+}
+
+// Margin sums the sales selected by filter per group. Products are
+// grouped by id and named after their latest sale; categories and cost
+// are the ones recorded at the time of sale.
+func (r ReportRepo) Margin(ctx context.Context, filter entity.MarginFilter) ([]entity.MarginRow, error) {
+	args := []interface{}{filter.From.Format(entity.DateLayout), filter.To.Format(entity.DateLayout), pq.Array(filter.Categories)}
+
+	var key, name, order string
+	switch filter.GroupBy {
+	case entity.GroupCategory:
+		key, name, order = `i.category`, `MIN(i.category)`, `revenue DESC, key`
+	case entity.GroupPeriod:
+		args = append(args, filter.Period)
+		key = `to_char(date_trunc($4, c.created_at), 'YYYY-MM-DD')`
+		name, order = `MIN(to_char(date_trunc($4, c.created_at), 'YYYY-MM-DD'))`, `key`
+	default:
+		key = `COALESCE(i.id_product::text, '')`
+		name, order = `(ARRAY_AGG(i.name ORDER BY i.id_item DESC))[1]`, `revenue DESC, key`
+	}
+
+	rows := []entity.MarginRow{}
+	err := r.dbConnector.DB.SelectContext(ctx, &rows, fmt.Sprintf(`
+        SELECT %s AS key, %s AS name, SUM(i.quantity) AS quantity,
+            SUM(i.quantity * i.price - i.discount) AS revenue, SUM(i.quantity * i.cost) AS cost
+        FROM "checkout_items" i
+        JOIN "checkouts" c ON c.id_checkout = i.id_checkout
+        WHERE c.created_at >= $1::date AND c.created_at < $2::date + 1
+            AND ($3::varchar[] IS NULL OR i.category = ANY($3))
+        GROUP BY 1
+        ORDER BY %s
+    `, key, name, order), args...)
+	if err != nil {
+		return nil, msg.InternalServerError(err.Error())
+	}
+	return rows, nil
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	categoryService "projectsphere/eniqlo-store/internal/category/service"
+	"projectsphere/eniqlo-store/internal/report/entity"
+	"projectsphere/eniqlo-store/internal/report/repository"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+)
+
+type ReportService struct {
+	reportRepo  repository.ReportRepo
+	categorySvc categoryService.CategoryService
+}
+
+func NewReportService(reportRepo repository.ReportRepo, categorySvc categoryService.CategoryService) ReportService {
+	return ReportService{
+		reportRepo:  reportRepo,
+		categorySvc: categorySvc,
+	}
+}
+
+// Margin reports the gross margin of the sales in filter, expanding its
+// category to the subcategories.
+func (s ReportService) Margin(ctx context.Context, filter entity.MarginFilter) (entity.MarginReport, error) {
+	if filter.Category != "" {
+		tree, err := s.categorySvc.Tree(ctx)
+		if err != nil {
+			return entity.MarginReport{}, err
+		}
+		filter.Categories = tree.Keys(filter.Category)
+		if len(filter.Categories) == 0 {
+			return entity.MarginReport{}, msg.NotFound(msg.ErrCategoryNotFound)
+		}
+	}
+
+	rows, err := s.reportRepo.Margin(ctx, filter)
+	if err != nil {
+		return entity.MarginReport{}, err
+	}
+	return entity.NewMarginReport(filter, rows), nil
+}
@@ -12,21 +12,22 @@ type User struct {
 	PhoneNumber string       `db:"phone_number" json:"phoneNumber"`
 	Password    string       `db:"password" json:"-"`
 	Salt        string       `db:"salt" json:"-"`
+	Role        string       `db:"role" json:"role"`
 	CreatedAt   time.Time    `db:"created_at" json:"created_at"`
 	UpdatedAt   sql.NullTime `db:"updated_at" json:"updated_at"`
 }
 
 type UserParam struct {
-	Email       string
-	Name        string
-	PhoneNumber string
-	Password    string
-	Salt        string
+	Email       string `json:"email" validate:"required,email"`
+	Name        string `json:"name" validate:"required,fullname"`
+	PhoneNumber string `json:"phoneNumber" validate:"required,phone"`
+	Password    string `json:"password" validate:"required,password"`
+	Salt        string `json:"-"`
 }
 
 type UserLoginParam struct {
-	PhoneNumber string `json:"phoneNumber"`
-	Password    string `json:"password"`
+	PhoneNumber string `json:"phoneNumber" validate:"required,phone"`
+	Password    string `json:"password" validate:"required,password"`
 }
 
 type UserResponse struct {
@@ -34,5 +35,6 @@ type UserResponse struct {
 	Name        string `json:"name"`
 	Email       string `json:"email"`
 	PhoneNumber string `json:"phoneNumber"`
+	Role        string `json:"role"`
 	AccessToken string `json:"accessToken"`
 }
@@ -5,6 +5,7 @@ import (
 	"projectsphere/eniqlo-store/internal/staff/entity"
 	"projectsphere/eniqlo-store/internal/staff/service"
 	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"projectsphere/eniqlo-store/pkg/validator"
 
 	"github.com/gin-gonic/gin"
 )
@@ -24,18 +25,17 @@ func (h UserHandler) Register(c *gin.Context) {
 
 	err := c.ShouldBindJSON(payload)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, msg.BadRequest(err.Error()))
+		c.Error(validator.BindError(err))
 		return
 	}
 
 	resp, err := h.userSvc.Register(c.Request.Context(), payload)
 	if err != nil {
-		respError := msg.UnwrapRespError(err)
-		c.JSON(respError.Code, respError)
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, msg.ReturnResult("User registered successfully", resp))
+	c.JSON(http.StatusCreated, msg.ReturnResult(msg.T(c.Request.Context(), msg.UserRegisteredResponse), resp))
 }
 
 func (h UserHandler) Login(c *gin.Context) {
@@ -43,16 +43,15 @@ func (h UserHandler) Login(c *gin.Context) {
 
 	err := c.ShouldBindJSON(payload)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, msg.BadRequest(err.Error()))
+		c.Error(validator.BindError(err))
 		return
 	}
 
 	resp, err := h.userSvc.Login(c.Request.Context(), payload)
 	if err != nil {
-		respError := msg.UnwrapRespError(err)
-		c.JSON(respError.Code, respError)
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, msg.ReturnResult("User logged successfully", resp))
+	c.JSON(http.StatusOK, msg.ReturnResult(msg.T(c.Request.Context(), msg.UserLoggedResponse), resp))
 }
@@ -22,7 +22,7 @@ func NewUserRepo(dbConnector database.PostgresConnector) UserRepo {
 func (r UserRepo) CreateUser(ctx context.Context, param entity.UserParam) (entity.User, error) {
 	query := `
 		INSERT INTO users (email, name, phone_number, password, salt) VALUES 
-		($1, $2, $3, $4, $5) RETURNING user_id, email, name, phone_number, password, salt, created_at, updated_at
+		($1, $2, $3, $4, $5) RETURNING user_id, email, name, phone_number, password, salt, role, created_at, updated_at
 	`
 	var row entity.User
 	err := r.dbConnector.DB.GetContext(
@@ -51,7 +51,7 @@ func (r UserRepo) CreateUser(ctx context.Context, param entity.UserParam) (entit
 
 func (r UserRepo) GetUserByPhoneNumber(ctx context.Context, phoneNumber string) (entity.User, error) {
 	query := `
-		SELECT user_id, email, name, phone_number, password, salt, created_at, updated_at FROM users WHERE phone_number = $1
+		SELECT user_id, email, name, phone_number, password, salt, role, created_at, updated_at FROM users WHERE phone_number = $1
 	`
 
 	var row entity.User
@@ -74,7 +74,7 @@ func (r UserRepo) GetUserByPhoneNumber(ctx context.Context, phoneNumber string)
 
 func (r UserRepo) IsUserExist(ctx context.Context, userId uint32) bool {
 	query := `
-		SELECT 1 FROM users WHERE id_user = $1
+		SELECT 1 FROM users WHERE user_id = $1
 	`
 
 	var result = 0
@@ -91,6 +91,29 @@ func (r UserRepo) IsUserExist(ctx context.Context, userId uint32) bool {
 	return result == 1
 }
 
+func (r UserRepo) GetUserRole(ctx context.Context, userId uint32) (string, error) {
+	query := `
+		SELECT role FROM users WHERE user_id = $1
+	`
+
+	var role string
+	err := r.dbConnector.DB.GetContext(
+		ctx,
+		&role,
+		query,
+		userId,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", msg.NotFound(msg.ErrUserNotFound)
+		} else {
+			return "", msg.InternalServerError(err.Error())
+		}
+	}
+
+	return role, nil
+}
+
 func (r UserRepo) IsPhoneNumberExist(ctx context.Context, phoneNumber string) bool {
 	query := `
 		SELECT 1 FROM users WHERE phone_number = $1
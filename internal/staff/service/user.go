@@ -6,8 +6,8 @@ import (
 	"projectsphere/eniqlo-store/internal/staff/entity"
 	"projectsphere/eniqlo-store/internal/staff/repository"
 	"projectsphere/eniqlo-store/pkg/middleware/auth"
+	"projectsphere/eniqlo-store/pkg/phone"
 	"projectsphere/eniqlo-store/pkg/protocol/msg"
-	"projectsphere/eniqlo-store/pkg/validator"
 )
 
 type UserService struct {
@@ -25,21 +25,11 @@ func NewUserService(userRepo repository.UserRepo, saltLen int, jwtAuth auth.JWTA
 }
 
 func (u UserService) Register(ctx context.Context, userParam *entity.UserParam) (entity.UserResponse, error) {
-	if !validator.IsValidFullName(userParam.Name) {
-		return entity.UserResponse{}, msg.BadRequest(msg.ErrInvalidFullName)
-	}
-
-	if !validator.IsEmailValid(userParam.Email) {
-		return entity.UserResponse{}, msg.BadRequest(msg.ErrInvalidEmail)
-	}
-
-	if !validator.IsSolidPassword(userParam.Password) {
-		return entity.UserResponse{}, msg.BadRequest(msg.ErrInvalidPassword)
-	}
-
-	if !validator.IsValidPhoneNumber(userParam.PhoneNumber) {
+	phoneNumber, err := phone.Normalize(userParam.PhoneNumber)
+	if err != nil {
 		return entity.UserResponse{}, msg.BadRequest(msg.ErrInvalidPhoneNumber)
 	}
+	userParam.PhoneNumber = phoneNumber
 
 	if u.userRepo.IsPhoneNumberExist(ctx, userParam.PhoneNumber) {
 		return entity.UserResponse{}, msg.BadRequest(msg.ErrPhoneNumberAlreadyUsed)
@@ -64,20 +54,18 @@ func (u UserService) Register(ctx context.Context, userParam *entity.UserParam)
 		Name:        user.Name,
 		Email:       user.Email,
 		PhoneNumber: user.PhoneNumber,
+		Role:        user.Role,
 		AccessToken: accessToken,
 	}, nil
 }
 
 func (u UserService) Login(ctx context.Context, loginParam *entity.UserLoginParam) (entity.UserResponse, error) {
-	if !validator.IsValidPhoneNumber(loginParam.PhoneNumber) {
+	phoneNumber, err := phone.Normalize(loginParam.PhoneNumber)
+	if err != nil {
 		return entity.UserResponse{}, msg.BadRequest(msg.ErrInvalidPhoneNumber)
 	}
 
-	if !validator.IsSolidPassword(loginParam.Password) {
-		return entity.UserResponse{}, msg.BadRequest(msg.ErrWrongPassword)
-	}
-
-	user, err := u.userRepo.GetUserByPhoneNumber(ctx, loginParam.PhoneNumber)
+	user, err := u.userRepo.GetUserByPhoneNumber(ctx, phoneNumber)
 	if err != nil {
 		return entity.UserResponse{}, err
 	}
@@ -97,6 +85,7 @@ func (u UserService) Login(ctx context.Context, loginParam *entity.UserLoginPara
 		Name:        user.Name,
 		Email:       user.Email,
 		PhoneNumber: user.PhoneNumber,
+		Role:        user.Role,
 		AccessToken: accessToken,
 	}, nil
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"os"
 	"projectsphere/eniqlo-store/config"
 	"projectsphere/eniqlo-store/pkg/middleware/graceful"
 	"projectsphere/eniqlo-store/pkg/middleware/logger"
@@ -9,22 +10,43 @@ import (
 	"time"
 
 	_ "github.com/lib/pq"
+	"github.com/rs/zerolog/log"
 )
 
 func main() {
-	logger.InitLogger()
 	config.LoadConfig(".env")
+	logger.InitLogger()
+
+	lifecycle := graceful.NewLifecycle(
+		graceful.NotifySignals(),
+		config.GetDuration("SHUTDOWN_TIMEOUT", 10*time.Second),
+	)
 
 	httpProtocol := httpListener.Start()
-	graceful.GracefulShutdown(
-		context.TODO(),
-		time.Duration(5*time.Second),
-		map[string]graceful.Operation{
-			"http": func(ctx context.Context) error {
-				return httpProtocol.Shutdown(ctx)
-			},
+
+	lifecycle.Append(graceful.Component{
+		Name: "logger",
+		Stop: func(ctx context.Context) error {
+			return logger.Close()
 		},
-	)
+	})
+	lifecycle.Append(graceful.Component{
+		Name: "database",
+		Stop: httpProtocol.CloseDatabase,
+	})
+	for _, worker := range httpProtocol.Workers() {
+		lifecycle.Append(worker)
+	}
+	lifecycle.Append(graceful.Component{
+		Name:   "http",
+		Start:  httpProtocol.Listen,
+		Stop:   httpProtocol.Shutdown,
+		Failed: httpProtocol.Failed(),
+	})
 
-	httpProtocol.Listen()
+	err := lifecycle.Run(context.Background())
+	if err != nil {
+		log.Error().Err(err).Msg("shutdown with error")
+	}
+	os.Exit(graceful.ExitCode(err))
 }
@@ -15,3 +15,7 @@ func NewPostgresConnector(ctx context.Context, db *sqlx.DB) PostgresConnector {
 		DB: db,
 	}
 }
+
+func (p PostgresConnector) Close() error {
+	return p.DB.Close()
+}
@@ -0,0 +1,55 @@
+BEGIN;
+
+-- staff log in by their phone number in E.164 form since numbers are
+-- normalized, so stored numbers are rewritten the way pkg/phone normalizes
+-- input: formatting is dropped, a leading 00 becomes +, a national number
+-- gets the default +62 and a trunk 0 kept after the country code goes.
+-- Numbers that still are not E.164 are left as they are.
+CREATE TEMPORARY TABLE "normalized_phone_numbers" ON COMMIT DROP AS
+SELECT "user_id", "phone_number", "normalized"
+FROM (
+  SELECT "user_id", "phone_number",
+    regexp_replace(
+      CASE
+        WHEN "cleaned" LIKE '00%' THEN '+' || substr("cleaned", 3)
+        WHEN "cleaned" LIKE '0%' THEN '+62' || substr("cleaned", 2)
+        ELSE "cleaned"
+      END,
+      '^\+(62|60|63|66|84|855|856|95|61|64|86|886|81|82|91|92|880|94|977|966|971|90|20|27|234|55|54|44|353|31|49|33|41|46)0', '+\1') AS "normalized"
+  FROM (
+    SELECT "user_id", "phone_number", regexp_replace(btrim("phone_number"), '[ .()-]', '', 'g') AS "cleaned"
+    FROM "users"
+  ) cleaned
+) normalized
+WHERE "normalized" ~ '^\+[1-9][0-9]{6,14}$';
+
+-- numbers that collapse into one go to the user already holding the
+-- normalized number, or else to the oldest user; the others keep theirs
+CREATE TEMPORARY TABLE "phone_number_owners" ON COMMIT DROP AS
+SELECT "user_id", "phone_number", "normalized",
+  ROW_NUMBER() OVER (PARTITION BY "normalized" ORDER BY "phone_number" = "normalized" DESC, "user_id") AS "rank"
+FROM "normalized_phone_numbers";
+
+UPDATE "users" u
+SET "phone_number" = o."normalized", "updated_at" = CURRENT_TIMESTAMP
+FROM "phone_number_owners" o
+WHERE o."user_id" = u."user_id" AND o."rank" = 1 AND u."phone_number" <> o."normalized";
+
+DO $$
+DECLARE
+  duplicate record;
+BEGIN
+  FOR duplicate IN
+    SELECT d."user_id", d."phone_number", d."normalized", o."user_id" AS "owner"
+    FROM "phone_number_owners" d
+    JOIN "phone_number_owners" o ON o."normalized" = d."normalized" AND o."rank" = 1
+    WHERE d."rank" > 1
+    ORDER BY d."user_id"
+  LOOP
+    RAISE WARNING 'user % keeps phone number %: % belongs to user %',
+      duplicate."user_id", duplicate."phone_number", duplicate."normalized", duplicate."owner";
+  END LOOP;
+END;
+$$;
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+-- user_id, image_url and updated_at were written by the service before the
+-- schema declared them, so they may already exist
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "user_id" integer;
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "image_url" text NOT NULL DEFAULT '';
+ALTER TABLE "products" ALTER COLUMN "image_url" DROP DEFAULT;
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "updated_at" timestamp DEFAULT CURRENT_TIMESTAMP;
+
+-- existing products start at version 1
+ALTER TABLE "products" ADD COLUMN "version" integer NOT NULL DEFAULT 1;
+
+ALTER TABLE "products" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("user_id");
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+CREATE TABLE "product_changes" (
+  "id_change" SERIAL PRIMARY KEY,
+  "id_product" integer NOT NULL,
+  "user_id" integer,
+  "version" integer NOT NULL,
+  "changes" jsonb NOT NULL,
+  "created_at" timestamp DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE "product_changes" ADD FOREIGN KEY ("id_product") REFERENCES "products" ("id_product") ON DELETE CASCADE;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+-- prices are whole cents from now on
+ALTER TABLE "products" ALTER COLUMN "price" TYPE numeric(16,2) USING round("price", 2);
+
+COMMIT;
@@ -0,0 +1,29 @@
+BEGIN;
+
+-- existing staff keep their current rights
+ALTER TABLE "users" ADD COLUMN "role" varchar not null DEFAULT 'staff' CHECK ("role" IN ('staff', 'manager'));
+
+ALTER TABLE "products" ADD COLUMN "attributes" jsonb NOT NULL DEFAULT '{}';
+
+CREATE TABLE "categories" (
+  "id_category" SERIAL PRIMARY KEY,
+  "parent_id" integer,
+  "name" varchar NOT NULL,
+  "slug" varchar unique NOT NULL,
+  "attributes" jsonb NOT NULL DEFAULT '[]',
+  "created_at" timestamp DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE "categories" ADD FOREIGN KEY ("parent_id") REFERENCES "categories" ("id_category");
+
+-- the categories products could be given before they were managed; their
+-- names still match the category stored on those products
+INSERT INTO "categories" ("name", "slug") VALUES
+  ('Clothing', 'clothing'),
+  ('Accessories', 'accessories'),
+  ('Footwear', 'footwear'),
+  ('Beverages', 'beverages')
+ON CONFLICT ("slug") DO NOTHING;
+
+COMMIT;
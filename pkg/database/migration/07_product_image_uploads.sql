@@ -0,0 +1,39 @@
+BEGIN;
+
+-- images linked before uploads keep their URL as thumbnail, have no stored
+-- objects and are ordered as they were added
+ALTER TABLE "product_images"
+  ADD COLUMN "thumbnail_url" text NOT NULL DEFAULT '',
+  ADD COLUMN "storage_key" varchar NOT NULL DEFAULT '',
+  ADD COLUMN "thumbnail_key" varchar NOT NULL DEFAULT '',
+  ADD COLUMN "content_type" varchar NOT NULL DEFAULT '',
+  ADD COLUMN "size_bytes" bigint NOT NULL DEFAULT 0,
+  ADD COLUMN "width" integer NOT NULL DEFAULT 0,
+  ADD COLUMN "height" integer NOT NULL DEFAULT 0,
+  ADD COLUMN "position" integer NOT NULL DEFAULT 1,
+  ADD COLUMN "is_primary" boolean NOT NULL DEFAULT false;
+
+UPDATE "product_images" i
+SET "thumbnail_url" = i."image_url", "position" = ordered."position"
+FROM (
+  SELECT "id_image", ROW_NUMBER() OVER (PARTITION BY "id_product" ORDER BY "id_image") AS "position"
+  FROM "product_images"
+) ordered
+WHERE ordered."id_image" = i."id_image";
+
+ALTER TABLE "product_images"
+  ALTER COLUMN "thumbnail_url" DROP DEFAULT,
+  ALTER COLUMN "storage_key" DROP DEFAULT,
+  ALTER COLUMN "thumbnail_key" DROP DEFAULT,
+  ALTER COLUMN "content_type" DROP DEFAULT,
+  ALTER COLUMN "size_bytes" DROP DEFAULT,
+  ALTER COLUMN "width" DROP DEFAULT,
+  ALTER COLUMN "height" DROP DEFAULT,
+  ALTER COLUMN "position" DROP DEFAULT;
+
+CREATE UNIQUE INDEX "product_images_primary" ON "product_images" ("id_product") WHERE "is_primary";
+
+ALTER TABLE "product_images" DROP CONSTRAINT "product_images_id_product_fkey";
+ALTER TABLE "product_images" ADD FOREIGN KEY ("id_product") REFERENCES "products" ("id_product") ON DELETE CASCADE;
+
+COMMIT;
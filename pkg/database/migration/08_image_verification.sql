@@ -0,0 +1,25 @@
+BEGIN;
+
+-- existing image URLs start out pending and are picked up by the verifier
+ALTER TABLE "products"
+  ADD COLUMN "image_status" varchar NOT NULL DEFAULT 'pending' CHECK ("image_status" IN ('pending', 'ok', 'broken')),
+  ADD COLUMN "image_checked_at" timestamp,
+  ADD COLUMN "image_error" text,
+  ADD COLUMN "cached_image_url" text;
+
+-- a new image URL has to be verified again
+CREATE FUNCTION "reset_image_check"() RETURNS trigger AS $$
+BEGIN
+  NEW.image_status := 'pending';
+  NEW.image_checked_at := NULL;
+  NEW.image_error := NULL;
+  NEW.cached_image_url := NULL;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "products_image_url_changed" BEFORE UPDATE OF "image_url" ON "products"
+  FOR EACH ROW WHEN (OLD.image_url IS DISTINCT FROM NEW.image_url)
+  EXECUTE FUNCTION "reset_image_check"();
+
+COMMIT;
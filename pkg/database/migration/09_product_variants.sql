@@ -0,0 +1,56 @@
+BEGIN;
+
+ALTER TABLE "products" ADD COLUMN "options" jsonb NOT NULL DEFAULT '[]';
+
+CREATE TABLE "product_variants" (
+  "id_variant" SERIAL PRIMARY KEY,
+  "id_product" integer NOT NULL,
+  "options" jsonb NOT NULL DEFAULT '{}',
+  "sku" varchar NOT NULL,
+  "barcode" varchar,
+  "price" numeric(16,2),
+  "stock" int NOT NULL DEFAULT 0 CHECK ("stock" >= 0),
+  "is_available" boolean NOT NULL DEFAULT true,
+  "position" integer NOT NULL,
+  "created_at" timestamp DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE "checkouts" (
+  "id_checkout" SERIAL PRIMARY KEY,
+  "user_id" integer,
+  "total" numeric(16,2) NOT NULL,
+  "paid" numeric(16,2) NOT NULL,
+  "change" numeric(16,2) NOT NULL,
+  "created_at" timestamp DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE "checkout_items" (
+  "id_item" SERIAL PRIMARY KEY,
+  "id_checkout" integer NOT NULL,
+  "id_product" integer,
+  "id_variant" integer,
+  "name" varchar NOT NULL,
+  "sku" varchar NOT NULL,
+  "options" jsonb NOT NULL DEFAULT '{}',
+  "quantity" integer NOT NULL,
+  "price" numeric(16,2) NOT NULL
+);
+
+CREATE UNIQUE INDEX "product_variants_options" ON "product_variants" ("id_product", "options");
+CREATE UNIQUE INDEX "product_variants_barcode" ON "product_variants" ("barcode") WHERE "barcode" IS NOT NULL;
+CREATE INDEX "product_variants_sku" ON "product_variants" ("sku");
+
+ALTER TABLE "product_variants" ADD FOREIGN KEY ("id_product") REFERENCES "products" ("id_product") ON DELETE CASCADE;
+ALTER TABLE "checkouts" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("user_id");
+ALTER TABLE "checkout_items" ADD FOREIGN KEY ("id_checkout") REFERENCES "checkouts" ("id_checkout") ON DELETE CASCADE;
+ALTER TABLE "checkout_items" ADD FOREIGN KEY ("id_product") REFERENCES "products" ("id_product") ON DELETE SET NULL;
+ALTER TABLE "checkout_items" ADD FOREIGN KEY ("id_variant") REFERENCES "product_variants" ("id_variant") ON DELETE SET NULL;
+
+-- existing products become single variant products holding their stock;
+-- variants cannot hold a negative stock, so that counts as none
+INSERT INTO "product_variants" ("id_product", "sku", "stock", "is_available", "position", "created_at", "updated_at")
+SELECT "id_product", "sku", GREATEST("stock", 0), "is_available", 1, "created_at", "updated_at"
+FROM "products";
+
+COMMIT;
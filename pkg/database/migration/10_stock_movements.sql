@@ -0,0 +1,40 @@
+BEGIN;
+
+-- the stock ledger outlives deleted products, so it has no foreign keys to
+-- them; product_variants.stock caches the sum of quantity per variant
+CREATE TABLE "stock_movements" (
+  "id_movement" BIGSERIAL PRIMARY KEY,
+  "id_product" integer NOT NULL,
+  "id_variant" integer NOT NULL,
+  "type" varchar NOT NULL CHECK ("type" IN ('sale', 'return', 'adjustment', 'receiving', 'transfer')),
+  "quantity" integer NOT NULL CHECK ("quantity" <> 0),
+  "balance" integer NOT NULL,
+  "reason" varchar NOT NULL DEFAULT '',
+  "reference" varchar NOT NULL DEFAULT '',
+  "user_id" integer,
+  "created_at" timestamp DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "stock_movements_product" ON "stock_movements" ("id_product", "id_movement");
+
+ALTER TABLE "stock_movements" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("user_id");
+
+-- stock held before the ledger becomes its opening balance, so the ledger
+-- adds up to product_variants.stock from the start
+INSERT INTO "stock_movements" ("id_product", "id_variant", "type", "quantity", "balance", "reason")
+SELECT "id_product", "id_variant", 'adjustment', "stock", "stock", 'opening balance'
+FROM "product_variants"
+WHERE "stock" <> 0
+ORDER BY "id_variant";
+
+-- stock movements are never changed or removed
+CREATE FUNCTION "reject_stock_movement_change"() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'stock_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "stock_movements_append_only" BEFORE UPDATE OR DELETE ON "stock_movements"
+  FOR EACH ROW EXECUTE FUNCTION "reject_stock_movement_change"();
+
+COMMIT;
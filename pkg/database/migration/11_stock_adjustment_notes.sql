@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE "stock_movements" ADD COLUMN "note" varchar NOT NULL DEFAULT '';
+
+COMMIT;
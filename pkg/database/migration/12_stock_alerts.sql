@@ -0,0 +1,32 @@
+BEGIN;
+
+-- existing products have no reorder point until one is set
+ALTER TABLE "products"
+  ADD COLUMN "reorder_point" int NOT NULL DEFAULT 0 CHECK ("reorder_point" >= 0),
+  ADD COLUMN "reorder_quantity" int NOT NULL DEFAULT 0 CHECK ("reorder_quantity" >= 0);
+
+-- one alert per product is open or acknowledged at a time
+CREATE TABLE "stock_alerts" (
+  "id_alert" SERIAL PRIMARY KEY,
+  "id_product" integer NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'open' CHECK ("status" IN ('open', 'acknowledged', 'resolved')),
+  "stock" integer NOT NULL,
+  "reorder_point" integer NOT NULL,
+  "reorder_quantity" integer NOT NULL,
+  "notified_at" timestamp,
+  "acknowledged_at" timestamp,
+  "acknowledged_by" integer,
+  "resolved_at" timestamp,
+  "resolved_by" integer,
+  "created_at" timestamp DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX "stock_alerts_active" ON "stock_alerts" ("id_product") WHERE "status" <> 'resolved';
+CREATE INDEX "stock_alerts_status" ON "stock_alerts" ("status", "id_alert");
+CREATE INDEX "products_updated_at" ON "products" ("updated_at");
+
+ALTER TABLE "stock_alerts" ADD FOREIGN KEY ("id_product") REFERENCES "products" ("id_product") ON DELETE CASCADE;
+ALTER TABLE "stock_alerts" ADD FOREIGN KEY ("acknowledged_by") REFERENCES "users" ("user_id");
+ALTER TABLE "stock_alerts" ADD FOREIGN KEY ("resolved_by") REFERENCES "users" ("user_id");
+
+COMMIT;
@@ -0,0 +1,80 @@
+BEGIN;
+
+CREATE TABLE "locations" (
+  "id_location" SERIAL PRIMARY KEY,
+  "code" varchar NOT NULL UNIQUE,
+  "name" varchar NOT NULL,
+  "type" varchar NOT NULL CHECK ("type" IN ('store', 'backroom', 'warehouse')),
+  "is_active" boolean NOT NULL DEFAULT true,
+  "created_at" timestamp DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp DEFAULT CURRENT_TIMESTAMP
+);
+
+-- stock of a variant per location; product_variants.stock caches the sum
+-- over all locations
+CREATE TABLE "variant_stocks" (
+  "id_variant" integer NOT NULL,
+  "id_location" integer NOT NULL,
+  "stock" int NOT NULL DEFAULT 0 CHECK ("stock" >= 0),
+  PRIMARY KEY ("id_variant", "id_location")
+);
+
+-- a transfer takes its items out of the source location when it is
+-- created and puts them into the destination when it is received
+CREATE TABLE "stock_transfers" (
+  "id_transfer" SERIAL PRIMARY KEY,
+  "from_location" integer NOT NULL,
+  "to_location" integer NOT NULL CHECK ("to_location" <> "from_location"),
+  "status" varchar NOT NULL DEFAULT 'in_transit' CHECK ("status" IN ('in_transit', 'received', 'cancelled')),
+  "note" varchar NOT NULL DEFAULT '',
+  "created_by" integer,
+  "received_by" integer,
+  "received_at" timestamp,
+  "cancelled_by" integer,
+  "cancelled_at" timestamp,
+  "created_at" timestamp DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE "stock_transfer_items" (
+  "id_item" SERIAL PRIMARY KEY,
+  "id_transfer" integer NOT NULL,
+  "id_product" integer NOT NULL,
+  "id_variant" integer NOT NULL,
+  "quantity" integer NOT NULL CHECK ("quantity" > 0)
+);
+
+INSERT INTO "locations" ("code", "name", "type") VALUES
+  ('store', 'Store floor', 'store'),
+  ('backroom', 'Back room', 'backroom'),
+  ('warehouse', 'Warehouse', 'warehouse');
+
+-- the ledger now records where stock moved; everything booked before
+-- locations happened on the store floor. The ledger is append-only, so its
+-- trigger is set aside for this one backfill.
+ALTER TABLE "stock_movements" ADD COLUMN "id_location" integer;
+ALTER TABLE "stock_movements" DISABLE TRIGGER "stock_movements_append_only";
+UPDATE "stock_movements" SET "id_location" = (SELECT "id_location" FROM "locations" WHERE "code" = 'store');
+ALTER TABLE "stock_movements" ENABLE TRIGGER "stock_movements_append_only";
+ALTER TABLE "stock_movements" ALTER COLUMN "id_location" SET NOT NULL;
+
+-- stock held before locations is on the store floor
+INSERT INTO "variant_stocks" ("id_variant", "id_location", "stock")
+SELECT "id_variant", (SELECT "id_location" FROM "locations" WHERE "code" = 'store'), "stock"
+FROM "product_variants"
+WHERE "stock" <> 0;
+
+CREATE INDEX "variant_stocks_location" ON "variant_stocks" ("id_location", "id_variant");
+CREATE INDEX "stock_transfers_status" ON "stock_transfers" ("status", "id_transfer");
+CREATE INDEX "stock_transfer_items_transfer" ON "stock_transfer_items" ("id_transfer");
+
+ALTER TABLE "variant_stocks" ADD FOREIGN KEY ("id_variant") REFERENCES "product_variants" ("id_variant") ON DELETE CASCADE;
+ALTER TABLE "variant_stocks" ADD FOREIGN KEY ("id_location") REFERENCES "locations" ("id_location");
+ALTER TABLE "stock_movements" ADD FOREIGN KEY ("id_location") REFERENCES "locations" ("id_location");
+ALTER TABLE "stock_transfers" ADD FOREIGN KEY ("from_location") REFERENCES "locations" ("id_location");
+ALTER TABLE "stock_transfers" ADD FOREIGN KEY ("to_location") REFERENCES "locations" ("id_location");
+ALTER TABLE "stock_transfers" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("user_id");
+ALTER TABLE "stock_transfers" ADD FOREIGN KEY ("received_by") REFERENCES "users" ("user_id");
+ALTER TABLE "stock_transfers" ADD FOREIGN KEY ("cancelled_by") REFERENCES "users" ("user_id");
+ALTER TABLE "stock_transfer_items" ADD FOREIGN KEY ("id_transfer") REFERENCES "stock_transfers" ("id_transfer") ON DELETE CASCADE;
+
+COMMIT;
@@ -0,0 +1,40 @@
+BEGIN;
+
+-- a stock take freezes the expected stock of its lines when it starts;
+-- approving it posts counted - expected as adjustments
+CREATE TABLE "stock_takes" (
+  "id_stock_take" SERIAL PRIMARY KEY,
+  "id_location" integer NOT NULL,
+  "category" varchar,
+  "status" varchar NOT NULL DEFAULT 'counting' CHECK ("status" IN ('counting', 'approved', 'cancelled')),
+  "note" varchar NOT NULL DEFAULT '',
+  "created_by" integer,
+  "approved_by" integer,
+  "approved_at" timestamp,
+  "cancelled_by" integer,
+  "cancelled_at" timestamp,
+  "created_at" timestamp DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE "stock_take_lines" (
+  "id_stock_take" integer NOT NULL,
+  "id_variant" integer NOT NULL,
+  "id_product" integer NOT NULL,
+  "expected" integer NOT NULL,
+  "counted" integer CHECK ("counted" >= 0),
+  "counted_by" integer,
+  "counted_at" timestamp,
+  PRIMARY KEY ("id_stock_take", "id_variant")
+);
+
+CREATE INDEX "stock_takes_status" ON "stock_takes" ("status", "id_stock_take");
+
+ALTER TABLE "stock_takes" ADD FOREIGN KEY ("id_location") REFERENCES "locations" ("id_location");
+ALTER TABLE "stock_takes" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("user_id");
+ALTER TABLE "stock_takes" ADD FOREIGN KEY ("approved_by") REFERENCES "users" ("user_id");
+ALTER TABLE "stock_takes" ADD FOREIGN KEY ("cancelled_by") REFERENCES "users" ("user_id");
+ALTER TABLE "stock_take_lines" ADD FOREIGN KEY ("id_stock_take") REFERENCES "stock_takes" ("id_stock_take") ON DELETE CASCADE;
+ALTER TABLE "stock_take_lines" ADD FOREIGN KEY ("id_variant") REFERENCES "product_variants" ("id_variant") ON DELETE CASCADE;
+ALTER TABLE "stock_take_lines" ADD FOREIGN KEY ("counted_by") REFERENCES "users" ("user_id");
+
+COMMIT;
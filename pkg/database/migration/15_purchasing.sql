@@ -0,0 +1,83 @@
+BEGIN;
+
+CREATE TABLE "suppliers" (
+  "id_supplier" SERIAL PRIMARY KEY,
+  "name" varchar NOT NULL UNIQUE,
+  "contact_name" varchar NOT NULL DEFAULT '',
+  "email" varchar NOT NULL DEFAULT '',
+  "phone_number" varchar NOT NULL DEFAULT '',
+  "address" varchar NOT NULL DEFAULT '',
+  "lead_time_days" integer NOT NULL DEFAULT 0 CHECK ("lead_time_days" >= 0),
+  "is_active" boolean NOT NULL DEFAULT true,
+  "created_at" timestamp DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp DEFAULT CURRENT_TIMESTAMP
+);
+
+-- a purchase order is a draft until sent to the supplier; goods receipts
+-- book what arrives into its location until every line is received
+CREATE TABLE "purchase_orders" (
+  "id_purchase_order" SERIAL PRIMARY KEY,
+  "id_supplier" integer NOT NULL,
+  "id_location" integer NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'draft' CHECK ("status" IN ('draft', 'sent', 'partially_received', 'received', 'cancelled')),
+  "note" varchar NOT NULL DEFAULT '',
+  "created_by" integer,
+  "sent_by" integer,
+  "sent_at" timestamp,
+  "cancelled_by" integer,
+  "cancelled_at" timestamp,
+  "created_at" timestamp DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE "purchase_order_lines" (
+  "id_line" SERIAL PRIMARY KEY,
+  "id_purchase_order" integer NOT NULL,
+  "id_product" integer NOT NULL,
+  "id_variant" integer NOT NULL,
+  "quantity" integer NOT NULL CHECK ("quantity" > 0),
+  "received" integer NOT NULL DEFAULT 0 CHECK ("received" >= 0),
+  "unit_cost" numeric(16,2) NOT NULL CHECK ("unit_cost" >= 0),
+  UNIQUE ("id_purchase_order", "id_variant"),
+  CONSTRAINT "purchase_order_lines_received_check" CHECK ("received" <= "quantity")
+);
+
+-- what arrived per delivery, at the cost actually paid
+CREATE TABLE "goods_receipts" (
+  "id_receipt" SERIAL PRIMARY KEY,
+  "id_purchase_order" integer NOT NULL,
+  "id_location" integer NOT NULL,
+  "note" varchar NOT NULL DEFAULT '',
+  "received_by" integer,
+  "created_at" timestamp DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE "goods_receipt_items" (
+  "id_item" SERIAL PRIMARY KEY,
+  "id_receipt" integer NOT NULL,
+  "id_line" integer NOT NULL,
+  "id_product" integer NOT NULL,
+  "id_variant" integer NOT NULL,
+  "quantity" integer NOT NULL CHECK ("quantity" > 0),
+  "unit_cost" numeric(16,2) NOT NULL CHECK ("unit_cost" >= 0)
+);
+
+CREATE INDEX "purchase_orders_status" ON "purchase_orders" ("status", "id_purchase_order");
+CREATE INDEX "purchase_orders_supplier" ON "purchase_orders" ("id_supplier", "id_purchase_order");
+CREATE INDEX "purchase_order_lines_product" ON "purchase_order_lines" ("id_product");
+CREATE INDEX "goods_receipts_purchase_order" ON "goods_receipts" ("id_purchase_order");
+CREATE INDEX "goods_receipt_items_receipt" ON "goods_receipt_items" ("id_receipt");
+
+ALTER TABLE "purchase_orders" ADD FOREIGN KEY ("id_supplier") REFERENCES "suppliers" ("id_supplier");
+ALTER TABLE "purchase_orders" ADD FOREIGN KEY ("id_location") REFERENCES "locations" ("id_location");
+ALTER TABLE "purchase_orders" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("user_id");
+ALTER TABLE "purchase_orders" ADD FOREIGN KEY ("sent_by") REFERENCES "users" ("user_id");
+ALTER TABLE "purchase_orders" ADD FOREIGN KEY ("cancelled_by") REFERENCES "users" ("user_id");
+ALTER TABLE "purchase_order_lines" ADD FOREIGN KEY ("id_purchase_order") REFERENCES "purchase_orders" ("id_purchase_order") ON DELETE CASCADE;
+ALTER TABLE "goods_receipts" ADD FOREIGN KEY ("id_purchase_order") REFERENCES "purchase_orders" ("id_purchase_order");
+ALTER TABLE "goods_receipts" ADD FOREIGN KEY ("id_location") REFERENCES "locations" ("id_location");
+ALTER TABLE "goods_receipts" ADD FOREIGN KEY ("received_by") REFERENCES "users" ("user_id");
+ALTER TABLE "goods_receipt_items" ADD FOREIGN KEY ("id_receipt") REFERENCES "goods_receipts" ("id_receipt") ON DELETE CASCADE;
+ALTER TABLE "goods_receipt_items" ADD FOREIGN KEY ("id_line") REFERENCES "purchase_order_lines" ("id_line");
+
+COMMIT;
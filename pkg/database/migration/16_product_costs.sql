@@ -0,0 +1,20 @@
+BEGIN;
+
+-- existing products have no known cost until the next goods receipt
+ALTER TABLE "products" ADD COLUMN "cost" numeric(16,2) NOT NULL DEFAULT 0 CHECK ("cost" >= 0);
+
+-- items keep what they were sold as, including the product's cost and
+-- category at the time, since products may change or be deleted later
+ALTER TABLE "checkout_items"
+  ADD COLUMN "cost" numeric(16,2) NOT NULL DEFAULT 0,
+  ADD COLUMN "category" varchar NOT NULL DEFAULT '';
+
+-- past sales take the category their product has now
+UPDATE "checkout_items" i
+SET "category" = p."category"
+FROM "products" p
+WHERE p."id_product" = i."id_product";
+
+CREATE INDEX "checkouts_created_at" ON "checkouts" ("created_at");
+
+COMMIT;
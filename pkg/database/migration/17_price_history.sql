@@ -0,0 +1,79 @@
+BEGIN;
+
+-- every price a product or variant had; old_price is NULL for the first
+-- price and price is NULL when a variant falls back to the product price.
+-- Like the stock ledger, the history outlives deleted products and
+-- variants, so it has no foreign keys to them.
+CREATE TABLE "price_history" (
+  "id_price_change" BIGSERIAL PRIMARY KEY,
+  "id_product" integer NOT NULL,
+  "id_variant" integer,
+  "old_price" numeric(16,2),
+  "price" numeric(16,2),
+  "id_schedule" integer,
+  "user_id" integer,
+  "created_at" timestamp DEFAULT CURRENT_TIMESTAMP
+);
+
+-- future prices of a product, or of one variant when id_variant is set;
+-- kept with the history, pending ones are cancelled when their product or
+-- variant is deleted
+CREATE TABLE "price_schedules" (
+  "id_schedule" SERIAL PRIMARY KEY,
+  "id_product" integer NOT NULL,
+  "id_variant" integer,
+  "price" numeric(16,2) NOT NULL CHECK ("price" > 0),
+  "effective_at" timestamp NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending' CHECK ("status" IN ('pending', 'applied', 'cancelled')),
+  "note" varchar NOT NULL DEFAULT '',
+  "created_by" integer,
+  "applied_at" timestamp,
+  "cancelled_by" integer,
+  "cancelled_at" timestamp,
+  "created_at" timestamp DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "price_history_product" ON "price_history" ("id_product", "id_price_change");
+CREATE INDEX "price_schedules_due" ON "price_schedules" ("effective_at") WHERE "status" = 'pending';
+CREATE INDEX "price_schedules_product" ON "price_schedules" ("id_product", "effective_at");
+
+ALTER TABLE "price_history" ADD FOREIGN KEY ("id_schedule") REFERENCES "price_schedules" ("id_schedule");
+ALTER TABLE "price_history" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("user_id");
+ALTER TABLE "price_schedules" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("user_id");
+ALTER TABLE "price_schedules" ADD FOREIGN KEY ("cancelled_by") REFERENCES "users" ("user_id");
+
+-- a deleted product or variant takes no more price changes
+CREATE FUNCTION "cancel_price_schedules"() RETURNS trigger AS $$
+BEGIN
+  IF TG_TABLE_NAME = 'products' THEN
+    UPDATE "price_schedules"
+    SET status = 'cancelled', cancelled_at = CURRENT_TIMESTAMP
+    WHERE id_product = OLD.id_product AND status = 'pending';
+  ELSE
+    UPDATE "price_schedules"
+    SET status = 'cancelled', cancelled_at = CURRENT_TIMESTAMP
+    WHERE id_variant = OLD.id_variant AND status = 'pending';
+  END IF;
+  RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "products_cancel_price_schedules" AFTER DELETE ON "products"
+  FOR EACH ROW EXECUTE FUNCTION "cancel_price_schedules"();
+
+CREATE TRIGGER "product_variants_cancel_price_schedules" AFTER DELETE ON "product_variants"
+  FOR EACH ROW EXECUTE FUNCTION "cancel_price_schedules"();
+
+-- current prices become the first entry of the history
+INSERT INTO "price_history" ("id_product", "price", "user_id", "created_at")
+SELECT "id_product", "price", "user_id", COALESCE("updated_at", "created_at")
+FROM "products"
+ORDER BY "id_product";
+
+INSERT INTO "price_history" ("id_product", "id_variant", "price", "created_at")
+SELECT "id_product", "id_variant", "price", COALESCE("updated_at", "created_at")
+FROM "product_variants"
+WHERE "price" IS NOT NULL
+ORDER BY "id_variant";
+
+COMMIT;
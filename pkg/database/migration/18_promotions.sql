@@ -0,0 +1,55 @@
+BEGIN;
+
+-- past sales had no discounts
+ALTER TABLE "checkouts" ADD COLUMN "discount" numeric(16,2) NOT NULL DEFAULT 0;
+
+-- discount is the total of the item's checkout_discounts
+ALTER TABLE "checkout_items" ADD COLUMN "discount" numeric(16,2) NOT NULL DEFAULT 0;
+
+-- percent, amount, the quantities and min_spend are set as the type needs
+-- them; product_ids and categories hold the scope's targets
+CREATE TABLE "promotions" (
+  "id_promotion" SERIAL PRIMARY KEY,
+  "name" varchar NOT NULL,
+  "code" varchar UNIQUE,
+  "type" varchar NOT NULL CHECK ("type" IN ('percentage', 'fixed', 'bogo', 'bundle', 'min_spend')),
+  "scope" varchar NOT NULL CHECK ("scope" IN ('product', 'category', 'cart')),
+  "product_ids" jsonb NOT NULL DEFAULT '[]',
+  "categories" jsonb NOT NULL DEFAULT '[]',
+  "percent" integer CHECK ("percent" BETWEEN 1 AND 100),
+  "amount" numeric(16,2) CHECK ("amount" > 0),
+  "buy_quantity" integer CHECK ("buy_quantity" > 0),
+  "get_quantity" integer CHECK ("get_quantity" > 0),
+  "bundle_quantity" integer CHECK ("bundle_quantity" > 1),
+  "min_spend" numeric(16,2) CHECK ("min_spend" > 0),
+  "priority" integer NOT NULL DEFAULT 0,
+  "stackable" boolean NOT NULL DEFAULT true,
+  "starts_at" timestamp NOT NULL,
+  "ends_at" timestamp,
+  "is_active" boolean NOT NULL DEFAULT true,
+  "created_by" integer,
+  "created_at" timestamp DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT "promotions_window_check" CHECK ("ends_at" IS NULL OR "ends_at" > "starts_at")
+);
+
+-- the promotions applied to a sold item, kept as they were at the sale
+CREATE TABLE "checkout_discounts" (
+  "id_discount" SERIAL PRIMARY KEY,
+  "id_item" integer NOT NULL,
+  "id_promotion" integer,
+  "name" varchar NOT NULL,
+  "type" varchar NOT NULL,
+  "quantity" integer NOT NULL,
+  "amount" numeric(16,2) NOT NULL
+);
+
+CREATE INDEX "promotions_starts_at" ON "promotions" ("starts_at") WHERE "is_active";
+CREATE INDEX "checkout_discounts_item" ON "checkout_discounts" ("id_item");
+CREATE INDEX "checkout_discounts_promotion" ON "checkout_discounts" ("id_promotion");
+
+ALTER TABLE "promotions" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("user_id");
+ALTER TABLE "checkout_discounts" ADD FOREIGN KEY ("id_item") REFERENCES "checkout_items" ("id_item") ON DELETE CASCADE;
+ALTER TABLE "checkout_discounts" ADD FOREIGN KEY ("id_promotion") REFERENCES "promotions" ("id_promotion") ON DELETE SET NULL;
+
+COMMIT;
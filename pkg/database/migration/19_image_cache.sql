@@ -0,0 +1,22 @@
+BEGIN;
+
+-- the verifier revalidates its cached copy with If-None-Match instead of
+-- downloading it again, and deletes the copy of a replaced image URL
+ALTER TABLE "products"
+  ADD COLUMN "image_etag" text,
+  ADD COLUMN "stale_cached_image_url" text;
+
+CREATE OR REPLACE FUNCTION "reset_image_check"() RETURNS trigger AS $$
+BEGIN
+  NEW.image_status := 'pending';
+  NEW.image_checked_at := NULL;
+  NEW.image_error := NULL;
+  NEW.image_etag := NULL;
+  -- kept until the verifier has deleted the object
+  NEW.stale_cached_image_url := COALESCE(OLD.cached_image_url, OLD.stale_cached_image_url);
+  NEW.cached_image_url := NULL;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
@@ -0,0 +1,161 @@
+package imaging
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"io"
+	"net/http"
+	"strings"
+
+	// register decoders for image.Decode
+	_ "image/gif"
+	_ "image/png"
+)
+
+var (
+	ErrNotImage          = errors.New("imaging: not an image")
+	ErrUnsupportedFormat = errors.New("imaging: unsupported image format")
+	ErrTooManyPixels     = errors.New("imaging: image dimensions too large")
+)
+
+// MaxPixels guards against small files that decode to huge images. A 12 MP
+// image, enough for phone photos, decodes to about 48 MB.
+const MaxPixels = 12_000_000
+
+// ContentTypes lists the accepted image types by sniffed content type.
+var ContentTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// Info describes an image validated by Inspect.
+type Info struct {
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+}
+
+// Inspect sniffs the content type of r instead of trusting the client and
+// checks that its header decodes. Only the header is read.
+func Inspect(r io.Reader) (Info, error) {
+	reader := bufio.NewReader(r)
+	head, err := reader.Peek(512)
+	if err != nil && err != io.EOF {
+		return Info{}, err
+	}
+
+	contentType := http.DetectContentType(head)
+	extension, ok := ContentTypes[contentType]
+	if !ok {
+		if strings.HasPrefix(contentType, "image/") {
+			return Info{}, ErrUnsupportedFormat
+		}
+		return Info{}, ErrNotImage
+	}
+
+	config, _, err := image.DecodeConfig(reader)
+	if err != nil {
+		return Info{}, ErrNotImage
+	}
+	if int64(config.Width)*int64(config.Height) > MaxPixels {
+		return Info{}, ErrTooManyPixels
+	}
+
+	return Info{
+		ContentType: contentType,
+		Extension:   extension,
+		Width:       config.Width,
+		Height:      config.Height,
+	}, nil
+}
+
+// Thumbnail decodes r, which must have passed Inspect, and returns a JPEG no
+// larger than maxSize on either side, keeping the aspect ratio. Transparency
+// is flattened onto white.
+func Thumbnail(r io.Reader, maxSize int) ([]byte, error) {
+	src, _, err := image.Decode(r)
+	if err != nil {
+		return nil, ErrNotImage
+	}
+
+	bounds := src.Bounds()
+	width, height := fit(bounds.Dx(), bounds.Dy(), maxSize)
+
+	var out bytes.Buffer
+	err = jpeg.Encode(&out, resize(src, width, height), &jpeg.Options{Quality: 80})
+	if err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func fit(width, height, maxSize int) (int, int) {
+	if width <= maxSize && height <= maxSize {
+		return width, height
+	}
+	if width >= height {
+		return maxSize, maxInt(1, height*maxSize/width)
+	}
+	return maxInt(1, width*maxSize/height), maxSize
+}
+
+// resize scales src with a box filter: every target pixel averages the
+// source pixels it covers, which is good enough for downscaling, and is
+// flattened onto white. Source rows are converted one at a time, so no full
+// size copy of src is made.
+func resize(src image.Image, width, height int) *image.RGBA {
+	bounds := src.Bounds()
+	row := image.NewRGBA(image.Rect(bounds.Min.X, 0, bounds.Max.X, 1))
+	sums := make([]uint32, 4*width)
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := maxInt(y0+1, bounds.Min.Y+(y+1)*bounds.Dy()/height)
+		for i := range sums {
+			sums[i] = 0
+		}
+
+		for sy := y0; sy < y1; sy++ {
+			// row holds premultiplied RGBA, so averaging weighs by alpha
+			draw.Draw(row, row.Rect, src, image.Pt(bounds.Min.X, sy), draw.Src)
+			for x := 0; x < width; x++ {
+				x0 := x * bounds.Dx() / width
+				x1 := maxInt(x0+1, (x+1)*bounds.Dx()/width)
+				for offset := 4 * x0; offset < 4*x1; offset += 4 {
+					sums[4*x] += uint32(row.Pix[offset])
+					sums[4*x+1] += uint32(row.Pix[offset+1])
+					sums[4*x+2] += uint32(row.Pix[offset+2])
+					sums[4*x+3] += uint32(row.Pix[offset+3])
+				}
+			}
+		}
+
+		for x := 0; x < width; x++ {
+			x0 := x * bounds.Dx() / width
+			x1 := maxInt(x0+1, (x+1)*bounds.Dx()/width)
+			n := uint32((x1 - x0) * (y1 - y0))
+			// over white: premultiplied colour plus the uncovered white
+			uncovered := 255 - sums[4*x+3]/n
+			offset := dst.PixOffset(x, y)
+			dst.Pix[offset] = uint8(sums[4*x]/n + uncovered)
+			dst.Pix[offset+1] = uint8(sums[4*x+1]/n + uncovered)
+			dst.Pix[offset+2] = uint8(sums[4*x+2]/n + uncovered)
+			dst.Pix[offset+3] = 255
+		}
+	}
+	return dst
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
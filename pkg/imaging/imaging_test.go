@@ -0,0 +1,82 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestInspect(t *testing.T) {
+	info, err := Inspect(bytes.NewReader(encodePNG(t, image.NewNRGBA(image.Rect(0, 0, 30, 20)))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ContentType != "image/png" || info.Extension != ".png" || info.Width != 30 || info.Height != 20 {
+		t.Errorf("Inspect() = %+v", info)
+	}
+
+	if _, err := Inspect(bytes.NewReader([]byte("<html></html>"))); !errors.Is(err, ErrNotImage) {
+		t.Errorf("Inspect(html) = %v, want %v", err, ErrNotImage)
+	}
+
+	// the header claims more pixels than allowed, nothing is decoded
+	huge := encodePNG(t, image.NewGray(image.Rect(0, 0, 1, 1)))
+	huge[16], huge[17], huge[18], huge[19] = 0, 0, 0x10, 0 // width 4096
+	huge[20], huge[21], huge[22], huge[23] = 0, 0, 0x10, 0 // height 4096
+	binary.BigEndian.PutUint32(huge[29:], crc32.ChecksumIEEE(huge[12:29]))
+	if _, err := Inspect(bytes.NewReader(huge)); !errors.Is(err, ErrTooManyPixels) {
+		t.Errorf("Inspect(4096x4096) = %v, want %v", err, ErrTooManyPixels)
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	// left half opaque red, right half transparent
+	src := image.NewNRGBA(image.Rect(0, 0, 400, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 200; x++ {
+			src.Set(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+
+	data, err := Thumbnail(bytes.NewReader(encodePNG(t, src)), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thumb, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bounds := thumb.Bounds(); bounds.Dx() != 100 || bounds.Dy() != 50 {
+		t.Fatalf("thumbnail is %dx%d, want 100x50", bounds.Dx(), bounds.Dy())
+	}
+
+	near := func(got color.Color, r, g, b uint8) bool {
+		gr, gg, gb, _ := got.RGBA()
+		diff := func(a uint32, b uint8) bool {
+			d := int(a>>8) - int(b)
+			return d > -12 && d < 12
+		}
+		return diff(gr, r) && diff(gg, g) && diff(gb, b)
+	}
+	if c := thumb.At(20, 25); !near(c, 255, 0, 0) {
+		t.Errorf("opaque half = %v, want red", c)
+	}
+	if c := thumb.At(80, 25); !near(c, 255, 255, 255) {
+		t.Errorf("transparent half = %v, want white", c)
+	}
+}
@@ -0,0 +1,52 @@
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// MergePatch applies an RFC 7386 JSON Merge Patch to doc: object members of
+// patch replace those of doc recursively, null members are removed and
+// any non object patch replaces doc entirely.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var target interface{}
+	if err := decode(doc, &target); err != nil {
+		return nil, err
+	}
+
+	var patchValue interface{}
+	if err := decode(patch, &patchValue); err != nil {
+		return nil, ErrInvalidPatch
+	}
+
+	return json.Marshal(mergeValue(target, patchValue))
+}
+
+func mergeValue(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{})
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergeValue(targetObject[key], value)
+	}
+
+	return targetObject
+}
+
+// decode keeps numbers as json.Number so that values round trip exactly.
+func decode(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
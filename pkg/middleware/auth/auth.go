@@ -16,17 +16,25 @@ import (
 
 var JWT_SIGNING_METHOD = jwt.SigningMethodHS256
 
+// Staff roles, stored in users.role.
+const (
+	RoleStaff   = "staff"
+	RoleManager = "manager"
+)
+
 type JWTAuth struct {
 	ExpireTimeInMinute int
 	SecretKey          string
 	IsAuthorizedUser   func(context.Context, uint32) bool
+	UserRole           func(context.Context, uint32) (string, error)
 }
 
-func NewJwtAuth(expireTimeInMinute int, secretKey string, isAuthorizedUser func(context.Context, uint32) bool) JWTAuth {
+func NewJwtAuth(expireTimeInMinute int, secretKey string, isAuthorizedUser func(context.Context, uint32) bool, userRole func(context.Context, uint32) (string, error)) JWTAuth {
 	return JWTAuth{
 		ExpireTimeInMinute: expireTimeInMinute,
 		SecretKey:          secretKey,
 		IsAuthorizedUser:   isAuthorizedUser,
+		UserRole:           userRole,
 	}
 }
 
@@ -150,8 +158,7 @@ func (j JWTAuth) JwtAuthUserMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userId, err := j.TokenValid(c)
 		if err != nil {
-			respError := msg.UnwrapRespError(err)
-			c.JSON(respError.Code, respError)
+			c.Error(err)
 			c.Abort()
 			return
 		}
@@ -161,12 +168,44 @@ func (j JWTAuth) JwtAuthUserMiddleware() gin.HandlerFunc {
 	}
 }
 
+// RequireRole only lets users with one of roles through. The role is read
+// from the database on every request, so demotions apply immediately. It
+// must run after JwtAuthUserMiddleware.
+func (j JWTAuth) RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, err := GetUserIdInsideCtx(c)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		role, err := j.UserRole(c.Request.Context(), userId)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Set("userRole", role)
+				c.Next()
+				return
+			}
+		}
+
+		c.Error(msg.New(msg.CodeForbidden, msg.ErrUnauthorizedAction))
+		c.Abort()
+	}
+}
+
 func GetUserIdInsideCtx(c *gin.Context) (uint32, error) {
 	rawUserId, exist := c.Get("userId")
 	if !exist {
 		return 0, &msg.RespError{
 			Code:    http.StatusBadRequest,
-			Message: "Can't retrieve userId inside context",
+			Message: msg.ErrCantGetUserIdCtx,
 		}
 	}
 
@@ -174,7 +213,7 @@ func GetUserIdInsideCtx(c *gin.Context) (uint32, error) {
 	if !ok {
 		return 0, &msg.RespError{
 			Code:    http.StatusBadRequest,
-			Message: "Can't parse userId from current context",
+			Message: msg.ErrCantParseUserId,
 		}
 	}
 
@@ -0,0 +1,258 @@
+package cors
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"projectsphere/eniqlo-store/config"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrCredentialsWithAnyOrigin rejects a policy that would let every website
+// make credentialed requests.
+var ErrCredentialsWithAnyOrigin = errors.New(`cors: CORS_ALLOW_CREDENTIALS cannot be combined with the "*" origin`)
+
+var (
+	defaultMethods = []string{
+		http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions,
+	}
+	defaultHeaders = []string{
+		"Accept", "Accept-Encoding", "Accept-Language", "Authorization", "Cache-Control", "Content-Disposition",
+		"Content-Length", "Content-Type", "If-Match", "Origin", "X-CSRF-Token", "X-Request-ID", "X-Requested-With",
+	}
+	defaultExposedHeaders = []string{
+		"ETag", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "X-Request-ID",
+	}
+)
+
+type Config struct {
+	// AllowedOrigins lists exact origins ("https://pos.example.com"),
+	// wildcard subdomains ("https://*.example.com") or "*" for any origin.
+	// A wildcard without a port matches its subdomains on any port.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	MaxAge           time.Duration
+	AllowCredentials bool
+}
+
+// ConfigFromEnv reads the policy from CORS_ALLOWED_ORIGINS,
+// CORS_ALLOWED_METHODS, CORS_ALLOWED_HEADERS, CORS_EXPOSED_HEADERS,
+// CORS_MAX_AGE and CORS_ALLOW_CREDENTIALS.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		AllowedOrigins:   config.GetStrings("CORS_ALLOWED_ORIGINS"),
+		AllowedMethods:   config.GetStrings("CORS_ALLOWED_METHODS"),
+		AllowedHeaders:   config.GetStrings("CORS_ALLOWED_HEADERS"),
+		ExposedHeaders:   config.GetStrings("CORS_EXPOSED_HEADERS"),
+		MaxAge:           config.GetDuration("CORS_MAX_AGE", 10*time.Minute),
+		AllowCredentials: config.GetBool("CORS_ALLOW_CREDENTIALS", false),
+	}
+	if len(cfg.AllowedMethods) == 0 {
+		cfg.AllowedMethods = defaultMethods
+	}
+	if len(cfg.AllowedHeaders) == 0 {
+		cfg.AllowedHeaders = defaultHeaders
+	}
+	if len(cfg.ExposedHeaders) == 0 {
+		cfg.ExposedHeaders = defaultExposedHeaders
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate rejects credentials together with the "*" origin: the origin is
+// echoed back for credentialed requests, so any website could read responses
+// with the user's cookies or tokens.
+func (cfg Config) Validate() error {
+	if !cfg.AllowCredentials {
+		return nil
+	}
+	for _, origin := range cfg.AllowedOrigins {
+		if strings.TrimSpace(origin) == "*" {
+			return ErrCredentialsWithAnyOrigin
+		}
+	}
+	return nil
+}
+
+type policy struct {
+	cfg            Config
+	anyOrigin      bool
+	origins        map[string]bool
+	suffixes       []originSuffix
+	methods        map[string]bool
+	headers        map[string]bool
+	allowMethods   string
+	allowHeaders   string
+	exposedHeaders string
+	maxAge         string
+}
+
+type originSuffix struct {
+	scheme string
+	suffix string
+	// port is empty when any port is allowed
+	port string
+}
+
+// Middleware answers preflight requests and adds CORS headers for allowed
+// origins. The matching origin is echoed back instead of "*" so that
+// credentialed requests are accepted by browsers. It panics if cfg is invalid.
+func Middleware(cfg Config) gin.HandlerFunc {
+	if err := cfg.Validate(); err != nil {
+		panic(err.Error())
+	}
+	p := newPolicy(cfg)
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if preflight {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		if !p.isOriginAllowed(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if preflight {
+			p.handlePreflight(c, origin)
+			return
+		}
+
+		p.setOriginHeaders(c, origin)
+		if len(p.cfg.ExposedHeaders) > 0 {
+			c.Header("Access-Control-Expose-Headers", p.exposedHeaders)
+		}
+		c.Next()
+	}
+}
+
+func newPolicy(cfg Config) policy {
+	p := policy{
+		cfg:            cfg,
+		origins:        make(map[string]bool),
+		methods:        make(map[string]bool),
+		headers:        make(map[string]bool),
+		allowMethods:   strings.Join(cfg.AllowedMethods, ", "),
+		allowHeaders:   strings.Join(cfg.AllowedHeaders, ", "),
+		exposedHeaders: strings.Join(cfg.ExposedHeaders, ", "),
+		maxAge:         strconv.Itoa(int(cfg.MaxAge.Seconds())),
+	}
+
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimSuffix(origin, "/"))
+		switch {
+		case origin == "*":
+			p.anyOrigin = true
+		case strings.Contains(origin, "://*."):
+			scheme, host, port, ok := splitOrigin(strings.Replace(origin, "://*.", "://", 1))
+			if ok {
+				p.suffixes = append(p.suffixes, originSuffix{scheme: scheme, suffix: "." + host, port: port})
+			}
+		default:
+			p.origins[origin] = true
+		}
+	}
+	for _, method := range cfg.AllowedMethods {
+		p.methods[strings.ToUpper(method)] = true
+	}
+	for _, header := range cfg.AllowedHeaders {
+		p.headers[http.CanonicalHeaderKey(header)] = true
+	}
+
+	return p
+}
+
+func (p policy) isOriginAllowed(origin string) bool {
+	origin = strings.ToLower(origin)
+	if p.anyOrigin || p.origins[origin] {
+		return true
+	}
+
+	scheme, host, port, ok := splitOrigin(origin)
+	if !ok {
+		return false
+	}
+	for _, s := range p.suffixes {
+		if scheme != s.scheme || (s.port != "" && port != s.port) {
+			continue
+		}
+		// the wildcard must cover at least one label: "https://*.example.com"
+		// does not match "https://example.com"
+		if strings.HasSuffix(host, s.suffix) && len(host) > len(s.suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitOrigin splits "scheme://host[:port]", rejecting anything with a path.
+func splitOrigin(origin string) (scheme, host, port string, ok bool) {
+	scheme, host, ok = strings.Cut(origin, "://")
+	if !ok || host == "" || strings.ContainsAny(host, "/?#@") {
+		return "", "", "", false
+	}
+	if i := strings.LastIndex(host, ":"); i > strings.LastIndex(host, "]") {
+		h, p, err := net.SplitHostPort(host)
+		if err != nil || p == "" {
+			return "", "", "", false
+		}
+		host, port = h, p
+	}
+	return scheme, host, port, true
+}
+
+func (p policy) handlePreflight(c *gin.Context, origin string) {
+	method := strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))
+	if !p.methods[method] {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	for _, header := range strings.Split(c.GetHeader("Access-Control-Request-Headers"), ",") {
+		header = strings.TrimSpace(header)
+		if header != "" && !p.headers[http.CanonicalHeaderKey(header)] {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+	}
+
+	p.setOriginHeaders(c, origin)
+	c.Header("Access-Control-Allow-Methods", p.allowMethods)
+	c.Header("Access-Control-Allow-Headers", p.allowHeaders)
+	if p.cfg.MaxAge > 0 {
+		c.Header("Access-Control-Max-Age", p.maxAge)
+	}
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+func (p policy) setOriginHeaders(c *gin.Context, origin string) {
+	if p.anyOrigin && !p.cfg.AllowCredentials {
+		c.Header("Access-Control-Allow-Origin", "*")
+		return
+	}
+
+	c.Header("Access-Control-Allow-Origin", origin)
+	if p.cfg.AllowCredentials {
+		c.Header("Access-Control-Allow-Credentials", "true")
+	}
+}
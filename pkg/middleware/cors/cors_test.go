@@ -0,0 +1,162 @@
+package cors
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestRouter(cfg Config) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	if len(cfg.AllowedMethods) == 0 {
+		cfg.AllowedMethods = defaultMethods
+	}
+	if len(cfg.AllowedHeaders) == 0 {
+		cfg.AllowedHeaders = defaultHeaders
+	}
+	if len(cfg.ExposedHeaders) == 0 {
+		cfg.ExposedHeaders = defaultExposedHeaders
+	}
+
+	router := gin.New()
+	router.Use(Middleware(cfg))
+	router.GET("/v1/product", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	return router
+}
+
+func serve(router *gin.Engine, method, origin string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/v1/product", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestPreflight(t *testing.T) {
+	router := newTestRouter(Config{
+		AllowedOrigins:   []string{"https://pos.example.com", "https://*.shop.example.com"},
+		MaxAge:           10 * time.Minute,
+		AllowCredentials: true,
+	})
+
+	tests := []struct {
+		name       string
+		origin     string
+		method     string
+		headers    string
+		wantStatus int
+		wantOrigin string
+	}{
+		{name: "exact origin", origin: "https://pos.example.com", method: "PATCH", headers: "Authorization, If-Match", wantStatus: http.StatusNoContent, wantOrigin: "https://pos.example.com"},
+		{name: "wildcard subdomain", origin: "https://a.shop.example.com", method: "GET", wantStatus: http.StatusNoContent, wantOrigin: "https://a.shop.example.com"},
+		{name: "wildcard subdomain with port", origin: "https://a.shop.example.com:8443", method: "GET", wantStatus: http.StatusNoContent, wantOrigin: "https://a.shop.example.com:8443"},
+		{name: "wildcard does not match apex", origin: "https://shop.example.com", method: "GET", wantStatus: http.StatusForbidden},
+		{name: "wildcard checks the scheme", origin: "http://a.shop.example.com", method: "GET", wantStatus: http.StatusForbidden},
+		{name: "suffix is not a subdomain", origin: "https://evilshop.example.com", method: "GET", wantStatus: http.StatusForbidden},
+		{name: "unknown origin", origin: "https://evil.example.org", method: "GET", wantStatus: http.StatusForbidden},
+		{name: "method not allowed", origin: "https://pos.example.com", method: "TRACE", wantStatus: http.StatusForbidden},
+		{name: "header not allowed", origin: "https://pos.example.com", method: "GET", headers: "X-Secret", wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(router, http.MethodOptions, tt.origin, map[string]string{
+				"Access-Control-Request-Method":  tt.method,
+				"Access-Control-Request-Headers": tt.headers,
+			})
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if tt.wantStatus != http.StatusNoContent {
+				return
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+				t.Errorf("Access-Control-Allow-Credentials = %q, want true", got)
+			}
+			if got := rec.Header().Get("Access-Control-Max-Age"); got != "600" {
+				t.Errorf("Access-Control-Max-Age = %q, want 600", got)
+			}
+			if rec.Header().Get("Access-Control-Allow-Methods") == "" || rec.Header().Get("Access-Control-Allow-Headers") == "" {
+				t.Errorf("preflight is missing the allowed methods or headers: %v", rec.Header())
+			}
+		})
+	}
+}
+
+func TestSimpleRequest(t *testing.T) {
+	router := newTestRouter(Config{AllowedOrigins: []string{"https://pos.example.com"}})
+
+	rec := serve(router, http.MethodGet, "https://pos.example.com", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://pos.example.com" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want the request origin", got)
+	}
+	if got := rec.Header().Get("Access-Control-Expose-Headers"); got == "" {
+		t.Error("Access-Control-Expose-Headers is not set")
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Access-Control-Allow-Credentials = %q without AllowCredentials", got)
+	}
+	if got := rec.Header().Values("Vary"); len(got) == 0 || got[0] != "Origin" {
+		t.Errorf("Vary = %v, want Origin", got)
+	}
+
+	// a disallowed origin still reaches the handler, the browser blocks the
+	// response because it has no CORS headers
+	rec = serve(router, http.MethodGet, "https://evil.example.org", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q for a disallowed origin", got)
+	}
+
+	// same origin and non browser requests carry no Origin header
+	rec = serve(router, http.MethodGet, "", nil)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q without Origin", got)
+	}
+}
+
+func TestAnyOrigin(t *testing.T) {
+	router := newTestRouter(Config{AllowedOrigins: []string{"*"}})
+
+	rec := serve(router, http.MethodGet, "https://anything.example.org", nil)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want *", got)
+	}
+}
+
+func TestValidateRejectsCredentialsWithAnyOrigin(t *testing.T) {
+	cfg := Config{AllowedOrigins: []string{"https://pos.example.com", "*"}, AllowCredentials: true}
+	if err := cfg.Validate(); !errors.Is(err, ErrCredentialsWithAnyOrigin) {
+		t.Errorf("Validate() = %v, want %v", err, ErrCredentialsWithAnyOrigin)
+	}
+
+	cfg.AllowCredentials = false
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() without credentials = %v, want nil", err)
+	}
+
+	t.Setenv("CORS_ALLOWED_ORIGINS", "*")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+	if _, err := ConfigFromEnv(); !errors.Is(err, ErrCredentialsWithAnyOrigin) {
+		t.Errorf("ConfigFromEnv() = %v, want %v", err, ErrCredentialsWithAnyOrigin)
+	}
+}
@@ -0,0 +1,104 @@
+package errorhandler
+
+import (
+	"fmt"
+	"net/http"
+	"projectsphere/eniqlo-store/config"
+	"projectsphere/eniqlo-store/pkg/middleware/logger"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"runtime/debug"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	problemContentType = "application/problem+json"
+	problemTypePrefix  = "urn:eniqlo-store:problem:"
+)
+
+// Problem is an RFC 7807 problem details document.
+type Problem struct {
+	Type      string           `json:"type"`
+	Title     string           `json:"title"`
+	Status    int              `json:"status"`
+	Detail    string           `json:"detail,omitempty"`
+	Instance  string           `json:"instance,omitempty"`
+	ErrorCode msg.ErrorCode    `json:"errorCode"`
+	Details   []msg.FieldError `json:"details,omitempty"`
+	RequestID string           `json:"requestId,omitempty"`
+}
+
+// Middleware renders the last error handlers attached with c.Error, unless
+// a response has already been written. Errors are rendered as RespError
+// JSON, or as problem+json when ERROR_FORMAT=problem or when the client
+// asks for it in Accept.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		Render(c, c.Errors.Last().Err)
+	}
+}
+
+// Recovery turns panics into internal server errors rendered like any
+// other error.
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				logger.FromContext(c.Request.Context()).Error().
+					Interface("panic", rec).
+					Bytes("stack", debug.Stack()).
+					Msg("recovered from panic")
+
+				err := msg.InternalServerError(fmt.Sprint(rec))
+				c.Error(err)
+				if !c.Writer.Written() {
+					Render(c, err)
+				}
+				c.Abort()
+			}
+		}()
+
+		c.Next()
+	}
+}
+
+// Render writes err as the response and aborts the chain.
+func Render(c *gin.Context, err error) {
+	respError := msg.UnwrapRespError(err)
+	if respError.Code >= http.StatusInternalServerError {
+		logger.FromContext(c.Request.Context()).Error().Err(err).Msg("request failed")
+	}
+	respError = respError.Localize(msg.LanguageFromContext(c.Request.Context()))
+
+	if wantsProblem(c) {
+		problem := Problem{
+			Type:      problemTypePrefix + strings.ToLower(strings.ReplaceAll(string(respError.ErrorCode), "_", "-")),
+			Title:     http.StatusText(respError.Code),
+			Status:    respError.Code,
+			Detail:    respError.Message,
+			Instance:  c.Request.URL.Path,
+			ErrorCode: respError.ErrorCode,
+			Details:   respError.Details,
+			RequestID: c.GetString(logger.RequestIDKey),
+		}
+		c.Render(respError.Code, problemRender{problem})
+		c.Abort()
+		return
+	}
+
+	c.AbortWithStatusJSON(respError.Code, respError)
+}
+
+func wantsProblem(c *gin.Context) bool {
+	if strings.EqualFold(config.GetString("ERROR_FORMAT"), "problem") {
+		return true
+	}
+	return strings.Contains(c.GetHeader("Accept"), problemContentType)
+}
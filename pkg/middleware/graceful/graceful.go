@@ -2,49 +2,175 @@ package graceful
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"os/signal"
-	"sync"
 	"syscall"
 	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
+// ErrForcedShutdown is returned by Run when at least one component did not
+// stop within its timeout.
+var ErrForcedShutdown = errors.New("forced shutdown")
+
 type Operation func(ctx context.Context) error
 
-func GracefulShutdown(ctx context.Context, timeout time.Duration, operations map[string]Operation) {
-	if len(operations) == 0 {
-		return
+type Component struct {
+	Name string
+	// Start must return once the component is ready to serve. Optional.
+	Start Operation
+	// Stop releases the component. Optional.
+	Stop Operation
+	// Timeout bounds Stop. Zero uses the lifecycle default.
+	Timeout time.Duration
+	// Failed reports a fatal error of a running component, triggering a
+	// shutdown. Optional.
+	Failed <-chan error
+}
+
+// Lifecycle starts components in the order they were appended, waits for a
+// signal or a component failure, then stops them in reverse order.
+type Lifecycle struct {
+	signals        <-chan os.Signal
+	defaultTimeout time.Duration
+	components     []Component
+	fatal          chan error
+}
+
+func NewLifecycle(signals <-chan os.Signal, defaultTimeout time.Duration) *Lifecycle {
+	return &Lifecycle{
+		signals:        signals,
+		defaultTimeout: defaultTimeout,
+		fatal:          make(chan error, 1),
+	}
+}
+
+// NotifySignals returns a channel receiving SIGINT and SIGTERM.
+func NotifySignals() <-chan os.Signal {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	return signals
+}
+
+func (l *Lifecycle) Append(component Component) {
+	l.components = append(l.components, component)
+}
+
+// Fail requests a shutdown because of err. Only the first failure is kept.
+func (l *Lifecycle) Fail(err error) {
+	select {
+	case l.fatal <- err:
+	default:
+	}
+}
+
+// Run blocks until the lifecycle has shut down. It returns the start error,
+// the failure that caused the shutdown, the stop errors, or
+// ErrForcedShutdown, joined together; nil means a clean shutdown.
+func (l *Lifecycle) Run(ctx context.Context) error {
+	started := 0
+	var startErr error
+	for _, component := range l.components {
+		if component.Start != nil {
+			log.Info().Str("component", component.Name).Msg("starting")
+			if err := component.Start(ctx); err != nil {
+				startErr = fmt.Errorf("start %s: %w", component.Name, err)
+				break
+			}
+		}
+		l.watch(component)
+		started++
+	}
+
+	var cause error
+	if startErr != nil {
+		cause = startErr
+	} else {
+		select {
+		case sig := <-l.signals:
+			log.Warn().Str("signal", sig.String()).Msg("shutdown requested")
+		case err := <-l.fatal:
+			log.Error().Err(err).Msg("component failed, shutting down")
+			cause = err
+		case <-ctx.Done():
+			log.Warn().Msg("context cancelled, shutting down")
+		}
+	}
+
+	return errors.Join(cause, l.stop(l.components[:started]))
+}
+
+// ExitCode is the process exit status for the error returned by Run: any
+// error, including ErrForcedShutdown, is a failure.
+func ExitCode(err error) int {
+	if err != nil {
+		return 1
 	}
+	return 0
+}
 
-	wait := make(chan struct{})
+func (l *Lifecycle) watch(component Component) {
+	if component.Failed == nil {
+		return
+	}
 	go func() {
-		signalchan := make(chan os.Signal, 1)
-		signal.Notify(signalchan, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-		oscall := <-signalchan
-
-		timeAfterExecuted := time.AfterFunc(timeout, func() {
-			log.Warn().Msg("Force shutdown")
-			os.Exit(0)
-		})
-		defer timeAfterExecuted.Stop()
-
-		wg := sync.WaitGroup{}
-		wg.Add(len(operations))
-		for k, op := range operations {
-			go func(k string, op Operation) {
-				defer wg.Done()
-				log.Warn().Msgf("Shutdown %s", k)
-				err := op(ctx)
-				if err != nil {
-					log.Err(err).Msg("Error when stop server")
-				}
-			}(k, op)
+		if err, ok := <-component.Failed; ok && err != nil {
+			l.Fail(fmt.Errorf("%s: %w", component.Name, err))
+		}
+	}()
+}
+
+func (l *Lifecycle) stop(components []Component) error {
+	var errs []error
+	forced := false
+
+	for i := len(components) - 1; i >= 0; i-- {
+		component := components[i]
+		if component.Stop == nil {
+			continue
+		}
+
+		timeout := component.Timeout
+		if timeout <= 0 {
+			timeout = l.defaultTimeout
+		}
+
+		log.Warn().Str("component", component.Name).Msg("stopping")
+		err := stopWithTimeout(component.Stop, timeout)
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			log.Error().Str("component", component.Name).Dur("timeout", timeout).Msg("force shutdown")
+			forced = true
+		case err != nil:
+			log.Err(err).Str("component", component.Name).Msg("error when stopping")
+			errs = append(errs, fmt.Errorf("stop %s: %w", component.Name, err))
 		}
-		wg.Wait()
+	}
 
-		log.Warn().Msgf("system call:%+v", oscall)
-		close(wait)
+	if forced {
+		errs = append(errs, ErrForcedShutdown)
+	}
+	return errors.Join(errs...)
+}
+
+// stopWithTimeout gives stop a context bounded by timeout and abandons it
+// if it does not honour the deadline.
+func stopWithTimeout(stop Operation, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- stop(ctx)
 	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
@@ -0,0 +1,218 @@
+package graceful
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// recorder collects the lifecycle events of test components in order.
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) component(name string) Component {
+	return Component{
+		Name: name,
+		Start: func(context.Context) error {
+			r.record("start " + name)
+			return nil
+		},
+		Stop: func(context.Context) error {
+			r.record("stop " + name)
+			return nil
+		},
+	}
+}
+
+// runAsync runs l and returns a channel receiving its result.
+func runAsync(ctx context.Context, l *Lifecycle) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- l.Run(ctx)
+	}()
+	return done
+}
+
+func wait(t *testing.T, done <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("lifecycle did not shut down")
+		return nil
+	}
+}
+
+func TestStartInOrderStopInReverse(t *testing.T) {
+	signals := make(chan os.Signal, 1)
+	l := NewLifecycle(signals, time.Second)
+	r := &recorder{}
+	for _, name := range []string{"database", "worker", "http"} {
+		l.Append(r.component(name))
+	}
+
+	signals <- syscall.SIGTERM
+	err := wait(t, runAsync(context.Background(), l))
+	if err != nil {
+		t.Fatalf("Run() = %v, want a clean shutdown", err)
+	}
+	if code := ExitCode(err); code != 0 {
+		t.Errorf("ExitCode() = %d, want 0", code)
+	}
+
+	want := []string{"start database", "start worker", "start http", "stop http", "stop worker", "stop database"}
+	if !reflect.DeepEqual(r.events, want) {
+		t.Errorf("events = %v, want %v", r.events, want)
+	}
+}
+
+func TestStartFailureStopsStartedComponents(t *testing.T) {
+	l := NewLifecycle(make(chan os.Signal), time.Second)
+	r := &recorder{}
+	startErr := errors.New("address in use")
+
+	l.Append(r.component("database"))
+	l.Append(Component{
+		Name:  "http",
+		Start: func(context.Context) error { return startErr },
+		Stop: func(context.Context) error {
+			r.record("stop http")
+			return nil
+		},
+	})
+	l.Append(r.component("worker"))
+
+	err := wait(t, runAsync(context.Background(), l))
+	if !errors.Is(err, startErr) {
+		t.Errorf("Run() = %v, want %v", err, startErr)
+	}
+
+	want := []string{"start database", "stop database"}
+	if !reflect.DeepEqual(r.events, want) {
+		t.Errorf("events = %v, want %v", r.events, want)
+	}
+}
+
+func TestComponentFailureShutsDown(t *testing.T) {
+	l := NewLifecycle(make(chan os.Signal), time.Second)
+	r := &recorder{}
+	failed := make(chan error, 1)
+
+	l.Append(r.component("database"))
+	server := r.component("http")
+	server.Failed = failed
+	l.Append(server)
+
+	serveErr := errors.New("listener closed")
+	failed <- serveErr
+	err := wait(t, runAsync(context.Background(), l))
+	if !errors.Is(err, serveErr) {
+		t.Errorf("Run() = %v, want %v", err, serveErr)
+	}
+
+	want := []string{"start database", "start http", "stop http", "stop database"}
+	if !reflect.DeepEqual(r.events, want) {
+		t.Errorf("events = %v, want %v", r.events, want)
+	}
+}
+
+func TestPerComponentTimeout(t *testing.T) {
+	signals := make(chan os.Signal, 1)
+	l := NewLifecycle(signals, time.Hour)
+	r := &recorder{}
+	block := make(chan struct{})
+	defer close(block)
+
+	l.Append(r.component("database"))
+	l.Append(Component{
+		Name: "worker",
+		// ignores its context, so it has to be abandoned
+		Stop: func(context.Context) error {
+			<-block
+			return nil
+		},
+		Timeout: 20 * time.Millisecond,
+	})
+	var deadline time.Duration
+	l.Append(Component{
+		Name: "http",
+		Stop: func(ctx context.Context) error {
+			if d, ok := ctx.Deadline(); ok {
+				deadline = time.Until(d)
+			}
+			return nil
+		},
+	})
+
+	signals <- syscall.SIGTERM
+	start := time.Now()
+	err := wait(t, runAsync(context.Background(), l))
+
+	if !errors.Is(err, ErrForcedShutdown) {
+		t.Errorf("Run() = %v, want %v", err, ErrForcedShutdown)
+	}
+	if code := ExitCode(err); code == 0 {
+		t.Error("ExitCode() = 0 after a forced shutdown")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("shutdown took %v, the worker timeout is 20ms", elapsed)
+	}
+	if deadline < time.Minute {
+		t.Errorf("http got %v to stop, want the lifecycle default", deadline)
+	}
+	// components after a forced one are still stopped
+	if want := []string{"start database", "stop database"}; !reflect.DeepEqual(r.events, want) {
+		t.Errorf("events = %v, want %v", r.events, want)
+	}
+}
+
+func TestStopErrorsAreJoined(t *testing.T) {
+	signals := make(chan os.Signal, 1)
+	l := NewLifecycle(signals, time.Second)
+	stopErr := errors.New("flush failed")
+	l.Append(Component{
+		Name: "logger",
+		Stop: func(context.Context) error { return stopErr },
+	})
+
+	signals <- syscall.SIGINT
+	err := wait(t, runAsync(context.Background(), l))
+	if !errors.Is(err, stopErr) || errors.Is(err, ErrForcedShutdown) {
+		t.Errorf("Run() = %v, want %v only", err, stopErr)
+	}
+	if code := ExitCode(err); code == 0 {
+		t.Error("ExitCode() = 0 after a stop error")
+	}
+}
+
+func TestContextCancellationShutsDown(t *testing.T) {
+	l := NewLifecycle(make(chan os.Signal), time.Second)
+	r := &recorder{}
+	l.Append(r.component("http"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := runAsync(ctx, l)
+	cancel()
+	if err := wait(t, done); err != nil {
+		t.Errorf("Run() = %v, want a clean shutdown", err)
+	}
+
+	want := []string{"start http", "stop http"}
+	if !reflect.DeepEqual(r.events, want) {
+		t.Errorf("events = %v, want %v", r.events, want)
+	}
+}
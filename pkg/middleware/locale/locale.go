@@ -0,0 +1,79 @@
+package locale
+
+import (
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// aliases maps language tags we accept to a supported language. "in" is the
+// deprecated ISO 639 code for Indonesian still sent by older Android builds.
+var aliases = map[string]msg.Language{
+	"en": msg.English,
+	"id": msg.Indonesian,
+	"in": msg.Indonesian,
+}
+
+// Middleware negotiates the response language from Accept-Language and
+// stores it in the request context for msg.T and the error renderer.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lang := Negotiate(c.GetHeader("Accept-Language"))
+		c.Request = c.Request.WithContext(msg.WithLanguage(c.Request.Context(), lang))
+		c.Header("Content-Language", string(lang))
+		c.Next()
+	}
+}
+
+type weightedTag struct {
+	tag    string
+	weight float64
+	order  int
+}
+
+// Negotiate picks the supported language with the highest quality value in
+// an Accept-Language header, falling back to msg.DefaultLanguage.
+func Negotiate(header string) msg.Language {
+	var tags []weightedTag
+	for i, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+
+		weight := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					weight = q
+				}
+			}
+		}
+		if weight <= 0 {
+			continue
+		}
+
+		tags = append(tags, weightedTag{tag: tag, weight: weight, order: i})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].weight > tags[j].weight
+	})
+
+	for _, t := range tags {
+		if t.tag == "*" {
+			return msg.DefaultLanguage
+		}
+		primary := strings.SplitN(t.tag, "-", 2)[0]
+		if lang, ok := aliases[primary]; ok {
+			return lang
+		}
+	}
+
+	return msg.DefaultLanguage
+}
@@ -1,47 +1,239 @@
 package logger
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"strings"
 	"time"
 
+	"projectsphere/eniqlo-store/config"
+
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
+const (
+	RequestIDHeader = "X-Request-ID"
+	RequestIDKey    = "requestId"
+
+	maxRequestIDLen = 128
+	maxLoggedBody   = 4096
+	redactedValue   = "[REDACTED]"
+)
+
+var sensitiveHeaders = map[string]bool{
+	"authorization": true,
+	"cookie":        true,
+	"set-cookie":    true,
+}
+
+var sensitiveFields = map[string]bool{
+	"password":    true,
+	"oldpassword": true,
+	"newpassword": true,
+	"salt":        true,
+	"accesstoken": true,
+	"token":       true,
+}
+
+var fileSink *RotatingFile
+
+// InitLogger configures the global zerolog logger from LOG_LEVEL
+// (trace, debug, info, warn, error; default info) and LOG_FORMAT
+// (json or console; default json). When LOG_FILE is set, JSON logs are
+// also written to a rotating file configured by LOG_MAX_SIZE_MB,
+// LOG_MAX_BACKUPS, LOG_MAX_AGE_DAYS and LOG_COMPRESS.
 func InitLogger() {
-	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
-	output := zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
-	log.Logger = log.Output(output)
+	zerolog.TimeFieldFormat = time.RFC3339Nano
+
+	level, err := zerolog.ParseLevel(strings.ToLower(config.GetString("LOG_LEVEL")))
+	if err != nil || level == zerolog.NoLevel {
+		level = zerolog.InfoLevel
+	}
+	zerolog.SetGlobalLevel(level)
+
+	var output io.Writer = os.Stdout
+	if strings.ToLower(config.GetString("LOG_FORMAT")) == "console" {
+		output = zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
+	}
+
+	if filename := config.GetString("LOG_FILE"); filename != "" {
+		sink, err := NewRotatingFile(RotateConfig{
+			Filename:   filename,
+			MaxSize:    int64(config.GetInt("LOG_MAX_SIZE_MB", 100)) * 1024 * 1024,
+			MaxBackups: config.GetInt("LOG_MAX_BACKUPS", 7),
+			MaxAge:     time.Duration(config.GetInt("LOG_MAX_AGE_DAYS", 30)) * 24 * time.Hour,
+			Compress:   config.GetBool("LOG_COMPRESS", true),
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "logger: file sink disabled: %v\n", err)
+		} else {
+			sink.ReopenOnSIGHUP()
+			fileSink = sink
+			output = zerolog.MultiLevelWriter(output, sink)
+		}
+	}
+
+	log.Logger = zerolog.New(output).With().Timestamp().Logger()
+	zerolog.DefaultContextLogger = &log.Logger
+}
+
+// Close flushes and closes the file sink, if any.
+func Close() error {
+	if fileSink == nil {
+		return nil
+	}
+	return fileSink.Close()
+}
+
+// FromContext returns the request scoped logger stored by Logger, falling
+// back to the global logger outside of a request.
+func FromContext(ctx context.Context) *zerolog.Logger {
+	return zerolog.Ctx(ctx)
 }
 
+// Logger assigns every request an id, exposes a request scoped logger
+// through the request context and writes one structured access log line
+// once the request is served.
 func Logger() gin.HandlerFunc {
-	return gin.LoggerWithFormatter(func(params gin.LogFormatterParams) string {
-		var statusColor, methodColor, resetColor string
-		statusColor = params.StatusCodeColor()
-		methodColor = params.MethodColor()
-		resetColor = params.ResetColor()
+	return func(c *gin.Context) {
+		start := time.Now()
 
-		if params.Latency > time.Minute {
-			params.Latency = params.Latency.Truncate(time.Second)
+		requestID := c.GetHeader(RequestIDHeader)
+		if !isValidRequestID(requestID) {
+			requestID = uuid.NewString()
 		}
+		c.Set(RequestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
 
-		return fmt.Sprintf("%v%s %s %s %s %s %3d %s Latency:%5v [GIN]\n",
-			params.TimeStamp.Format("2006-01-02T15:04:05+07:00"),
-			methodColor, params.Method, resetColor,
-			params.Path,
-			statusColor, params.StatusCode, resetColor,
-			params.Latency,
-		)
-	})
+		reqLogger := log.Logger.With().Str("requestId", requestID).Logger()
+		c.Request = c.Request.WithContext(reqLogger.WithContext(c.Request.Context()))
+
+		debug := zerolog.GlobalLevel() <= zerolog.DebugLevel
+
+		var body []byte
+		if debug && c.Request.Body != nil {
+			body, _ = io.ReadAll(io.LimitReader(c.Request.Body, maxLoggedBody))
+			c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(body), c.Request.Body), c.Request.Body}
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		event := reqLogger.WithLevel(levelForStatus(status))
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		event = event.
+			Str("method", c.Request.Method).
+			Str("route", route).
+			Str("path", c.Request.URL.Path).
+			Int("status", status).
+			Dur("latency", time.Since(start)).
+			Int("bytes", c.Writer.Size()).
+			Str("clientIp", c.ClientIP()).
+			Str("userAgent", c.Request.UserAgent())
+
+		if userID, ok := c.Get("userId"); ok {
+			event = event.Interface("userId", userID)
+		}
+		if len(c.Errors) > 0 {
+			event = event.Str("errors", c.Errors.String())
+		}
+		if debug {
+			event = event.Interface("headers", RedactHeaders(c.Request.Header))
+			if len(body) > 0 {
+				event = event.RawJSON("body", RedactJSON(body))
+			}
+		}
+
+		event.Msg("request served")
+	}
 }
 
-func GetLog() *os.File {
-	logData := fmt.Sprintf("gin_" + time.Now().Format("20060102") + ".log")
-	f, err := os.OpenFile(logData, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+// RedactHeaders returns a copy of header with credentials masked.
+func RedactHeaders(header http.Header) map[string]string {
+	redacted := make(map[string]string, len(header))
+	for key, values := range header {
+		if sensitiveHeaders[strings.ToLower(key)] {
+			redacted[key] = redactedValue
+			continue
+		}
+		redacted[key] = strings.Join(values, ", ")
+	}
+	return redacted
+}
+
+// RedactJSON masks password, salt and token fields at any depth of a JSON
+// document. Input that is not valid JSON is replaced entirely so it can
+// never leak into the logs.
+func RedactJSON(raw []byte) []byte {
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		quoted, _ := json.Marshal(redactedValue)
+		return quoted
+	}
+
+	redacted, err := json.Marshal(redactValue(doc))
 	if err != nil {
-		log.Warn().Msg(fmt.Sprintf("error opening file: %v", err))
+		quoted, _ := json.Marshal(redactedValue)
+		return quoted
+	}
+	return redacted
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, inner := range v {
+			if sensitiveFields[strings.ToLower(key)] {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactValue(inner)
+		}
+	case []interface{}:
+		for i, inner := range v {
+			v[i] = redactValue(inner)
+		}
+	}
+	return value
+}
+
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
 	}
-	return f
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func levelForStatus(status int) zerolog.Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return zerolog.ErrorLevel
+	case status >= http.StatusBadRequest:
+		return zerolog.WarnLevel
+	default:
+		return zerolog.InfoLevel
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
 }
@@ -0,0 +1,418 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	compressSuffix   = ".gz"
+	tmpSuffix        = ".tmp"
+	dayFormat        = "20060102"
+)
+
+type RotateConfig struct {
+	// Filename is the active log file; backups are created next to it.
+	Filename string
+	// MaxSize is the size in bytes after which the file is rotated. Zero
+	// disables size based rotation.
+	MaxSize int64
+	// MaxBackups is the number of rotated files to keep. Zero keeps all.
+	MaxBackups int
+	// MaxAge removes rotated files older than this. Zero keeps all.
+	MaxAge time.Duration
+	// Compress gzips rotated files.
+	Compress bool
+}
+
+// RotatingFile is an io.Writer that rotates the underlying file by size and
+// at the start of every day, and compresses and prunes old backups in the
+// background. Backups are named after the time their period started.
+type RotatingFile struct {
+	cfg RotateConfig
+	now func() time.Time
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	day    string
+	start  time.Time
+	closed bool
+
+	millCh   chan struct{}
+	millDone chan struct{}
+	stopHUP  func()
+}
+
+func NewRotatingFile(cfg RotateConfig) (*RotatingFile, error) {
+	if cfg.Filename == "" {
+		return nil, fmt.Errorf("log filename is required")
+	}
+
+	r := &RotatingFile{
+		cfg:      cfg,
+		now:      time.Now,
+		millCh:   make(chan struct{}, 1),
+		millDone: make(chan struct{}),
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+
+	go r.runMill()
+	r.triggerMill()
+
+	return r, nil
+}
+
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	sizeExceeded := r.cfg.MaxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.cfg.MaxSize
+	if sizeExceeded || r.now().Format(dayFormat) != r.day {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Reopen closes and reopens the active file so that an external tool such
+// as logrotate can move it away.
+func (r *RotatingFile) Reopen() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return os.ErrClosed
+	}
+	if err := r.closeFile(); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+// ReopenOnSIGHUP reopens the file every time the process receives SIGHUP
+// until Close is called.
+func (r *RotatingFile) ReopenOnSIGHUP() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-signals:
+				if err := r.Reopen(); err != nil {
+					fmt.Fprintf(os.Stderr, "logger: cannot reopen %s: %v\n", r.cfg.Filename, err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	r.mu.Lock()
+	r.stopHUP = func() {
+		signal.Stop(signals)
+		close(done)
+	}
+	r.mu.Unlock()
+}
+
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	stopHUP := r.stopHUP
+	r.stopHUP = nil
+	err := r.closeFile()
+	close(r.millCh)
+	r.mu.Unlock()
+
+	if stopHUP != nil {
+		stopHUP()
+	}
+	<-r.millDone
+
+	return err
+}
+
+func (r *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.cfg.Filename), 0755); err != nil {
+		return fmt.Errorf("cannot create log directory: %w", err)
+	}
+
+	f, err := os.OpenFile(r.cfg.Filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot open log file: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("cannot stat log file: %w", err)
+	}
+
+	r.file = f
+	r.size = info.Size()
+	r.day = r.now().Format(dayFormat)
+	r.start = r.now()
+	if r.size > 0 {
+		r.day = info.ModTime().Format(dayFormat)
+		r.start = r.resumedStart(info.ModTime())
+	}
+
+	return nil
+}
+
+// resumedStart estimates when an existing active file was started: at the
+// start of the day it was last written to, but after the newest backup.
+func (r *RotatingFile) resumedStart(modTime time.Time) time.Time {
+	year, month, day := modTime.Date()
+	start := time.Date(year, month, day, 0, 0, 0, 0, modTime.Location())
+
+	backups, err := r.backups()
+	if err == nil && len(backups) > 0 && !backups[0].timestamp.Before(start) {
+		start = backups[0].timestamp.Add(time.Millisecond)
+	}
+	return start
+}
+
+func (r *RotatingFile) closeFile() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func (r *RotatingFile) rotate() error {
+	if err := r.closeFile(); err != nil {
+		return err
+	}
+
+	previous := r.start
+	if r.size > 0 {
+		if err := os.Rename(r.cfg.Filename, r.backupName(previous)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("cannot rotate log file: %w", err)
+		}
+	}
+
+	if err := r.open(); err != nil {
+		return err
+	}
+	r.day = r.now().Format(dayFormat)
+	// backup names have millisecond precision and must stay unique
+	if !r.start.After(previous.Truncate(time.Millisecond)) {
+		r.start = previous.Truncate(time.Millisecond).Add(time.Millisecond)
+	}
+
+	r.triggerMill()
+	return nil
+}
+
+func (r *RotatingFile) backupName(t time.Time) string {
+	dir := filepath.Dir(r.cfg.Filename)
+	prefix, ext := r.prefixAndExt()
+	return filepath.Join(dir, prefix+t.Format(backupTimeFormat)+ext)
+}
+
+func (r *RotatingFile) prefixAndExt() (string, string) {
+	base := filepath.Base(r.cfg.Filename)
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "-", ext
+}
+
+func (r *RotatingFile) triggerMill() {
+	select {
+	case r.millCh <- struct{}{}:
+	default:
+	}
+}
+
+func (r *RotatingFile) runMill() {
+	defer close(r.millDone)
+	for range r.millCh {
+		if err := r.mill(); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: cannot clean up rotated logs: %v\n", err)
+		}
+	}
+}
+
+type backupFile struct {
+	path       string
+	timestamp  time.Time
+	compressed bool
+	// leftover is the uncompressed original of a compressed backup, left
+	// behind if the process stopped before removing it.
+	leftover string
+}
+
+// mill compresses rotated files and removes the ones exceeding MaxBackups
+// or MaxAge.
+func (r *RotatingFile) mill() error {
+	backups, err := r.backups()
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Time{}
+	if r.cfg.MaxAge > 0 {
+		cutoff = r.now().Add(-r.cfg.MaxAge)
+	}
+
+	var remaining []backupFile
+	for i, b := range backups {
+		if b.leftover != "" {
+			if err := os.Remove(b.leftover); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+
+		expired := !cutoff.IsZero() && b.timestamp.Before(cutoff)
+		overflow := r.cfg.MaxBackups > 0 && i >= r.cfg.MaxBackups
+		if expired || overflow {
+			if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		remaining = append(remaining, b)
+	}
+
+	if !r.cfg.Compress {
+		return nil
+	}
+	for _, b := range remaining {
+		if b.compressed {
+			continue
+		}
+		if err := compressFile(b.path, b.path+compressSuffix); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backups lists rotated files, newest first. Files still being compressed
+// are skipped and a backup present both compressed and uncompressed is
+// listed once.
+func (r *RotatingFile) backups() ([]backupFile, error) {
+	dir := filepath.Dir(r.cfg.Filename)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, ext := r.prefixAndExt()
+	var backups []backupFile
+	byStamp := make(map[string]int)
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), tmpSuffix) {
+			continue
+		}
+
+		name := entry.Name()
+		compressed := strings.HasSuffix(name, compressSuffix)
+		trimmed := strings.TrimSuffix(name, compressSuffix)
+		if !strings.HasPrefix(trimmed, prefix) || !strings.HasSuffix(trimmed, ext) {
+			continue
+		}
+
+		stamp := strings.TrimSuffix(strings.TrimPrefix(trimmed, prefix), ext)
+		t, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+
+		backup := backupFile{
+			path:       filepath.Join(dir, name),
+			timestamp:  t,
+			compressed: compressed,
+		}
+		if i, ok := byStamp[stamp]; ok {
+			if compressed {
+				backup.leftover = backups[i].path
+				backups[i] = backup
+			} else {
+				backups[i].leftover = backup.path
+			}
+			continue
+		}
+		byStamp[stamp] = len(backups)
+		backups = append(backups, backup)
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].timestamp.After(backups[j].timestamp)
+	})
+
+	return backups, nil
+}
+
+// compressFile writes dst under a temporary name first, so an unfinished
+// compression is never taken for a backup.
+func compressFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := dst + tmpSuffix
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Remove(src)
+}
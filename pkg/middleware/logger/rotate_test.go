@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// newTestRotatingFile returns a RotatingFile whose clock is *now, with the
+// active file started at *now.
+func newTestRotatingFile(t *testing.T, cfg RotateConfig, now *time.Time) *RotatingFile {
+	t.Helper()
+	r, err := NewRotatingFile(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+
+	r.mu.Lock()
+	r.now = func() time.Time { return *now }
+	r.mu.Unlock()
+	if err := r.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestBackupsAreNamedByPeriodStart(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 1, 23, 0, 0, 0, time.Local)
+	r := newTestRotatingFile(t, RotateConfig{Filename: filepath.Join(dir, "app.log"), MaxSize: 1 << 20}, &now)
+
+	r.Write([]byte("evening\n"))
+	now = now.Add(2 * time.Hour)
+	r.Write([]byte("next day\n"))
+	r.Close()
+
+	// the backup covers the file started at 23:00, not the rotation at 01:00
+	want := []string{"app-2026-03-01T23-00-00.000.log", "app.log"}
+	if got := listDir(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
+
+func TestSizeRotationsGetUniqueNames(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+	r := newTestRotatingFile(t, RotateConfig{Filename: filepath.Join(dir, "app.log"), MaxSize: 4}, &now)
+
+	// the clock does not move, every rotation still needs its own name
+	for i := 0; i < 3; i++ {
+		r.Write([]byte("line"))
+	}
+	r.Close()
+
+	want := []string{"app-2026-03-01T10-00-00.000.log", "app-2026-03-01T10-00-00.001.log", "app.log"}
+	if got := listDir(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
+
+func TestMillSkipsFilesInProgress(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 5, 10, 0, 0, 0, time.Local)
+	r := newTestRotatingFile(t, RotateConfig{Filename: filepath.Join(dir, "app.log"), MaxBackups: 2}, &now)
+	r.Close()
+
+	for _, name := range []string{
+		"app-2026-03-01T00-00-00.000.log",
+		"app-2026-03-02T00-00-00.000.log",
+		// compressed, but the original was not removed yet
+		"app-2026-03-03T00-00-00.000.log",
+		"app-2026-03-03T00-00-00.000.log.gz",
+		// compression in progress
+		"app-2026-03-04T00-00-00.000.log",
+		"app-2026-03-04T00-00-00.000.log.gz.tmp",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := r.mill(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"app-2026-03-03T00-00-00.000.log.gz",
+		"app-2026-03-04T00-00-00.000.log",
+		"app-2026-03-04T00-00-00.000.log.gz.tmp",
+		"app.log",
+	}
+	if got := listDir(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
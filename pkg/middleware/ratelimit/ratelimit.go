@@ -0,0 +1,172 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Rule allows Limit requests per Period, refilled continuously, with bursts
+// of up to Limit requests.
+type Rule struct {
+	Limit  int
+	Period time.Duration
+}
+
+// ParseRule parses rules written as "<limit>/<period>", e.g. "10/1m".
+func ParseRule(raw string) (Rule, error) {
+	parts := strings.SplitN(strings.TrimSpace(raw), "/", 2)
+	if len(parts) != 2 {
+		return Rule{}, fmt.Errorf("rate limit %q must look like 10/1m", raw)
+	}
+
+	limit, err := strconv.Atoi(parts[0])
+	if err != nil || limit <= 0 {
+		return Rule{}, fmt.Errorf("rate limit %q has an invalid limit", raw)
+	}
+
+	period, err := time.ParseDuration(parts[1])
+	if err != nil || period <= 0 {
+		return Rule{}, fmt.Errorf("rate limit %q has an invalid period", raw)
+	}
+
+	return Rule{Limit: limit, Period: period}, nil
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps one token bucket per key.
+type Store interface {
+	Take(key string, rule Rule, now time.Time) Result
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	period  time.Duration
+}
+
+// MemoryStore is a process local Store. Idle buckets are swept lazily.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+	}
+}
+
+func (s *MemoryStore) Take(key string, rule Rule, now time.Time) Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	capacity := float64(rule.Limit)
+	ratePerSecond := capacity / rule.Period.Seconds()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+		s.buckets[key] = b
+	}
+	b.period = rule.Period
+
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed*ratePerSecond)
+		b.updated = now
+	}
+
+	result := Result{Limit: rule.Limit}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / ratePerSecond)
+	}
+
+	result.Remaining = int(math.Floor(b.tokens))
+	result.Reset = secondsToDuration((capacity - b.tokens) / ratePerSecond)
+
+	return result
+}
+
+// sweep drops buckets that have been idle long enough to be full again,
+// at most once a minute.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if now.Sub(b.updated) > b.period {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
+
+// KeyFunc identifies the client a request is counted against.
+type KeyFunc func(c *gin.Context) string
+
+// ByClientIP keys requests by client IP. Forwarded headers are only honoured
+// from the proxies trusted by the gin engine.
+func ByClientIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByUserID keys authenticated requests by userId and falls back to the
+// client IP otherwise. It must run after the auth middleware.
+func ByUserID(c *gin.Context) string {
+	if userId, ok := c.Get("userId"); ok {
+		return fmt.Sprintf("user:%v", userId)
+	}
+	return ByClientIP(c)
+}
+
+// Middleware limits requests of a route group. name separates the buckets
+// of groups sharing a store.
+func Middleware(store Store, name string, rule Rule, key KeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result := store.Take(name+":"+key(c), rule, time.Now())
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			c.Error(msg.New(msg.CodeTooManyRequests, msg.ErrTooManyRequests))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
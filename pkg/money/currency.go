@@ -0,0 +1,44 @@
+package money
+
+import "strings"
+
+// Currency describes how amounts of an ISO 4217 currency are stored and
+// rounded. Exponent is the number of minor unit digits and CashUnit the
+// smallest amount, in minor units, that can be paid in cash.
+type Currency struct {
+	Code     string
+	Exponent int
+	CashUnit int64
+}
+
+var (
+	// IDR keeps the ISO 4217 exponent of 2 so decimal prices survive, but
+	// rounds charges to whole rupiah and cash to Rp 100, the smallest coin
+	// in circulation.
+	IDR = Currency{Code: "IDR", Exponent: 2, CashUnit: 10000}
+	USD = Currency{Code: "USD", Exponent: 2, CashUnit: 1}
+)
+
+var currencies = map[string]Currency{
+	IDR.Code: IDR,
+	USD.Code: USD,
+}
+
+// DefaultCurrency is used for amounts stored without a currency, which is
+// every amount column in the schema.
+var DefaultCurrency = IDR
+
+// LookupCurrency finds a currency by its ISO 4217 code.
+func LookupCurrency(code string) (Currency, bool) {
+	currency, ok := currencies[strings.ToUpper(code)]
+	return currency, ok
+}
+
+// unit returns one major unit in minor units.
+func (c Currency) unit() int64 {
+	unit := int64(1)
+	for i := 0; i < c.Exponent; i++ {
+		unit *= 10
+	}
+	return unit
+}
@@ -0,0 +1,397 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidAmount    = errors.New("money: invalid amount")
+	ErrTooPrecise       = errors.New("money: amount has more decimals than the currency allows")
+	ErrOverflow         = errors.New("money: amount out of range")
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	ErrNegativeRatio    = errors.New("money: negative allocation ratio")
+)
+
+var decimalPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// RoundingMode decides how amounts between two steps are rounded.
+type RoundingMode int
+
+const (
+	// HalfUp rounds to the nearest step, halves away from zero.
+	HalfUp RoundingMode = iota
+	// HalfEven rounds to the nearest step, halves to the even step.
+	HalfEven
+	// Down rounds towards zero.
+	Down
+	// Up rounds away from zero.
+	Up
+)
+
+// Money is an exact amount in the minor units of its currency. The zero
+// value is zero in DefaultCurrency.
+type Money struct {
+	amount   int64
+	currency string
+}
+
+// New returns amount minor units of currency.
+func New(amount int64, currency Currency) Money {
+	return Money{amount: amount, currency: currency.Code}
+}
+
+// FromMajor returns a whole number of major units, e.g. Rp 15.000.
+func FromMajor(major int64, currency Currency) Money {
+	return New(major*currency.unit(), currency)
+}
+
+// Parse reads a decimal amount such as "15000" or "15000.50". More decimals
+// than the currency has are rejected rather than rounded.
+func Parse(raw string, currency Currency) (Money, error) {
+	return parse(raw, currency, false)
+}
+
+func parse(raw string, currency Currency, round bool) (Money, error) {
+	raw = strings.TrimSpace(raw)
+	if !decimalPattern.MatchString(raw) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, raw)
+	}
+	rat, _ := new(big.Rat).SetString(raw)
+
+	scaled := rat.Mul(rat, new(big.Rat).SetInt64(currency.unit()))
+	if !scaled.IsInt() {
+		if !round {
+			return Money{}, fmt.Errorf("%w: %q", ErrTooPrecise, raw)
+		}
+		scaled.SetInt(roundRat(scaled, HalfUp))
+	}
+
+	amount := scaled.Num()
+	if !amount.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %q", ErrOverflow, raw)
+	}
+	return New(amount.Int64(), currency), nil
+}
+
+func (m Money) Currency() Currency {
+	if m.currency == "" {
+		return DefaultCurrency
+	}
+	currency, ok := LookupCurrency(m.currency)
+	if !ok {
+		return Currency{Code: m.currency, Exponent: DefaultCurrency.Exponent, CashUnit: 1}
+	}
+	return currency
+}
+
+// Amount returns the amount in minor units.
+func (m Money) Amount() int64 {
+	return m.amount
+}
+
+func (m Money) IsZero() bool {
+	return m.amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.amount < 0
+}
+
+// Cmp compares m and other like strings.Compare. Both must share a currency.
+func (m Money) Cmp(other Money) int {
+	m.mustMatch(other)
+	switch {
+	case m.amount < other.amount:
+		return -1
+	case m.amount > other.amount:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Add returns m + other. Like all arithmetic it panics on currency mismatch
+// or overflow, both of which are programming errors.
+func (m Money) Add(other Money) Money {
+	currency := m.mustMatch(other)
+	sum := m.amount + other.amount
+	if (sum > m.amount) != (other.amount > 0) {
+		panic(ErrOverflow)
+	}
+	return New(sum, currency)
+}
+
+func (m Money) Sub(other Money) Money {
+	return m.Add(other.Neg())
+}
+
+func (m Money) Neg() Money {
+	return Money{amount: -m.amount, currency: m.currency}
+}
+
+// Mul returns m times quantity.
+func (m Money) Mul(quantity int64) Money {
+	product := new(big.Int).Mul(big.NewInt(m.amount), big.NewInt(quantity))
+	if !product.IsInt64() {
+		panic(ErrOverflow)
+	}
+	return Money{amount: product.Int64(), currency: m.currency}
+}
+
+// MulRat returns m * num / den rounded to minor units, for percentages
+// and ratios: MulRat(10, 100, HalfUp) is 10% of m.
+func (m Money) MulRat(num, den int64, mode RoundingMode) Money {
+	return m.mulRat(num, den, 1, mode)
+}
+
+// MulRatMajor is MulRat rounded to whole major units, e.g. whole rupiah.
+func (m Money) MulRatMajor(num, den int64, mode RoundingMode) Money {
+	return m.mulRat(num, den, m.Currency().unit(), mode)
+}
+
+// mulRat returns m * num / den rounded to a multiple of step minor units.
+func (m Money) mulRat(num, den, step int64, mode RoundingMode) Money {
+	divisor := new(big.Int).Mul(big.NewInt(den), big.NewInt(step))
+	rat := new(big.Rat).SetFrac(new(big.Int).Mul(big.NewInt(m.amount), big.NewInt(num)), divisor)
+	amount := roundRat(rat, mode)
+	amount.Mul(amount, big.NewInt(step))
+	if !amount.IsInt64() {
+		panic(ErrOverflow)
+	}
+	return Money{amount: amount.Int64(), currency: m.currency}
+}
+
+// Round rounds m to a multiple of step minor units.
+func (m Money) Round(step int64, mode RoundingMode) Money {
+	if step <= 1 {
+		return m
+	}
+	rounded := roundRat(big.NewRat(m.amount, step), mode)
+	amount := rounded.Mul(rounded, big.NewInt(step))
+	if !amount.IsInt64() {
+		panic(ErrOverflow)
+	}
+	return Money{amount: amount.Int64(), currency: m.currency}
+}
+
+// RoundMajor rounds m to whole major units, e.g. whole rupiah.
+func (m Money) RoundMajor(mode RoundingMode) Money {
+	return m.Round(m.Currency().unit(), mode)
+}
+
+// RoundCash rounds m to an amount payable in cash, e.g. a multiple of
+// Rp 100.
+func (m Money) RoundCash(mode RoundingMode) Money {
+	return m.Round(m.Currency().CashUnit, mode)
+}
+
+// Allocate splits m into parts proportional to ratios without losing minor
+// units: the remainder goes to the first parts, one unit each. A negative
+// ratio, or ratios adding up beyond int64, panic like overflowing
+// arithmetic does.
+func (m Money) Allocate(ratios ...int64) []Money {
+	var total int64
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			panic(ErrNegativeRatio)
+		}
+		if total > math.MaxInt64-ratio {
+			panic(ErrOverflow)
+		}
+		total += ratio
+	}
+
+	parts := make([]Money, len(ratios))
+	if total == 0 {
+		for i := range parts {
+			parts[i] = Money{currency: m.currency}
+		}
+		return parts
+	}
+
+	remainder := m.amount
+	for i, ratio := range ratios {
+		parts[i] = m.MulRat(ratio, total, Down)
+		remainder -= parts[i].amount
+	}
+
+	step := int64(1)
+	if remainder < 0 {
+		step = -1
+	}
+	for i := 0; remainder != 0; i = (i + 1) % len(parts) {
+		if ratios[i] == 0 {
+			continue
+		}
+		parts[i].amount += step
+		remainder -= step
+	}
+	return parts
+}
+
+// AllocateMajor is Allocate in whole major units, e.g. whole rupiah. What
+// m has below a major unit goes to the first part.
+func (m Money) AllocateMajor(ratios ...int64) []Money {
+	unit := m.Currency().unit()
+	whole := m.RoundMajor(Down)
+	parts := Money{amount: whole.amount / unit, currency: m.currency}.Allocate(ratios...)
+	for i := range parts {
+		parts[i].amount *= unit
+	}
+	if len(parts) > 0 {
+		parts[0] = parts[0].Add(m.Sub(whole))
+	}
+	return parts
+}
+
+// Change returns what is given back when paid is handed over for total,
+// rounded down to an amount payable in cash.
+func Change(paid, total Money) Money {
+	return paid.Sub(total).RoundCash(Down)
+}
+
+// Sum adds amounts, returning zero in DefaultCurrency for none.
+func Sum(amounts ...Money) Money {
+	var total Money
+	for _, amount := range amounts {
+		total = total.Add(amount)
+	}
+	return total
+}
+
+// String formats m as a plain decimal, e.g. "15000.50".
+func (m Money) String() string {
+	exponent := m.Currency().Exponent
+	if exponent == 0 {
+		return strconv.FormatInt(m.amount, 10)
+	}
+
+	digits := strconv.FormatInt(m.amount, 10)
+	sign := ""
+	if m.amount < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// MarshalJSON writes m as a JSON number without trailing zero decimals,
+// e.g. 15000 or 15000.5, the shape prices had before they were Money, so
+// that clients reading them as numbers or integers keep working.
+func (m Money) MarshalJSON() ([]byte, error) {
+	raw := m.String()
+	if strings.Contains(raw, ".") {
+		raw = strings.TrimRight(strings.TrimRight(raw, "0"), ".")
+	}
+	return []byte(raw), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a numeric string in the currency
+// already set on m, DefaultCurrency otherwise.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	raw := string(data)
+	if raw == "null" {
+		return nil
+	}
+	raw = strings.Trim(raw, `"`)
+
+	parsed, err := Parse(raw, m.Currency())
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value stores m as a decimal string, which postgres reads exactly.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan reads decimal columns. Extra decimals are rounded half up since the
+// column may be more precise than the currency.
+func (m *Money) Scan(src interface{}) error {
+	var raw string
+	switch value := src.(type) {
+	case nil:
+		*m = Money{currency: m.currency}
+		return nil
+	case []byte:
+		raw = string(value)
+	case string:
+		raw = value
+	case int64:
+		raw = strconv.FormatInt(value, 10)
+	case float64:
+		raw = strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return fmt.Errorf("money: cannot scan %T", src)
+	}
+
+	parsed, err := parse(raw, m.Currency(), true)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Float64 is an approximation meant for validation and display only.
+func (m Money) Float64() float64 {
+	value, _ := new(big.Rat).SetFrac64(m.amount, m.Currency().unit()).Float64()
+	return value
+}
+
+// mustMatch returns the currency shared by m and other. A zero amount
+// without currency, like the zero value, matches any currency.
+func (m Money) mustMatch(other Money) Currency {
+	switch {
+	case m.currency == other.currency:
+		return m.Currency()
+	case m.currency == "" && m.amount == 0:
+		return other.Currency()
+	case other.currency == "" && other.amount == 0:
+		return m.Currency()
+	case m.Currency().Code == other.Currency().Code:
+		return m.Currency()
+	default:
+		panic(fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency().Code, other.Currency().Code))
+	}
+}
+
+func roundRat(rat *big.Rat, mode RoundingMode) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(rat.Num(), rat.Denom(), new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+
+	away := big.NewInt(int64(rat.Sign()))
+	switch mode {
+	case Down:
+		return quotient
+	case Up:
+		return quotient.Add(quotient, away)
+	}
+
+	// compare twice the remainder with the denominator to find the half
+	twice := new(big.Int).Abs(remainder)
+	twice.Lsh(twice, 1)
+	switch twice.Cmp(rat.Denom()) {
+	case 1:
+		return quotient.Add(quotient, away)
+	case 0:
+		if mode == HalfUp || quotient.Bit(0) == 1 {
+			return quotient.Add(quotient, away)
+		}
+	}
+	return quotient
+}
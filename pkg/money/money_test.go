@@ -0,0 +1,193 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"testing/quick"
+)
+
+// amounts and ratios are drawn from int32 so sums of a few of them cannot
+// overflow.
+
+func TestAllocateSumsToTotal(t *testing.T) {
+	property := func(amount int32, raw []uint16) bool {
+		ratios := make([]int64, len(raw))
+		for i, ratio := range raw {
+			ratios[i] = int64(ratio % 100)
+		}
+		total := New(int64(amount), IDR)
+
+		parts := total.Allocate(ratios...)
+		if len(parts) != len(ratios) {
+			return false
+		}
+
+		var ratioSum int64
+		for _, ratio := range ratios {
+			ratioSum += ratio
+		}
+		if ratioSum == 0 {
+			return Sum(parts...).IsZero()
+		}
+		for i, part := range parts {
+			if ratios[i] == 0 && !part.IsZero() {
+				return false
+			}
+		}
+		return Sum(parts...) == total
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAllocateIsProportional(t *testing.T) {
+	property := func(amount uint32, a, b uint8) bool {
+		total := New(int64(amount), IDR)
+		parts := total.Allocate(int64(a)+1, int64(b)+1)
+
+		// each part is within one minor unit of its exact share
+		for i, ratio := range []int64{int64(a) + 1, int64(b) + 1} {
+			exact := total.MulRat(ratio, int64(a)+int64(b)+2, Down)
+			if diff := parts[i].Sub(exact).Amount(); diff < 0 || diff > 1 {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAllocateRejectsNegativeRatios(t *testing.T) {
+	tests := []struct {
+		name   string
+		ratios []int64
+		want   error
+	}{
+		{name: "negative ratio", ratios: []int64{1, -1, 1}, want: ErrNegativeRatio},
+		{name: "ratios overflow", ratios: []int64{math.MaxInt64, 1}, want: ErrOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				err, _ := recover().(error)
+				if !errors.Is(err, tt.want) {
+					t.Errorf("Allocate(%v) panicked with %v, want %v", tt.ratios, err, tt.want)
+				}
+			}()
+			New(100, IDR).Allocate(tt.ratios...)
+		})
+	}
+}
+
+func TestAddSubRoundTrip(t *testing.T) {
+	property := func(a, b int32) bool {
+		x, y := New(int64(a), IDR), New(int64(b), IDR)
+		return x.Add(y).Sub(y) == x && x.Sub(y).Add(y) == x && x.Add(y) == y.Add(x)
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestChangeIsPaidMinusTotal(t *testing.T) {
+	property := func(prices []uint16, extra uint32) bool {
+		subtotals := make([]Money, len(prices))
+		var minor int64
+		for i, price := range prices {
+			subtotals[i] = New(int64(price), IDR).Mul(3).RoundMajor(HalfUp)
+			minor += (int64(price)*3 + 50) / 100 * 100
+		}
+		total := Sum(subtotals...)
+		if total.Amount() != minor {
+			return false
+		}
+
+		// change is what was paid over, less what cannot be given in coins
+		paid := total.Add(New(int64(extra), IDR))
+		change := Change(paid, total)
+		kept := paid.Sub(total).Sub(change)
+		return !change.IsNegative() && change.Amount()%IDR.CashUnit == 0 &&
+			!kept.IsNegative() && kept.Amount() < IDR.CashUnit && total.Add(change).Add(kept) == paid
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+
+	if change := Change(FromMajor(50000, IDR), New(3725050, IDR)); change != FromMajor(12700, IDR) {
+		t.Errorf("Change(50000, 37250.50) = %v, want 12700", change)
+	}
+}
+
+func TestAllocateMajorKeepsWholeUnits(t *testing.T) {
+	property := func(major int32, ratios []uint16) bool {
+		if len(ratios) == 0 {
+			return true
+		}
+		ratios64 := make([]int64, len(ratios))
+		for i, ratio := range ratios {
+			ratios64[i] = int64(ratio)
+		}
+		amount := FromMajor(int64(major), IDR)
+		parts := amount.AllocateMajor(ratios64...)
+		for _, part := range parts {
+			if part.RoundMajor(Down) != part {
+				return false
+			}
+		}
+		return Sum(parts...) == amount
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMulRatMajorRoundsOnce(t *testing.T) {
+	// 12.4950 rupiah: rounding to sen first would make it 12.50 and then 13
+	if got := New(2499, IDR).MulRatMajor(1, 2, HalfUp); got != FromMajor(12, IDR) {
+		t.Errorf("MulRatMajor = %v, want 12", got)
+	}
+	if got := FromMajor(15000, IDR).MulRatMajor(15, 100, HalfUp); got != FromMajor(2250, IDR) {
+		t.Errorf("15%% of 15000 = %v, want 2250", got)
+	}
+}
+
+func TestRoundOverflowPanics(t *testing.T) {
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrOverflow) {
+			t.Errorf("Round panicked with %v, want %v", err, ErrOverflow)
+		}
+	}()
+	New(math.MaxInt64-1, IDR).Round(10000, Up)
+}
+
+func TestMarshalJSONKeepsNumberShape(t *testing.T) {
+	tests := []struct {
+		amount int64
+		want   string
+	}{
+		{amount: 1500000, want: `15000`},
+		{amount: 1500050, want: `15000.5`},
+		{amount: 1500055, want: `15000.55`},
+		{amount: 0, want: `0`},
+		{amount: -250, want: `-2.5`},
+	}
+	for _, tt := range tests {
+		got, err := json.Marshal(New(tt.amount, IDR))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("Marshal(%d) = %s, want %s", tt.amount, got, tt.want)
+		}
+
+		var back Money
+		if err := json.Unmarshal(got, &back); err != nil || back.Amount() != tt.amount {
+			t.Errorf("Unmarshal(%s) = %d, %v, want %d", got, back.Amount(), err, tt.amount)
+		}
+	}
+}
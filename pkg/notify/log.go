@@ -0,0 +1,23 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Log writes messages to the application log, for development and as a
+// fallback when no other channel is configured.
+type Log struct{}
+
+func NewLog() Log {
+	return Log{}
+}
+
+func (Log) Notify(ctx context.Context, message Message) error {
+	log.Warn().
+		Str("event", message.Event).
+		Interface("data", message.Data).
+		Msg(message.Subject)
+	return nil
+}
@@ -0,0 +1,120 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"projectsphere/eniqlo-store/config"
+	"strings"
+	"time"
+)
+
+// Message is one notification. Subject and Body are plain text for people,
+// Data is the structured payload sent to machines.
+type Message struct {
+	Event   string      `json:"event"`
+	Subject string      `json:"subject"`
+	Body    string      `json:"body"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// Notifier delivers messages to one channel.
+type Notifier interface {
+	Notify(ctx context.Context, message Message) error
+}
+
+const (
+	ChannelLog     = "log"
+	ChannelWebhook = "webhook"
+	ChannelEmail   = "email"
+)
+
+type Config struct {
+	Channels []string
+
+	WebhookURL     string
+	WebhookSecret  string
+	WebhookTimeout time.Duration
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	EmailFrom    string
+	EmailTo      []string
+}
+
+// ConfigFromEnv reads NOTIFY_CHANNELS, NOTIFY_WEBHOOK_URL,
+// NOTIFY_WEBHOOK_SECRET, NOTIFY_WEBHOOK_TIMEOUT, SMTP_HOST, SMTP_PORT,
+// SMTP_USERNAME, SMTP_PASSWORD, NOTIFY_EMAIL_FROM and NOTIFY_EMAIL_TO.
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Channels:       config.GetStrings("NOTIFY_CHANNELS"),
+		WebhookURL:     config.GetString("NOTIFY_WEBHOOK_URL"),
+		WebhookSecret:  config.GetString("NOTIFY_WEBHOOK_SECRET"),
+		WebhookTimeout: config.GetDuration("NOTIFY_WEBHOOK_TIMEOUT", 10*time.Second),
+		SMTPHost:       config.GetString("SMTP_HOST"),
+		SMTPPort:       config.GetInt("SMTP_PORT", 1025),
+		SMTPUsername:   config.GetString("SMTP_USERNAME"),
+		SMTPPassword:   config.GetString("SMTP_PASSWORD"),
+		EmailFrom:      config.GetString("NOTIFY_EMAIL_FROM"),
+		EmailTo:        config.GetStrings("NOTIFY_EMAIL_TO"),
+	}
+
+	if len(cfg.Channels) == 0 {
+		cfg.Channels = []string{ChannelLog}
+	}
+	if cfg.SMTPHost == "" {
+		cfg.SMTPHost = "localhost"
+	}
+	if cfg.EmailFrom == "" {
+		cfg.EmailFrom = "eniqlo-store@localhost"
+	}
+
+	return cfg
+}
+
+// New builds a notifier delivering to every channel of cfg.Channels.
+func New(cfg Config) (Notifier, error) {
+	notifiers := make(Multi, 0, len(cfg.Channels))
+	for _, channel := range cfg.Channels {
+		switch strings.ToLower(channel) {
+		case ChannelLog:
+			notifiers = append(notifiers, NewLog())
+		case ChannelWebhook:
+			if cfg.WebhookURL == "" {
+				return nil, errors.New("notify: webhook channel needs NOTIFY_WEBHOOK_URL")
+			}
+			notifiers = append(notifiers, NewWebhook(cfg.WebhookURL, cfg.WebhookSecret, cfg.WebhookTimeout))
+		case ChannelEmail:
+			if len(cfg.EmailTo) == 0 {
+				return nil, errors.New("notify: email channel needs NOTIFY_EMAIL_TO")
+			}
+			notifiers = append(notifiers, NewEmail(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.EmailFrom, cfg.EmailTo))
+		default:
+			return nil, fmt.Errorf("notify: unknown channel %q", channel)
+		}
+	}
+
+	if len(notifiers) == 1 {
+		return notifiers[0], nil
+	}
+	return notifiers, nil
+}
+
+// Multi delivers to every notifier, even when an earlier one fails, and
+// returns the failures joined.
+type Multi []Notifier
+
+func (m Multi) Notify(ctx context.Context, message Message) error {
+	var failures []string
+	for _, notifier := range m {
+		if err := notifier.Notify(ctx, message); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package phone
+
+// country describes the national significant number lengths of a calling
+// code. trunkPrefix is the digit dialled before national numbers inside
+// the country, when there is one.
+type country struct {
+	region      string
+	code        string
+	minLength   int
+	maxLength   int
+	trunkPrefix string
+}
+
+// countries lists the calling codes we accept, starting with Indonesia and
+// its neighbours. Regions sharing a code (NANP, Russia and Kazakhstan) are
+// listed once under their main region.
+var countries = []country{
+	{region: "ID", code: "62", minLength: 8, maxLength: 12, trunkPrefix: "0"},
+	{region: "MY", code: "60", minLength: 8, maxLength: 10, trunkPrefix: "0"},
+	{region: "SG", code: "65", minLength: 8, maxLength: 8},
+	{region: "BN", code: "673", minLength: 7, maxLength: 7},
+	{region: "TL", code: "670", minLength: 7, maxLength: 8},
+	{region: "PH", code: "63", minLength: 8, maxLength: 10, trunkPrefix: "0"},
+	{region: "TH", code: "66", minLength: 8, maxLength: 9, trunkPrefix: "0"},
+	{region: "VN", code: "84", minLength: 9, maxLength: 10, trunkPrefix: "0"},
+	{region: "KH", code: "855", minLength: 8, maxLength: 9, trunkPrefix: "0"},
+	{region: "LA", code: "856", minLength: 8, maxLength: 10, trunkPrefix: "0"},
+	{region: "MM", code: "95", minLength: 7, maxLength: 10, trunkPrefix: "0"},
+	{region: "AU", code: "61", minLength: 9, maxLength: 9, trunkPrefix: "0"},
+	{region: "NZ", code: "64", minLength: 8, maxLength: 10, trunkPrefix: "0"},
+	{region: "CN", code: "86", minLength: 10, maxLength: 11, trunkPrefix: "0"},
+	{region: "HK", code: "852", minLength: 8, maxLength: 8},
+	{region: "MO", code: "853", minLength: 8, maxLength: 8},
+	{region: "TW", code: "886", minLength: 8, maxLength: 9, trunkPrefix: "0"},
+	{region: "JP", code: "81", minLength: 9, maxLength: 10, trunkPrefix: "0"},
+	{region: "KR", code: "82", minLength: 8, maxLength: 10, trunkPrefix: "0"},
+	{region: "IN", code: "91", minLength: 10, maxLength: 10, trunkPrefix: "0"},
+	{region: "PK", code: "92", minLength: 9, maxLength: 10, trunkPrefix: "0"},
+	{region: "BD", code: "880", minLength: 10, maxLength: 10, trunkPrefix: "0"},
+	{region: "LK", code: "94", minLength: 9, maxLength: 9, trunkPrefix: "0"},
+	{region: "NP", code: "977", minLength: 8, maxLength: 10, trunkPrefix: "0"},
+	{region: "SA", code: "966", minLength: 8, maxLength: 9, trunkPrefix: "0"},
+	{region: "AE", code: "971", minLength: 8, maxLength: 9, trunkPrefix: "0"},
+	{region: "QA", code: "974", minLength: 7, maxLength: 8},
+	{region: "TR", code: "90", minLength: 10, maxLength: 10, trunkPrefix: "0"},
+	{region: "EG", code: "20", minLength: 9, maxLength: 10, trunkPrefix: "0"},
+	{region: "ZA", code: "27", minLength: 9, maxLength: 9, trunkPrefix: "0"},
+	{region: "NG", code: "234", minLength: 8, maxLength: 10, trunkPrefix: "0"},
+	{region: "US", code: "1", minLength: 10, maxLength: 10},
+	{region: "MX", code: "52", minLength: 10, maxLength: 10},
+	{region: "BR", code: "55", minLength: 10, maxLength: 11, trunkPrefix: "0"},
+	{region: "AR", code: "54", minLength: 10, maxLength: 11, trunkPrefix: "0"},
+	{region: "GB", code: "44", minLength: 9, maxLength: 10, trunkPrefix: "0"},
+	{region: "IE", code: "353", minLength: 7, maxLength: 9, trunkPrefix: "0"},
+	{region: "NL", code: "31", minLength: 9, maxLength: 9, trunkPrefix: "0"},
+	{region: "DE", code: "49", minLength: 6, maxLength: 13, trunkPrefix: "0"},
+	{region: "FR", code: "33", minLength: 9, maxLength: 9, trunkPrefix: "0"},
+	{region: "ES", code: "34", minLength: 9, maxLength: 9},
+	{region: "IT", code: "39", minLength: 6, maxLength: 11},
+	{region: "CH", code: "41", minLength: 9, maxLength: 9, trunkPrefix: "0"},
+	{region: "SE", code: "46", minLength: 7, maxLength: 9, trunkPrefix: "0"},
+	{region: "RU", code: "7", minLength: 10, maxLength: 10, trunkPrefix: "8"},
+}
+
+var (
+	countriesByCode   = make(map[string]country, len(countries))
+	countriesByRegion = make(map[string]country, len(countries))
+)
+
+func init() {
+	for _, c := range countries {
+		countriesByCode[c.code] = c
+		countriesByRegion[c.region] = c
+	}
+}
@@ -0,0 +1,122 @@
+package phone
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrEmpty              = errors.New("phone number is empty")
+	ErrInvalidCharacters  = errors.New("phone number contains invalid characters")
+	ErrMissingCountryCode = errors.New("phone number must start with + and a country code")
+	ErrUnknownCountryCode = errors.New("phone number has an unknown country code")
+	ErrInvalidLength      = errors.New("phone number has an invalid length for its country")
+)
+
+// maxE164Digits is the maximum number of digits of an E.164 number,
+// country code included.
+const maxE164Digits = 15
+
+// DefaultRegion is the region assumed for numbers written without a country
+// code, e.g. "0812 3456 7890" in Indonesia.
+var DefaultRegion = "ID"
+
+// Number is a parsed phone number. NationalNumber excludes the trunk prefix.
+type Number struct {
+	CountryCode    string `json:"countryCode"`
+	NationalNumber string `json:"nationalNumber"`
+	Region         string `json:"region"`
+}
+
+// E164 formats the number as +<country code><national number>.
+func (n Number) E164() string {
+	return "+" + n.CountryCode + n.NationalNumber
+}
+
+// Parse accepts international numbers ("+62 812-3456-7890",
+// "0062 81234567890") and, when region is set, national numbers with
+// their trunk prefix ("081234567890"). Spaces, dashes, dots and
+// parentheses are ignored.
+func Parse(raw string, region string) (Number, error) {
+	cleaned, err := clean(raw)
+	if err != nil {
+		return Number{}, err
+	}
+
+	switch {
+	case strings.HasPrefix(cleaned, "+"):
+		return parseInternational(cleaned[1:])
+	case strings.HasPrefix(cleaned, "00"):
+		return parseInternational(cleaned[2:])
+	}
+
+	country, ok := countriesByRegion[strings.ToUpper(region)]
+	if !ok || country.trunkPrefix == "" || !strings.HasPrefix(cleaned, country.trunkPrefix) {
+		return Number{}, ErrMissingCountryCode
+	}
+
+	return country.number(strings.TrimPrefix(cleaned, country.trunkPrefix))
+}
+
+// Normalize parses raw with DefaultRegion and returns its E.164 form.
+func Normalize(raw string) (string, error) {
+	number, err := Parse(raw, DefaultRegion)
+	if err != nil {
+		return "", err
+	}
+	return number.E164(), nil
+}
+
+func clean(raw string) (string, error) {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(raw) {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", ErrInvalidCharacters
+		}
+	}
+
+	if b.Len() == 0 || b.String() == "+" {
+		return "", ErrEmpty
+	}
+	return b.String(), nil
+}
+
+func parseInternational(digits string) (Number, error) {
+	if len(digits) > maxE164Digits+1 {
+		return Number{}, ErrInvalidLength
+	}
+
+	// country codes are prefix free, so at most one length matches
+	for length := 1; length <= 3 && length < len(digits); length++ {
+		if country, ok := countriesByCode[digits[:length]]; ok {
+			national := digits[length:]
+			// tolerate a trunk 0 kept after the country code, e.g.
+			// "+62 0812..." written by Indonesian users. National numbers
+			// of these countries never start with 0.
+			if country.trunkPrefix == "0" {
+				national = strings.TrimPrefix(national, "0")
+			}
+			return country.number(national)
+		}
+	}
+
+	return Number{}, ErrUnknownCountryCode
+}
+
+func (c country) number(national string) (Number, error) {
+	if len(national) < c.minLength || len(national) > c.maxLength || len(c.code)+len(national) > maxE164Digits {
+		return Number{}, ErrInvalidLength
+	}
+
+	return Number{
+		CountryCode:    c.code,
+		NationalNumber: national,
+		Region:         c.region,
+	}, nil
+}
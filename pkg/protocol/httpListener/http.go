@@ -2,19 +2,46 @@ package httpListener
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"strconv"
+	"time"
 
 	"projectsphere/eniqlo-store/config"
+	categoryHandler "projectsphere/eniqlo-store/internal/category/handler"
+	categoryRepository "projectsphere/eniqlo-store/internal/category/repository"
+	categoryService "projectsphere/eniqlo-store/internal/category/service"
+	checkoutHandler "projectsphere/eniqlo-store/internal/checkout/handler"
+	checkoutRepository "projectsphere/eniqlo-store/internal/checkout/repository"
+	checkoutService "projectsphere/eniqlo-store/internal/checkout/service"
+	inventoryHandler "projectsphere/eniqlo-store/internal/inventory/handler"
+	inventoryRepository "projectsphere/eniqlo-store/internal/inventory/repository"
+	inventoryService "projectsphere/eniqlo-store/internal/inventory/service"
 	productHandler "projectsphere/eniqlo-store/internal/product/handler"
 	productRepository "projectsphere/eniqlo-store/internal/product/repository"
 	productService "projectsphere/eniqlo-store/internal/product/service"
+	promotionHandler "projectsphere/eniqlo-store/internal/promotion/handler"
+	promotionRepository "projectsphere/eniqlo-store/internal/promotion/repository"
+	promotionService "projectsphere/eniqlo-store/internal/promotion/service"
+	purchasingHandler "projectsphere/eniqlo-store/internal/purchasing/handler"
+	purchasingRepository "projectsphere/eniqlo-store/internal/purchasing/repository"
+	purchasingService "projectsphere/eniqlo-store/internal/purchasing/service"
+	reportHandler "projectsphere/eniqlo-store/internal/report/handler"
+	reportRepository "projectsphere/eniqlo-store/internal/report/repository"
+	reportService "projectsphere/eniqlo-store/internal/report/service"
 	userHandler "projectsphere/eniqlo-store/internal/staff/handler"
 	userRepository "projectsphere/eniqlo-store/internal/staff/repository"
 	userService "projectsphere/eniqlo-store/internal/staff/service"
 	"projectsphere/eniqlo-store/pkg/database"
 	"projectsphere/eniqlo-store/pkg/middleware/auth"
+	"projectsphere/eniqlo-store/pkg/middleware/graceful"
+	"projectsphere/eniqlo-store/pkg/notify"
+	"projectsphere/eniqlo-store/pkg/phone"
+	"projectsphere/eniqlo-store/pkg/safehttp"
+	"projectsphere/eniqlo-store/pkg/storage"
+	"projectsphere/eniqlo-store/pkg/validator"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
@@ -22,15 +49,21 @@ import (
 )
 
 type HttpImpl struct {
-	HttpRouter *HttpRouterImpl
-	httpServer *http.Server
+	HttpRouter        *HttpRouterImpl
+	httpServer        *http.Server
+	postgresConnector database.PostgresConnector
+	serveErr          chan error
+	workers           []graceful.Component
 }
 
 func NewHttpProtocol(
 	HttpRouter *HttpRouterImpl,
+	postgresConnector database.PostgresConnector,
 ) *HttpImpl {
 	return &HttpImpl{
-		HttpRouter: HttpRouter,
+		HttpRouter:        HttpRouter,
+		postgresConnector: postgresConnector,
+		serveErr:          make(chan error, 1),
 	}
 }
 
@@ -38,29 +71,61 @@ func (p *HttpImpl) setupRouter() *gin.Engine {
 	return p.HttpRouter.Router()
 }
 
-func (p *HttpImpl) Listen() {
+// Listen binds the configured port and serves requests in the background.
+// Errors after a successful bind are reported on Failed.
+func (p *HttpImpl) Listen(ctx context.Context) error {
 	app := p.setupRouter()
 
 	serverPort := fmt.Sprintf(":%v", config.GetString("APP_PORT"))
+	listener, err := net.Listen("tcp", serverPort)
+	if err != nil {
+		return err
+	}
+
 	p.httpServer = &http.Server{
 		Addr:    serverPort,
 		Handler: app,
 	}
 
 	log.Info().Msgf("Server started on Port %s ", serverPort)
-	err := p.httpServer.ListenAndServe()
-	if err != nil {
-		log.Printf(err.Error())
-	}
+	go func() {
+		err := p.httpServer.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msg("http server stopped")
+			p.serveErr <- err
+		}
+		close(p.serveErr)
+	}()
+
+	return nil
 }
 
+func (p *HttpImpl) Failed() <-chan error {
+	return p.serveErr
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests
+// until ctx expires.
 func (p *HttpImpl) Shutdown(ctx context.Context) error {
+	if p.httpServer == nil {
+		return nil
+	}
 	if err := p.httpServer.Shutdown(ctx); err != nil {
 		return err
 	}
 	return nil
 }
 
+// Workers returns the enabled background workers. They use the database, so
+// they must be stopped before it is closed.
+func (p *HttpImpl) Workers() []graceful.Component {
+	return p.workers
+}
+
+func (p *HttpImpl) CloseDatabase(ctx context.Context) error {
+	return p.postgresConnector.Close()
+}
+
 func Start() *HttpImpl {
 
 	db, err := sqlx.Connect("postgres", fmt.Sprintf("postgresql://%s:%s@%s:%v/%s?%s", config.GetString("DB_USERNAME"), config.GetString("DB_PASSWORD"), config.GetString("DB_HOST"), config.GetString("DB_PORT"), config.GetString("DB_NAME"), config.GetString("DB_PARAMS")))
@@ -78,28 +143,137 @@ func Start() *HttpImpl {
 		panic("cannot parse BCRYPT_SALT")
 	}
 
+	if region := config.GetString("PHONE_DEFAULT_REGION"); region != "" {
+		phone.DefaultRegion = region
+	}
+	if code := config.GetString("SELLING_LOCATION"); code != "" {
+		inventoryRepository.SellingLocation = code
+	}
+
 	userRepo := userRepository.NewUserRepo(postgresConnector)
 
 	jwtAuth := auth.NewJwtAuth(
 		accessTokenExpiredTime,
 		jwtSecretKey,
 		userRepo.IsUserExist,
+		userRepo.GetUserRole,
 	)
 
 	userSvc := userService.NewUserService(userRepo, saltLen, jwtAuth)
 	userHandler := userHandler.NewUserHandler(userSvc)
 
+	categoryRepo := categoryRepository.NewCategoryRepo(postgresConnector)
+	categorySvc := categoryService.NewCategoryService(categoryRepo, config.GetDuration("CATEGORY_CACHE_TTL", time.Minute))
+	categoryHandler := categoryHandler.NewCategoryHandler(categorySvc)
+	validator.SetCategoryChecker(categorySvc.Exists)
+
 	productRepo := productRepository.NewProductRepo(postgresConnector)
-	productSvc := productService.NewProductService(productRepo)
-	productHandler := productHandler.NewProductHandler(productSvc)
+	variantRepo := productRepository.NewVariantRepo(postgresConnector)
+	objectStorage, err := storage.New(storage.ConfigFromEnv())
+	if err != nil {
+		panic(err.Error())
+	}
+	imageMaxSize := int64(config.GetInt("IMAGE_MAX_SIZE_MB", 2)) << 20
+	imageRepo := productRepository.NewImageRepo(postgresConnector)
+	imageSvc := productService.NewImageService(imageRepo, objectStorage, imageMaxSize, config.GetInt("IMAGE_THUMBNAIL_SIZE", 320))
+	imageHandler := productHandler.NewImageHandler(imageSvc, imageMaxSize, config.GetInt("IMAGE_MAX_FILES", 10))
+
+	productSvc := productService.NewProductService(productRepo, variantRepo, categorySvc, imageSvc)
+	requireIfMatch := config.GetBool("PRODUCT_REQUIRE_IF_MATCH", false)
+	variantHandler := productHandler.NewVariantHandler(productService.NewVariantService(variantRepo), requireIfMatch)
+	priceRepo := productRepository.NewPriceRepo(postgresConnector)
+	priceHandler := productHandler.NewPriceHandler(productService.NewPriceService(priceRepo))
+	productHandler := productHandler.NewProductHandler(productSvc, requireIfMatch)
+
+	promotionSvc := promotionService.NewPromotionService(promotionRepository.NewPromotionRepo(postgresConnector), categorySvc)
+	promotionHandler := promotionHandler.NewPromotionHandler(promotionSvc)
+
+	checkoutRepo := checkoutRepository.NewCheckoutRepo(postgresConnector)
+	checkoutSvc := checkoutService.NewCheckoutService(checkoutRepo, promotionSvc)
+	checkoutHandler := checkoutHandler.NewCheckoutHandler(checkoutSvc)
+
+	movementRepo := inventoryRepository.NewMovementRepo(postgresConnector)
+	inventorySvc := inventoryService.NewInventoryService(movementRepo)
+	alertRepo := inventoryRepository.NewAlertRepo(postgresConnector)
+	alertHandler := inventoryHandler.NewAlertHandler(inventoryService.NewAlertService(alertRepo))
+	locationHandler := inventoryHandler.NewLocationHandler(inventoryService.NewLocationService(inventoryRepository.NewLocationRepo(postgresConnector)))
+	transferHandler := inventoryHandler.NewTransferHandler(inventoryService.NewTransferService(inventoryRepository.NewTransferRepo(postgresConnector)))
+	stockTakeHandler := inventoryHandler.NewStockTakeHandler(inventoryService.NewStockTakeService(inventoryRepository.NewStockTakeRepo(postgresConnector), categorySvc))
+	inventoryHandler := inventoryHandler.NewInventoryHandler(inventorySvc)
+
+	supplierHandler := purchasingHandler.NewSupplierHandler(purchasingService.NewSupplierService(purchasingRepository.NewSupplierRepo(postgresConnector)))
+	orderHandler := purchasingHandler.NewOrderHandler(purchasingService.NewOrderService(purchasingRepository.NewOrderRepo(postgresConnector)))
+	reportHandler := reportHandler.NewReportHandler(reportService.NewReportService(reportRepository.NewReportRepo(postgresConnector), categorySvc))
 
 	httpHandlerImpl := NewHttpHandler(
 		productHandler,
+		imageHandler,
+		variantHandler,
+		priceHandler,
 		userHandler,
+		categoryHandler,
+		checkoutHandler,
+		inventoryHandler,
+		alertHandler,
+		locationHandler,
+		transferHandler,
+		stockTakeHandler,
+		supplierHandler,
+		orderHandler,
+		reportHandler,
+		promotionHandler,
 		jwtAuth,
 	)
 	httpRouterImpl := NewHttpRoute(httpHandlerImpl)
-	httpImpl := NewHttpProtocol(httpRouterImpl)
+	httpImpl := NewHttpProtocol(httpRouterImpl, postgresConnector)
+
+	if config.GetBool("IMAGE_VERIFY_ENABLED", false) {
+		verifier := productService.NewImageVerifier(
+			productRepo,
+			objectStorage,
+			safehttp.NewClient(config.GetDuration("IMAGE_VERIFY_TIMEOUT", 10*time.Second), config.GetBool("IMAGE_VERIFY_ALLOW_PRIVATE", false)),
+			productService.VerifierConfig{
+				Interval:  config.GetDuration("IMAGE_VERIFY_INTERVAL", time.Minute),
+				Recheck:   config.GetDuration("IMAGE_VERIFY_RECHECK", 24*time.Hour),
+				BatchSize: config.GetInt("IMAGE_VERIFY_BATCH", 20),
+				MaxSize:   imageMaxSize,
+			},
+		)
+		httpImpl.workers = append(httpImpl.workers, graceful.Component{
+			Name:  "image-verifier",
+			Start: verifier.Start,
+			Stop:  verifier.Stop,
+		})
+	}
+
+	if config.GetBool("PRICE_SCHEDULER_ENABLED", true) {
+		scheduler := productService.NewPriceScheduler(priceRepo, productService.SchedulerConfig{
+			Interval:  config.GetDuration("PRICE_SCHEDULER_INTERVAL", time.Minute),
+			BatchSize: config.GetInt("PRICE_SCHEDULER_BATCH", 50),
+		})
+		httpImpl.workers = append(httpImpl.workers, graceful.Component{
+			Name:  "price-scheduler",
+			Start: scheduler.Start,
+			Stop:  scheduler.Stop,
+		})
+	}
+
+	if config.GetBool("STOCK_ALERT_ENABLED", true) {
+		notifier, err := notify.New(notify.ConfigFromEnv())
+		if err != nil {
+			panic(err.Error())
+		}
+		evaluator := inventoryService.NewAlertEvaluator(alertRepo, notifier, inventoryService.EvaluatorConfig{
+			Interval:      config.GetDuration("STOCK_ALERT_INTERVAL", time.Minute),
+			BatchSize:     config.GetInt("STOCK_ALERT_BATCH", 20),
+			NotifyTimeout: config.GetDuration("STOCK_ALERT_NOTIFY_TIMEOUT", 10*time.Second),
+		})
+		httpImpl.workers = append(httpImpl.workers, graceful.Component{
+			Name:  "stock-alert-evaluator",
+			Start: evaluator.Start,
+			Stop:  evaluator.Stop,
+		})
+	}
 
 	return httpImpl
 }
@@ -1,71 +1,255 @@
 package httpListener
 
 import (
-	"net/http"
 	"projectsphere/eniqlo-store/config"
+	categoryHandler "projectsphere/eniqlo-store/internal/category/handler"
+	checkoutHandler "projectsphere/eniqlo-store/internal/checkout/handler"
+	inventoryHandler "projectsphere/eniqlo-store/internal/inventory/handler"
 	productHandler "projectsphere/eniqlo-store/internal/product/handler"
+	promotionHandler "projectsphere/eniqlo-store/internal/promotion/handler"
+	purchasingHandler "projectsphere/eniqlo-store/internal/purchasing/handler"
+	reportHandler "projectsphere/eniqlo-store/internal/report/handler"
 	userHandler "projectsphere/eniqlo-store/internal/staff/handler"
 	"projectsphere/eniqlo-store/pkg/middleware/auth"
+	"projectsphere/eniqlo-store/pkg/middleware/cors"
+	"projectsphere/eniqlo-store/pkg/middleware/errorhandler"
+	"projectsphere/eniqlo-store/pkg/middleware/locale"
 	"projectsphere/eniqlo-store/pkg/middleware/logger"
+	"projectsphere/eniqlo-store/pkg/middleware/ratelimit"
 	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"projectsphere/eniqlo-store/pkg/storage"
+	"projectsphere/eniqlo-store/pkg/validator"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/rs/zerolog/log"
 )
 
 type HttpHandlerImpl struct {
-	productHandler productHandler.ProductHandler
-	userHandler    userHandler.UserHandler
-	jwtAuth        auth.JWTAuth
+	productHandler   productHandler.ProductHandler
+	imageHandler     productHandler.ImageHandler
+	variantHandler   productHandler.VariantHandler
+	priceHandler     productHandler.PriceHandler
+	userHandler      userHandler.UserHandler
+	categoryHandler  categoryHandler.CategoryHandler
+	checkoutHandler  checkoutHandler.CheckoutHandler
+	inventoryHandler inventoryHandler.InventoryHandler
+	alertHandler     inventoryHandler.AlertHandler
+	locationHandler  inventoryHandler.LocationHandler
+	transferHandler  inventoryHandler.TransferHandler
+	stockTakeHandler inventoryHandler.StockTakeHandler
+	supplierHandler  purchasingHandler.SupplierHandler
+	orderHandler     purchasingHandler.OrderHandler
+	reportHandler    reportHandler.ReportHandler
+	promotionHandler promotionHandler.PromotionHandler
+	jwtAuth          auth.JWTAuth
 }
 
 func NewHttpHandler(
 	productHandler productHandler.ProductHandler,
+	imageHandler productHandler.ImageHandler,
+	variantHandler productHandler.VariantHandler,
+	priceHandler productHandler.PriceHandler,
 	userHandler userHandler.UserHandler,
+	categoryHandler categoryHandler.CategoryHandler,
+	checkoutHandler checkoutHandler.CheckoutHandler,
+	inventoryHandler inventoryHandler.InventoryHandler,
+	alertHandler inventoryHandler.AlertHandler,
+	locationHandler inventoryHandler.LocationHandler,
+	transferHandler inventoryHandler.TransferHandler,
+	stockTakeHandler inventoryHandler.StockTakeHandler,
+	supplierHandler purchasingHandler.SupplierHandler,
+	orderHandler purchasingHandler.OrderHandler,
+	reportHandler reportHandler.ReportHandler,
+	promotionHandler promotionHandler.PromotionHandler,
 	jwtAuth auth.JWTAuth,
 
 ) *HttpHandlerImpl {
 	return &HttpHandlerImpl{
-		productHandler: productHandler,
-		userHandler:    userHandler,
-		jwtAuth:        jwtAuth,
+		productHandler:   productHandler,
+		imageHandler:     imageHandler,
+		variantHandler:   variantHandler,
+		priceHandler:     priceHandler,
+		userHandler:      userHandler,
+		categoryHandler:  categoryHandler,
+		checkoutHandler:  checkoutHandler,
+		inventoryHandler: inventoryHandler,
+		alertHandler:     alertHandler,
+		locationHandler:  locationHandler,
+		transferHandler:  transferHandler,
+		stockTakeHandler: stockTakeHandler,
+		supplierHandler:  supplierHandler,
+		orderHandler:     orderHandler,
+		reportHandler:    reportHandler,
+		promotionHandler: promotionHandler,
+		jwtAuth:          jwtAuth,
 	}
 }
-func CORSMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Disposition, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Header("Access-Control-Allow-Methods", "POST,HEAD,PATCH, OPTIONS, GET, PUT, DELETE")
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-
-		c.Next()
+func (h *HttpHandlerImpl) Router() *gin.Engine {
+	binding.Validator = validator.Shared()
+
+	corsConfig, err := cors.ConfigFromEnv()
+	if err != nil {
+		panic(err.Error())
 	}
-}
 
-func (h *HttpHandlerImpl) Router() *gin.Engine {
 	server := gin.New()
-	server.Use(gin.Recovery(), logger.Logger(), CORSMiddleware())
+	if err := server.SetTrustedProxies(config.GetStrings("TRUSTED_PROXIES")); err != nil {
+		log.Warn().Err(err).Msg("invalid TRUSTED_PROXIES, forwarded headers are ignored")
+		server.SetTrustedProxies(nil)
+	}
+	server.Use(
+		logger.Logger(),
+		errorhandler.Recovery(),
+		locale.Middleware(),
+		errorhandler.Middleware(),
+		cors.Middleware(corsConfig),
+	)
 	server.NoRoute(func(c *gin.Context) {
-		c.JSON(http.StatusNotFound, msg.NotFound(msg.ErrPageNotFound))
+		c.Error(msg.NotFound(msg.ErrPageNotFound))
 	})
 
 	server.Static("/v1/docs", "./dist")
+	if storageConfig := storage.ConfigFromEnv(); storageConfig.Driver == storage.DriverLocal {
+		server.Static(storage.LocalPath, storageConfig.LocalDir)
+	}
 
 	r := server.Group(config.GetString("APPLICATION_GROUP"))
 
+	rateLimitStore := ratelimit.NewMemoryStore()
+
+	// userGroup returns a route group for signed in staff, rate limited per
+	// user by its own RATE_LIMIT_<NAME> rule.
+	userGroup := func(path, name, fallback string, handlers ...gin.HandlerFunc) *gin.RouterGroup {
+		key := "RATE_LIMIT_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+		group := r.Group(path, h.jwtAuth.JwtAuthUserMiddleware())
+		group.Use(handlers...)
+		group.Use(ratelimit.Middleware(rateLimitStore, name, rateLimitRule(key, fallback), ratelimit.ByUserID))
+		return group
+	}
+
 	staff := r.Group("/staff")
+	staff.Use(ratelimit.Middleware(rateLimitStore, "staff", rateLimitRule("RATE_LIMIT_STAFF", "10/1m"), ratelimit.ByClientIP))
 	staff.POST("/register", h.userHandler.Register)
 	staff.POST("/login", h.userHandler.Login)
 
-	product := r.Group("/product")
-	product.Use(h.jwtAuth.JwtAuthUserMiddleware())
+	product := userGroup("/product", "product", "120/1m")
+	product.GET("/", h.productHandler.Search)
 	product.POST("/", h.productHandler.Create)
+	product.GET("/:id", h.productHandler.Get)
 	product.PUT("/:id", h.productHandler.Update)
+	product.PATCH("/:id", h.productHandler.Patch)
 	product.DELETE("/:id", h.productHandler.Delete)
+	product.GET("/:id/images", h.imageHandler.List)
+	product.POST("/:id/images", h.imageHandler.Upload)
+	product.PUT("/:id/images/order", h.imageHandler.Reorder)
+	product.PUT("/:id/images/:imageId/primary", h.imageHandler.SetPrimary)
+	product.DELETE("/:id/images/:imageId", h.imageHandler.Delete)
+	product.PUT("/:id/options", h.variantHandler.SetOptions)
+	product.GET("/:id/variants", h.variantHandler.List)
+	product.PUT("/:id/variants/:variantId", h.variantHandler.Update)
+	product.GET("/:id/prices", h.priceHandler.List)
+	product.POST("/:id/prices", h.priceHandler.Schedule)
+	product.DELETE("/:id/prices/:scheduleId", h.priceHandler.Cancel)
+	product.GET("/:id/stock-history", h.inventoryHandler.StockHistory)
+	product.GET("/:id/stock", h.locationHandler.ProductStock)
+
+	adjustStock := product.Group("", h.jwtAuth.RequireRole(auth.RoleManager))
+	adjustStock.POST("/:id/stock/adjust", h.inventoryHandler.Adjust)
+
+	category := userGroup("/category", "category", "120/1m")
+	category.GET("/", h.categoryHandler.List)
+	category.GET("/:id", h.categoryHandler.Get)
+
+	manageCategory := category.Group("", h.jwtAuth.RequireRole(auth.RoleManager))
+	manageCategory.POST("/", h.categoryHandler.Create)
+	manageCategory.PUT("/:id", h.categoryHandler.Update)
+	manageCategory.DELETE("/:id", h.categoryHandler.Delete)
+
+	checkout := userGroup("/checkout", "checkout", "120/1m")
+	checkout.POST("/", h.checkoutHandler.Checkout)
+
+	alert := userGroup("/stock-alerts", "stock-alerts", "120/1m")
+	alert.GET("/", h.alertHandler.List)
+	alert.POST("/:id/acknowledge", h.alertHandler.Acknowledge)
+	alert.POST("/:id/resolve", h.alertHandler.Resolve)
+
+	location := userGroup("/location", "location", "120/1m")
+	location.GET("/", h.locationHandler.List)
+	location.GET("/:id", h.locationHandler.Get)
+	location.GET("/:id/stock", h.locationHandler.Stock)
+
+	manageLocation := location.Group("", h.jwtAuth.RequireRole(auth.RoleManager))
+	manageLocation.POST("/", h.locationHandler.Create)
+	manageLocation.PUT("/:id", h.locationHandler.Update)
+
+	transfer := userGroup("/transfer", "transfer", "120/1m")
+	transfer.GET("/", h.transferHandler.List)
+	transfer.GET("/:id", h.transferHandler.Get)
+
+	manageTransfer := transfer.Group("", h.jwtAuth.RequireRole(auth.RoleManager))
+	manageTransfer.POST("/", h.transferHandler.Create)
+	manageTransfer.POST("/:id/receive", h.transferHandler.Receive)
+	manageTransfer.POST("/:id/cancel", h.transferHandler.Cancel)
+
+	stockTake := userGroup("/stock-take", "stock-take", "120/1m")
+	stockTake.GET("/", h.stockTakeHandler.List)
+	stockTake.POST("/", h.stockTakeHandler.Create)
+	stockTake.GET("/:id", h.stockTakeHandler.Get)
+	stockTake.POST("/:id/counts", h.stockTakeHandler.Counts)
+	stockTake.GET("/:id/variance", h.stockTakeHandler.Variance)
+
+	approveStockTake := stockTake.Group("", h.jwtAuth.RequireRole(auth.RoleManager))
+	approveStockTake.POST("/:id/approve", h.stockTakeHandler.Approve)
+	approveStockTake.POST("/:id/cancel", h.stockTakeHandler.Cancel)
+
+	supplier := userGroup("/supplier", "supplier", "120/1m")
+	supplier.GET("/", h.supplierHandler.List)
+	supplier.GET("/:id", h.supplierHandler.Get)
+
+	manageSupplier := supplier.Group("", h.jwtAuth.RequireRole(auth.RoleManager))
+	manageSupplier.POST("/", h.supplierHandler.Create)
+	manageSupplier.PUT("/:id", h.supplierHandler.Update)
+
+	purchaseOrder := userGroup("/purchase-order", "purchase-order", "120/1m")
+	purchaseOrder.GET("/", h.orderHandler.List)
+	purchaseOrder.POST("/", h.orderHandler.Create)
+	purchaseOrder.GET("/suggestions", h.orderHandler.Suggestions)
+	purchaseOrder.POST("/suggestions", h.orderHandler.Generate)
+	purchaseOrder.GET("/:id", h.orderHandler.Get)
+	purchaseOrder.PUT("/:id", h.orderHandler.Update)
+
+	manageOrder := purchaseOrder.Group("", h.jwtAuth.RequireRole(auth.RoleManager))
+	manageOrder.POST("/:id/receive", h.orderHandler.Receive)
+	manageOrder.POST("/:id/send", h.orderHandler.Send)
+	manageOrder.POST("/:id/cancel", h.orderHandler.Cancel)
+
+	report := userGroup("/report", "report", "30/1m", h.jwtAuth.RequireRole(auth.RoleManager))
+	report.GET("/margin", h.reportHandler.Margin)
+
+	promotion := userGroup("/promotion", "promotion", "120/1m")
+	promotion.GET("/", h.promotionHandler.List)
+	promotion.GET("/:id", h.promotionHandler.Get)
+
+	managePromotion := promotion.Group("", h.jwtAuth.RequireRole(auth.RoleManager))
+	managePromotion.POST("/", h.promotionHandler.Create)
+	managePromotion.PUT("/:id", h.promotionHandler.Update)
 
 	return server
 }
+
+// rateLimitRule reads a "<limit>/<period>" rule from key, e.g. "10/1m".
+func rateLimitRule(key string, fallback string) ratelimit.Rule {
+	raw := config.GetString(key)
+	if raw == "" {
+		raw = fallback
+	}
+
+	rule, err := ratelimit.ParseRule(raw)
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("invalid rate limit, using default")
+		rule, _ = ratelimit.ParseRule(fallback)
+	}
+	return rule
+}
@@ -0,0 +1,77 @@
+package msg
+
+import (
+	"context"
+	"fmt"
+)
+
+type Language string
+
+const (
+	English    Language = "en"
+	Indonesian Language = "id"
+
+	DefaultLanguage = English
+)
+
+var SupportedLanguages = []Language{English, Indonesian}
+
+// catalogs maps every message constant of this package to its translation.
+// English is the source language and needs no catalog: the constants are the
+// English messages. catalog_test.go fails when a constant is missing.
+var catalogs = map[Language]map[string]string{
+	Indonesian: indonesianCatalog,
+}
+
+type languageKey struct{}
+
+func WithLanguage(ctx context.Context, lang Language) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+func LanguageFromContext(ctx context.Context) Language {
+	if lang, ok := ctx.Value(languageKey{}).(Language); ok {
+		return lang
+	}
+	return DefaultLanguage
+}
+
+// Translate looks key up in the catalog of lang and formats it with args.
+// Unknown keys, such as messages from third party errors, are returned
+// unchanged.
+func Translate(lang Language, key string, args ...interface{}) string {
+	message, ok := catalogs[lang][key]
+	if !ok {
+		message = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// T translates key into the language negotiated for the request.
+func T(ctx context.Context, key string, args ...interface{}) string {
+	return Translate(LanguageFromContext(ctx), key, args...)
+}
+
+// Localize returns a copy of r with its message and field errors translated.
+func (r RespError) Localize(lang Language) RespError {
+	r.Message = Translate(lang, r.Message)
+	if len(r.Details) == 0 {
+		return r
+	}
+
+	details := make([]FieldError, len(r.Details))
+	for i, detail := range r.Details {
+		if detail.template != "" {
+			detail.Message = Translate(lang, detail.template, detail.params...)
+		} else {
+			detail.Message = Translate(lang, detail.Message)
+		}
+		details[i] = detail
+	}
+	r.Details = details
+
+	return r
+}
@@ -0,0 +1,233 @@
+package msg
+
+var indonesianCatalog = map[string]string{
+	// error
+	ErrInvalidRequest:          "permintaan tidak valid",
+	ErrInvalidJsonName:         "nama json tidak valid",
+	ErrUserNotFound:            "Pengguna tidak ditemukan",
+	ErrUserRoleNotExist:        "peran pengguna tidak ada",
+	ErrPageNotFound:            "Halaman Tidak Ditemukan",
+	ErrTokenNotExist:           "token tidak ada",
+	ErrTokenNotFound:           "token tidak ditemukan",
+	ErrInvalidToken:            "token tidak valid",
+	ErrUnauthorizedAction:      "tindakan tidak diizinkan",
+	ErrEmailAlreadyExist:       "email sudah terdaftar",
+	ErrNameAlreadyExist:        "Nama Sudah Terdaftar",
+	ErrWrongPassword:           "Kata sandi salah",
+	ErrConvertIdToInt:          "id harus berupa angka",
+	ErrPasswordContainUsername: "kata sandi tidak boleh mengandung nama pengguna",
+	ErrInvalidEmail:            "email tidak valid",
+	ErrInvalidTokenType:        "jenis token tidak valid",
+	ErrTokenAlreadyExpired:     "token sudah kedaluwarsa",
+	ErrRequiredOldPassword:     "kata sandi lama wajib diisi",
+	ErrInvalidPassword:         "Kata sandi harus berisi karakter dan panjangnya antara 5 sampai 15 karakter",
+	ErrOTPCodeNotFound:         "kode otp tidak ditemukan",
+
+	ErrParseToFormatDate:    "masukkan tanggal dengan format : yyyy-mm-dd",
+	ErrInvalidSigningMethod: "Metode penandatanganan tidak valid",
+	// filter
+	ErrLimitNotNumber:              "limit harus diisi dengan angka",
+	ErrLimitMustBetween0Until100:   "limit harus diisi antara 1-100",
+	ErrInvalidSortRequest:          "permintaan urutan tidak ditemukan",
+	ErrInvalidLevelRequest:         "permintaan level tidak valid",
+	ErrInvalidFilterRequest:        "permintaan filter tidak valid",
+	ErrPageNotNumber:               "halaman harus diisi dengan angka",
+	ErrStarNotNumber:               "bintang harus diisi dengan angka",
+	ErrIdSellerNotNumber:           "id penjual harus diisi dengan angka",
+	ErrIdCityNotNumber:             "id kota harus diisi dengan angka",
+	ErrIdProvinceNotNumber:         "id provinsi harus diisi dengan angka",
+	ErrMinPriceNotNumber:           "harga minimum harus diisi dengan angka",
+	ErrMaxPriceNotNumber:           "harga maksimum harus diisi dengan angka",
+	ErrIdCategoryNotNumber:         "id kategori harus diisi dengan angka",
+	ErrMinRatingNotNumber:          "rating harus diisi dengan angka",
+	ErrRatingMustBetween1Until5:    "rating harus diisi antara 1 sampai 5",
+	ErrMinPriceMinimal0:            "harga minimum tidak boleh negatif",
+	ErrMaxPriceMinimal1:            "harga maksimum harus lebih dari 0",
+	ErrMinRatingMustBetween0Until5: "rating minimum harus antara 0 - 5",
+	ErrStatusNotFound:              "status tidak ditemukan",
+
+	// user
+	ErrUserAlreadyExist:       "pengguna sudah ada",
+	ErrUserNotExist:           "pengguna tidak ada",
+	ErrUsernameAlreadyExist:   "nama pengguna sudah ada",
+	ErrUserNotASeller:         "pengguna bukan penjual",
+	ErrRequiredFullName:       "nama lengkap wajib diisi",
+	ErrInvalidFullName:        "nama hanya boleh berisi huruf dan spasi, dengan panjang antara 5 sampai 15 karakter",
+	ErrFullNameAlreadyUsed:    "nama lengkap sudah digunakan",
+	ErrRequiredPhoneNumber:    "nomor telepon wajib diisi",
+	ErrInvalidPhoneNumber:     "nomor telepon tidak valid",
+	ErrPhoneNumberAlreadyUsed: "nomor telepon sudah digunakan",
+	ErrInvalidBirthDate:       "tanggal lahir tidak valid",
+	ErrRequiredBirthDate:      "tanggal lahir wajib diisi",
+	ErrRequiredGender:         "jenis kelamin wajib diisi",
+	ErrInvalidGenderFormat:    "format jenis kelamin tidak valid",
+	ErrInvalidFormatFile:      "berkas yang diunggah harus berupa gambar",
+	ErrUnsupportedImgFormat:   "format gambar tidak didukung",
+	ErrProfileRecordNotFound:  "profil tidak ditemukan",
+	ErrRequiredUsername:       "nama pengguna tidak boleh kosong",
+	ErrWeakPassword:           "kata sandi harus berisi huruf, angka, simbol dan satu huruf kapital dengan panjang minimal 6",
+	ErrOldPassword:            "kata sandi lama tidak boleh digunakan",
+
+	ErrPleaseRelogin:   "token anda sudah kedaluwarsa, silakan masuk kembali",
+	ErrTooManyRequests: "terlalu banyak permintaan, silakan coba lagi nanti",
+
+	ErrValidationFailed:  "permintaan tidak lolos validasi",
+	ErrInternalServer:    "terjadi kesalahan pada server",
+	ErrEmptyRequestBody:  "Isi permintaan kosong",
+	ErrNoAuthHeader:      "Header otorisasi tidak ditemukan",
+	ErrPayloadNullValues: "Data JSON berisi nilai kosong",
+	ErrProductIDMissing:  "ID produk tidak ada",
+	ErrProductNotFound:   "produk tidak ditemukan",
+	ErrNoRowsReturned:    "tidak ada data yang dikembalikan",
+	ErrCantGetUserIdCtx:  "Tidak dapat mengambil userId dari konteks",
+	ErrCantParseUserId:   "Tidak dapat membaca userId dari konteks",
+
+	// product
+	ErrProductVersionMismatch: "produk telah diubah oleh orang lain, muat ulang lalu coba lagi",
+	ErrIfMatchRequired:        "Header If-Match dengan ETag produk wajib diisi",
+	ErrInvalidIfMatch:         "Header If-Match harus berisi ETag produk",
+	ErrImageNotFound:          "gambar tidak ditemukan",
+	ErrImageTooLarge:          "ukuran gambar melebihi batas",
+	ErrNoImageUploaded:        "tidak ada gambar yang diunggah, kirim berkas pada field images",
+	ErrTooManyImages:          "terlalu banyak gambar dalam satu permintaan",
+	ErrImageOrderMismatch:     "imageIds harus memuat setiap gambar produk tepat satu kali",
+	ErrUnsupportedPatchType:   "Content-Type harus application/merge-patch+json atau application/json-patch+json",
+	ErrInvalidPatch:           "dokumen patch tidak valid",
+	ErrPatchTestFailed:        "operasi test pada patch gagal",
+	ErrVariantNotFound:        "varian tidak ditemukan",
+	ErrVariantHasStock:        "varian yang masih memiliki stok tidak dapat dihapus, ubah stoknya menjadi 0 terlebih dahulu",
+	ErrTooManyVariants:        "opsi menghasilkan terlalu banyak varian",
+	ErrBarcodeExists:          "barcode sudah ada",
+	ErrPriceScheduleNotFound:  "jadwal harga tidak ditemukan",
+	ErrPriceScheduleClosed:    "jadwal harga sudah diterapkan atau dibatalkan",
+	ErrInsufficientStock:      "stok tidak mencukupi",
+	ErrStockNegative:          "penyesuaian akan membuat stok menjadi negatif",
+	ErrStockAboveMax:          "stok tidak boleh melebihi 100000",
+	ErrAlertNotFound:          "peringatan stok tidak ditemukan",
+	ErrAlertResolved:          "peringatan stok sudah diselesaikan",
+	ErrLocationNotFound:       "lokasi tidak ditemukan",
+	ErrLocationCodeExists:     "kode lokasi sudah ada",
+	ErrLocationInactive:       "lokasi tidak aktif",
+	ErrTransferNotFound:       "transfer tidak ditemukan",
+	ErrTransferNotInTransit:   "transfer tidak lagi dalam perjalanan",
+	ErrStockTakeNotFound:      "stock opname tidak ditemukan",
+	ErrStockTakeClosed:        "stock opname sudah tidak menerima hitungan",
+	ErrSupplierNotFound:       "pemasok tidak ditemukan",
+	ErrSupplierNameExists:     "nama pemasok sudah ada",
+	ErrSupplierInactive:       "pemasok tidak aktif",
+	ErrPurchaseOrderNotFound:  "pesanan pembelian tidak ditemukan",
+	ErrPurchaseOrderNotDraft:  "pesanan pembelian hanya dapat diubah atau dikirim selama masih draf",
+	ErrPurchaseOrderNotOpen:   "pesanan pembelian tidak terbuka untuk penerimaan barang",
+	ErrPurchaseOrderClosed:    "pesanan pembelian sudah diterima atau dibatalkan",
+	ErrNothingToReorder:       "tidak ada produk dari pemasok ini yang berada di atau di bawah titik pemesanan ulang",
+	ErrPromotionNotFound:      "promosi tidak ditemukan",
+	ErrPromotionCodeExists:    "kode promosi sudah ada",
+
+	// category
+	ErrCategoryNotFound:       "kategori tidak ditemukan",
+	ErrCategoryParentNotFound: "kategori induk tidak ditemukan",
+	ErrCategoryCycle:          "kategori tidak dapat dipindahkan ke bawah dirinya sendiri atau subkategorinya",
+	ErrCategoryHasChildren:    "kategori masih memiliki subkategori",
+	ErrCategoryInUse:          "kategori masih digunakan oleh produk",
+	ErrCategorySlugExists:     "slug kategori sudah ada",
+
+	// response
+	GetAllResponse:                      "berhasil mengambil semua data",
+	GetByIDResponse:                     "Berhasil Mengambil Data Berdasarkan ID",
+	GetByCodeResponse:                   "Berhasil Mengambil Data Berdasarkan Kode",
+	GetDataResponse:                     "Berhasil Mengambil Data",
+	CreateResponse:                      "Data Berhasil Dibuat",
+	UpdateResponse:                      "Data Berhasil Diperbarui",
+	DeleteResponse:                      "Data Berhasil Dihapus",
+	ConfirmResponse:                     "Data Berhasil Dikonfirmasi",
+	LoginResponse:                       "Berhasil Masuk",
+	SignInWithGoogle:                    "berhasil masuk dengan google",
+	RegisterResponse:                    "Berhasil Mendaftar",
+	UpdateUserProfileResponse:           "Profil Pengguna Berhasil Diperbarui",
+	AccessTokenSuccessfully:             "Token Akses Berhasil Dibuat",
+	ResetPasswordSuccessfully:           "berhasil mengatur ulang kata sandi",
+	ForgotPasswordRequestedSuccessfully: "permintaan lupa kata sandi berhasil dikirim",
+	RequestChangePasswordSuccessfully:   "permintaan ubah kata sandi berhasil",
+	PasswordSuccessfullyChanged:         "kata sandi berhasil diubah",
+	WithdrawalSuccess:                   "penarikan berhasil",
+	// seller
+	GetByIdUserResponse:             "Berhasil Mengambil Data Berdasarkan ID Pengguna",
+	SetAsSellerResponse:             "Berhasil Dijadikan Penjual",
+	VerifySeaLabsPay:                "silakan verifikasi sealabs pay melalui url ini",
+	SealabsPaySuccessfullyVerified:  "sealabs pay berhasil diverifikasi",
+	SealabsPaySuccessfullyConfirmed: "sealabs pay berhasil dikonfirmasi",
+	SealabsPaySuccessfullyCancelled: "sealabs pay berhasil dibatalkan",
+	SealabsPaySuccessfullyTopUp:     "berhasil isi saldo dengan sealabs pay",
+	RefundApproveResponse:           "Pengembalian Dana Berhasil Disetujui",
+	RefundDeclinedResponse:          "Pengembalian Dana Berhasil Ditolak",
+	RefundSuccessfully:              "Transaksi Berhasil Dikembalikan",
+	UserWalletSuccessfullyVerified:  "dompet pengguna berhasil diverifikasi",
+	// staff
+	UserRegisteredResponse: "Pengguna berhasil terdaftar",
+	UserLoggedResponse:     "Pengguna berhasil masuk",
+	// product
+	SuccessResponse:                "berhasil",
+	ProductUpdatedResponse:         "Produk berhasil diperbarui",
+	ProductDeletedResponse:         "Produk berhasil dihapus",
+	ImageUploadedResponse:          "Gambar berhasil diunggah",
+	PriceScheduledResponse:         "Perubahan harga dijadwalkan",
+	PriceScheduleCancelledResponse: "Perubahan harga dibatalkan",
+	// checkout
+	CheckoutResponse: "Checkout berhasil",
+	// inventory
+	StockAdjustedResponse:          "Stok berhasil disesuaikan",
+	AlertAcknowledgedResponse:      "Peringatan stok berhasil dikonfirmasi",
+	AlertResolvedResponse:          "Peringatan stok berhasil diselesaikan",
+	TransferCreatedResponse:        "Transfer stok berhasil dibuat",
+	TransferReceivedResponse:       "Transfer stok berhasil diterima",
+	TransferCancelledResponse:      "Transfer stok berhasil dibatalkan",
+	StockTakeCreatedResponse:       "Stock opname dimulai",
+	CountsRecordedResponse:         "Hitungan berhasil dicatat",
+	StockTakeApprovedResponse:      "Stock opname disetujui dan stok disesuaikan",
+	StockTakeCancelledResponse:     "Stock opname dibatalkan",
+	PurchaseOrderCreatedResponse:   "Pesanan pembelian dibuat",
+	PurchaseOrderSentResponse:      "Pesanan pembelian dikirim",
+	PurchaseOrderCancelledResponse: "Pesanan pembelian dibatalkan",
+	GoodsReceivedResponse:          "Barang diterima dan stok diperbarui",
+	PromotionCreatedResponse:       "Promosi dibuat",
+	PromotionUpdatedResponse:       "Promosi diperbarui",
+
+	// validation
+	ValRequired:           "%s tidak boleh kosong",
+	ValMinLength:          "%s minimal terdiri dari %s karakter",
+	ValMaxLength:          "%s maksimal terdiri dari %s karakter",
+	ValMin:                "%s minimal %s",
+	ValMax:                "%s maksimal %s",
+	ValOneOf:              "%s harus salah satu dari: %s",
+	ValInvalidEmail:       "%s harus berupa alamat email yang valid",
+	ValInvalidURL:         "%s harus berupa URL http atau https yang valid",
+	ValInvalidPhone:       "%s harus berupa nomor telepon yang valid dengan kode negara",
+	ValInvalidCategory:    "%s bukan kategori yang valid",
+	ValInvalidFullName:    "%s hanya boleh berisi huruf dan spasi dengan panjang antara 5 sampai 15 karakter",
+	ValInvalidPassword:    "%s harus terdiri dari 5 sampai 15 karakter",
+	ValInvalidSlug:        "%s hanya boleh berisi huruf kecil, angka dan tanda hubung tunggal",
+	ValDuplicate:          "%s harus unik",
+	ValOptionsForString:   "%s hanya dapat diisi untuk atribut bertipe string",
+	ValAttributeType:      "%s harus bertipe %s",
+	ValUnknownAttribute:   "%s bukan atribut dari kategori ini",
+	ValReadOnly:           "%s tidak dapat diubah",
+	ValVariantRequired:    "%s wajib diisi untuk produk yang memiliki opsi",
+	ValUnavailable:        "%s tidak tersedia",
+	ValInsufficientStock:  "%s melebihi stok yang tersedia sebanyak %s",
+	ValPaidNotEnough:      "%s kurang dari total sebesar %s",
+	ValDeltaOrCount:       "%s atau count wajib diisi, tetapi tidak keduanya",
+	ValDecreaseOnly:       "%s hanya boleh mengurangi stok",
+	ValIncreaseOnly:       "%s hanya boleh menambah stok",
+	ValNotInStockTake:     "%s tidak termasuk dalam stock opname ini",
+	ValAmbiguousSKU:       "%s cocok dengan beberapa varian, gunakan variantId",
+	ValSKUOrVariant:       "%s atau variantId wajib diisi",
+	ValNotInPurchaseOrder: "%s bukan baris dari pesanan pembelian ini",
+	ValOverReceived:       "%s melebihi %s yang masih harus diterima",
+	ValNotFuture:          "%s harus di masa mendatang",
+	ValRequiredFor:        "%s wajib diisi untuk promosi %s",
+	ValNotFor:             "%s tidak berlaku untuk promosi %s",
+	ValAfter:              "%s harus setelah %s",
+	ValInvalidPromoCode:   "%s bukan kode promosi yang berlaku",
+	ValInvalid:            "%s tidak valid",
+	ValStockAdjustOnly:    "%s hanya dapat diubah melalui %s",
+}
@@ -0,0 +1,99 @@
+package msg
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// declaredMessages parses the package source and returns every untyped string
+// constant by name. These are the message keys, so a new constant is checked
+// as soon as it is declared.
+func declaredMessages(t *testing.T) map[string]string {
+	t.Helper()
+
+	notTest := func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}
+	pkgs, err := parser.ParseDir(token.NewFileSet(), ".", notTest, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := make(map[string]string)
+	for _, file := range pkgs["msg"].Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				value := spec.(*ast.ValueSpec)
+				if value.Type != nil {
+					continue
+				}
+				for i, name := range value.Names {
+					lit, ok := value.Values[i].(*ast.BasicLit)
+					if !ok || lit.Kind != token.STRING {
+						continue
+					}
+					message, err := strconv.Unquote(lit.Value)
+					if err != nil {
+						t.Fatal(err)
+					}
+					messages[name.Name] = message
+				}
+			}
+		}
+	}
+	if len(messages) == 0 {
+		t.Fatal("no message constants found")
+	}
+	return messages
+}
+
+func TestCatalogsTranslateEveryMessage(t *testing.T) {
+	messages := declaredMessages(t)
+	for lang, catalog := range catalogs {
+		for name, message := range messages {
+			if _, ok := catalog[message]; !ok {
+				t.Errorf("%s: missing translation of %s", lang, name)
+			}
+		}
+		if len(catalog) > len(messages) {
+			t.Errorf("%s: %d translations for %d messages", lang, len(catalog), len(messages))
+		}
+	}
+}
+
+func TestTranslationsKeepPlaceholders(t *testing.T) {
+	for lang, catalog := range catalogs {
+		for message, translation := range catalog {
+			if strings.Count(message, "%") != strings.Count(translation, "%") {
+				t.Errorf("%s: %q and %q have different placeholders", lang, message, translation)
+			}
+		}
+	}
+}
+
+func TestErrorsHaveUniqueCodes(t *testing.T) {
+	seen := make(map[ErrorCode]string)
+	for name, message := range declaredMessages(t) {
+		if !strings.HasPrefix(name, "Err") {
+			continue
+		}
+		code := CodeOf(message)
+		if code == "" {
+			t.Errorf("%s has no error code", name)
+			continue
+		}
+		if other, ok := seen[code]; ok {
+			t.Errorf("%s and %s share the error code %s", name, other, code)
+		}
+		seen[code] = name
+	}
+}
@@ -0,0 +1,217 @@
+package msg
+
+import "net/http"
+
+// ErrorCode is a stable, machine readable error identifier. Clients should
+// branch on it rather than on Message, which is meant for humans.
+//
+// The Code* constants are error classes: they pick the HTTP status and are the
+// code of messages that have none of their own. Each Err* message has its own
+// code in errorCodes.
+type ErrorCode string
+
+const (
+	CodeBadRequest           ErrorCode = "BAD_REQUEST"
+	CodeValidationFailed     ErrorCode = "VALIDATION_FAILED"
+	CodeUnauthorized         ErrorCode = "UNAUTHORIZED"
+	CodeForbidden            ErrorCode = "FORBIDDEN"
+	CodeNotFound             ErrorCode = "NOT_FOUND"
+	CodeConflict             ErrorCode = "CONFLICT"
+	CodePreconditionFailed   ErrorCode = "PRECONDITION_FAILED"
+	CodePreconditionRequired ErrorCode = "PRECONDITION_REQUIRED"
+	CodePayloadTooLarge      ErrorCode = "PAYLOAD_TOO_LARGE"
+	CodeUnsupportedMediaType ErrorCode = "UNSUPPORTED_MEDIA_TYPE"
+	CodeTooManyRequests      ErrorCode = "TOO_MANY_REQUESTS"
+	CodeInternal             ErrorCode = "INTERNAL_ERROR"
+)
+
+var codeStatus = map[ErrorCode]int{
+	CodeBadRequest:           http.StatusBadRequest,
+	CodeValidationFailed:     http.StatusBadRequest,
+	CodeUnauthorized:         http.StatusUnauthorized,
+	CodeForbidden:            http.StatusForbidden,
+	CodeNotFound:             http.StatusNotFound,
+	CodeConflict:             http.StatusConflict,
+	CodePreconditionFailed:   http.StatusPreconditionFailed,
+	CodePreconditionRequired: http.StatusPreconditionRequired,
+	CodePayloadTooLarge:      http.StatusRequestEntityTooLarge,
+	CodeUnsupportedMediaType: http.StatusUnsupportedMediaType,
+	CodeTooManyRequests:      http.StatusTooManyRequests,
+	CodeInternal:             http.StatusInternalServerError,
+}
+
+// HTTPStatus maps the code to its HTTP status, defaulting to 500.
+func (c ErrorCode) HTTPStatus() int {
+	if status, ok := codeStatus[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// CodeForStatus is the inverse of HTTPStatus for errors built with only a
+// status code.
+func CodeForStatus(status int) ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusPreconditionFailed:
+		return CodePreconditionFailed
+	case http.StatusPreconditionRequired:
+		return CodePreconditionRequired
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusUnsupportedMediaType:
+		return CodeUnsupportedMediaType
+	case http.StatusTooManyRequests:
+		return CodeTooManyRequests
+	default:
+		return CodeInternal
+	}
+}
+
+// errorCodes gives every Err* message its own stable code, so clients can
+// tell apart errors that share an HTTP status. The status still comes from
+// the class code the error is built with.
+var errorCodes = map[string]ErrorCode{
+	ErrInvalidRequest:              "INVALID_REQUEST",
+	ErrInvalidJsonName:             "INVALID_JSON_NAME",
+	ErrUserNotFound:                "USER_NOT_FOUND",
+	ErrUserRoleNotExist:            "USER_ROLE_NOT_EXIST",
+	ErrPageNotFound:                "PAGE_NOT_FOUND",
+	ErrTokenNotExist:               "TOKEN_NOT_EXIST",
+	ErrTokenNotFound:               "TOKEN_NOT_FOUND",
+	ErrInvalidToken:                "INVALID_TOKEN",
+	ErrUnauthorizedAction:          "UNAUTHORIZED_ACTION",
+	ErrEmailAlreadyExist:           "EMAIL_ALREADY_EXIST",
+	ErrNameAlreadyExist:            "NAME_ALREADY_EXIST",
+	ErrWrongPassword:               "WRONG_PASSWORD",
+	ErrConvertIdToInt:              "CONVERT_ID_TO_INT",
+	ErrPasswordContainUsername:     "PASSWORD_CONTAIN_USERNAME",
+	ErrInvalidEmail:                "INVALID_EMAIL",
+	ErrInvalidTokenType:            "INVALID_TOKEN_TYPE",
+	ErrTokenAlreadyExpired:         "TOKEN_ALREADY_EXPIRED",
+	ErrRequiredOldPassword:         "REQUIRED_OLD_PASSWORD",
+	ErrInvalidPassword:             "INVALID_PASSWORD",
+	ErrOTPCodeNotFound:             "OTP_CODE_NOT_FOUND",
+	ErrParseToFormatDate:           "PARSE_TO_FORMAT_DATE",
+	ErrInvalidSigningMethod:        "INVALID_SIGNING_METHOD",
+	ErrLimitNotNumber:              "LIMIT_NOT_NUMBER",
+	ErrLimitMustBetween0Until100:   "LIMIT_MUST_BETWEEN_0_UNTIL_100",
+	ErrInvalidSortRequest:          "INVALID_SORT_REQUEST",
+	ErrInvalidLevelRequest:         "INVALID_LEVEL_REQUEST",
+	ErrInvalidFilterRequest:        "INVALID_FILTER_REQUEST",
+	ErrPageNotNumber:               "PAGE_NOT_NUMBER",
+	ErrStarNotNumber:               "STAR_NOT_NUMBER",
+	ErrIdSellerNotNumber:           "ID_SELLER_NOT_NUMBER",
+	ErrIdCityNotNumber:             "ID_CITY_NOT_NUMBER",
+	ErrIdProvinceNotNumber:         "ID_PROVINCE_NOT_NUMBER",
+	ErrMinPriceNotNumber:           "MIN_PRICE_NOT_NUMBER",
+	ErrMaxPriceNotNumber:           "MAX_PRICE_NOT_NUMBER",
+	ErrIdCategoryNotNumber:         "ID_CATEGORY_NOT_NUMBER",
+	ErrMinRatingNotNumber:          "MIN_RATING_NOT_NUMBER",
+	ErrRatingMustBetween1Until5:    "RATING_MUST_BETWEEN_1_UNTIL_5",
+	ErrMinPriceMinimal0:            "MIN_PRICE_MINIMAL_0",
+	ErrMaxPriceMinimal1:            "MAX_PRICE_MINIMAL_1",
+	ErrMinRatingMustBetween0Until5: "MIN_RATING_MUST_BETWEEN_0_UNTIL_5",
+	ErrStatusNotFound:              "STATUS_NOT_FOUND",
+	ErrUserAlreadyExist:            "USER_ALREADY_EXIST",
+	ErrUserNotExist:                "USER_NOT_EXIST",
+	ErrUsernameAlreadyExist:        "USERNAME_ALREADY_EXIST",
+	ErrUserNotASeller:              "USER_NOT_A_SELLER",
+	ErrRequiredFullName:            "REQUIRED_FULL_NAME",
+	ErrInvalidFullName:             "INVALID_FULL_NAME",
+	ErrFullNameAlreadyUsed:         "FULL_NAME_ALREADY_USED",
+	ErrRequiredPhoneNumber:         "REQUIRED_PHONE_NUMBER",
+	ErrInvalidPhoneNumber:          "INVALID_PHONE_NUMBER",
+	ErrPhoneNumberAlreadyUsed:      "PHONE_NUMBER_ALREADY_USED",
+	ErrInvalidBirthDate:            "INVALID_BIRTH_DATE",
+	ErrRequiredBirthDate:           "REQUIRED_BIRTH_DATE",
+	ErrRequiredGender:              "REQUIRED_GENDER",
+	ErrInvalidGenderFormat:         "INVALID_GENDER_FORMAT",
+	ErrInvalidFormatFile:           "INVALID_FORMAT_FILE",
+	ErrUnsupportedImgFormat:        "UNSUPPORTED_IMG_FORMAT",
+	ErrProfileRecordNotFound:       "PROFILE_RECORD_NOT_FOUND",
+	ErrRequiredUsername:            "REQUIRED_USERNAME",
+	ErrWeakPassword:                "WEAK_PASSWORD",
+	ErrOldPassword:                 "OLD_PASSWORD",
+	ErrPleaseRelogin:               "PLEASE_RELOGIN",
+	ErrTooManyRequests:             CodeTooManyRequests,
+	ErrValidationFailed:            CodeValidationFailed,
+	ErrInternalServer:              CodeInternal,
+	ErrEmptyRequestBody:            "EMPTY_REQUEST_BODY",
+	ErrNoAuthHeader:                "NO_AUTH_HEADER",
+	ErrPayloadNullValues:           "PAYLOAD_NULL_VALUES",
+	ErrProductIDMissing:            "PRODUCT_ID_MISSING",
+	ErrProductNotFound:             "PRODUCT_NOT_FOUND",
+	ErrNoRowsReturned:              "NO_ROWS_RETURNED",
+	ErrCantGetUserIdCtx:            "CANT_GET_USER_ID_CTX",
+	ErrCantParseUserId:             "CANT_PARSE_USER_ID",
+	ErrProductVersionMismatch:      "PRODUCT_VERSION_MISMATCH",
+	ErrIfMatchRequired:             "IF_MATCH_REQUIRED",
+	ErrInvalidIfMatch:              "INVALID_IF_MATCH",
+	ErrImageNotFound:               "IMAGE_NOT_FOUND",
+	ErrImageTooLarge:               "IMAGE_TOO_LARGE",
+	ErrNoImageUploaded:             "NO_IMAGE_UPLOADED",
+	ErrTooManyImages:               "TOO_MANY_IMAGES",
+	ErrImageOrderMismatch:          "IMAGE_ORDER_MISMATCH",
+	ErrUnsupportedPatchType:        "UNSUPPORTED_PATCH_TYPE",
+	ErrInvalidPatch:                "INVALID_PATCH",
+	ErrPatchTestFailed:             "PATCH_TEST_FAILED",
+	ErrVariantNotFound:             "VARIANT_NOT_FOUND",
+	ErrVariantHasStock:             "VARIANT_HAS_STOCK",
+	ErrTooManyVariants:             "TOO_MANY_VARIANTS",
+	ErrBarcodeExists:               "BARCODE_EXISTS",
+	ErrPriceScheduleNotFound:       "PRICE_SCHEDULE_NOT_FOUND",
+	ErrPriceScheduleClosed:         "PRICE_SCHEDULE_CLOSED",
+	ErrInsufficientStock:           "INSUFFICIENT_STOCK",
+	ErrStockNegative:               "STOCK_NEGATIVE",
+	ErrStockAboveMax:               "STOCK_ABOVE_MAX",
+	ErrAlertNotFound:               "ALERT_NOT_FOUND",
+	ErrAlertResolved:               "ALERT_RESOLVED",
+	ErrLocationNotFound:            "LOCATION_NOT_FOUND",
+	ErrLocationCodeExists:          "LOCATION_CODE_EXISTS",
+	ErrLocationInactive:            "LOCATION_INACTIVE",
+	ErrTransferNotFound:            "TRANSFER_NOT_FOUND",
+	ErrTransferNotInTransit:        "TRANSFER_NOT_IN_TRANSIT",
+	ErrStockTakeNotFound:           "STOCK_TAKE_NOT_FOUND",
+	ErrStockTakeClosed:             "STOCK_TAKE_CLOSED",
+	ErrSupplierNotFound:            "SUPPLIER_NOT_FOUND",
+	ErrSupplierNameExists:          "SUPPLIER_NAME_EXISTS",
+	ErrSupplierInactive:            "SUPPLIER_INACTIVE",
+	ErrPurchaseOrderNotFound:       "PURCHASE_ORDER_NOT_FOUND",
+	ErrPurchaseOrderNotDraft:       "PURCHASE_ORDER_NOT_DRAFT",
+	ErrPurchaseOrderNotOpen:        "PURCHASE_ORDER_NOT_OPEN",
+	ErrPurchaseOrderClosed:         "PURCHASE_ORDER_CLOSED",
+	ErrNothingToReorder:            "NOTHING_TO_REORDER",
+	ErrPromotionNotFound:           "PROMOTION_NOT_FOUND",
+	ErrPromotionCodeExists:         "PROMOTION_CODE_EXISTS",
+	ErrCategoryNotFound:            "CATEGORY_NOT_FOUND",
+	ErrCategoryParentNotFound:      "CATEGORY_PARENT_NOT_FOUND",
+	ErrCategoryCycle:               "CATEGORY_CYCLE",
+	ErrCategoryHasChildren:         "CATEGORY_HAS_CHILDREN",
+	ErrCategoryInUse:               "CATEGORY_IN_USE",
+	ErrCategorySlugExists:          "CATEGORY_SLUG_EXISTS",
+}
+
+// CodeOf returns the stable code of message, one of the Err* constants, or
+// "" if it has none.
+func CodeOf(message string) ErrorCode {
+	return errorCodes[message]
+}
+
+// codeFor is CodeOf falling back to the class code for messages that are not
+// Err* constants.
+func codeFor(message string, class ErrorCode) ErrorCode {
+	if code, ok := errorCodes[message]; ok {
+		return code
+	}
+	return class
+}
@@ -1,15 +1,41 @@
 package msg
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
-
-	"github.com/rs/zerolog/log"
 )
 
+// RespError is the error returned by every layer and rendered by the error
+// handling middleware. Code is the HTTP status, ErrorCode the stable machine
+// readable code and Details the per field problems of a validation error.
 type RespError struct {
-	Code    int    `json:"code,omitempty"`
-	Message string `json:"message,omitempty"`
+	Code      int          `json:"code,omitempty"`
+	ErrorCode ErrorCode    `json:"errorCode,omitempty"`
+	Message   string       `json:"message,omitempty"`
+	Details   []FieldError `json:"details,omitempty"`
+	Err       error        `json:"-"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+
+	// template and params keep the untranslated message for Localize.
+	template string
+	params   []interface{}
+}
+
+// NewFieldError formats template, one of the Val* constants, with params.
+func NewFieldError(field, code, template string, params ...interface{}) FieldError {
+	return FieldError{
+		Field:    field,
+		Code:     code,
+		Message:  fmt.Sprintf(template, params...),
+		template: template,
+		params:   params,
+	}
 }
 
 const (
@@ -79,49 +105,176 @@ const (
 	ErrWeakPassword           = "password should contain alphanumeric, symbol and one character to be uppercase with min length is 6"
 	ErrOldPassword            = "old password cannot be used"
 
-	ErrPleaseRelogin = "your token expired, please relogin"
+	ErrPleaseRelogin   = "your token expired, please relogin"
+	ErrTooManyRequests = "too many requests, please try again later"
+
+	ErrValidationFailed  = "request doesn't pass validation"
+	ErrInternalServer    = "internal server error"
+	ErrEmptyRequestBody  = "Request body is empty"
+	ErrNoAuthHeader      = "No authorization header provided"
+	ErrPayloadNullValues = "JSON payload contains null values"
+	ErrProductIDMissing  = "Product ID is missing"
+	ErrProductNotFound   = "product not found"
+	ErrNoRowsReturned    = "no rows were returned"
+	ErrCantGetUserIdCtx  = "Can't retrieve userId inside context"
+	ErrCantParseUserId   = "Can't parse userId from current context"
+
+	// product
+	ErrProductVersionMismatch = "product was modified by someone else, reload it and try again"
+	ErrIfMatchRequired        = "If-Match header with the product ETag is required"
+	ErrInvalidIfMatch         = "If-Match header must contain a product ETag"
+	ErrImageNotFound          = "image not found"
+	ErrImageTooLarge          = "image is larger than allowed"
+	ErrNoImageUploaded        = "no image uploaded, send the files in the images field"
+	ErrTooManyImages          = "too many images in one request"
+	ErrImageOrderMismatch     = "imageIds must list every image of the product exactly once"
+	ErrUnsupportedPatchType   = "Content-Type must be application/merge-patch+json or application/json-patch+json"
+	ErrInvalidPatch           = "patch document is invalid"
+	ErrPatchTestFailed        = "patch test operation failed"
+	ErrVariantNotFound        = "variant not found"
+	ErrVariantHasStock        = "variants that still have stock cannot be removed, set their stock to 0 first"
+	ErrTooManyVariants        = "options generate too many variants"
+	ErrBarcodeExists          = "barcode already exists"
+	ErrPriceScheduleNotFound  = "price schedule not found"
+	ErrPriceScheduleClosed    = "price schedule is already applied or cancelled"
+	ErrInsufficientStock      = "not enough stock"
+	ErrStockNegative          = "adjustment would make the stock negative"
+	ErrStockAboveMax          = "stock cannot exceed 100000"
+	ErrAlertNotFound          = "stock alert not found"
+	ErrAlertResolved          = "stock alert is already resolved"
+	ErrLocationNotFound       = "location not found"
+	ErrLocationCodeExists     = "location code already exists"
+	ErrLocationInactive       = "location is inactive"
+	ErrTransferNotFound       = "transfer not found"
+	ErrTransferNotInTransit   = "transfer is no longer in transit"
+	ErrStockTakeNotFound      = "stock take not found"
+	ErrStockTakeClosed        = "stock take is no longer open for counting"
+
+	// purchasing
+	ErrSupplierNotFound      = "supplier not found"
+	ErrSupplierNameExists    = "supplier name already exists"
+	ErrSupplierInactive      = "supplier is inactive"
+	ErrPurchaseOrderNotFound = "purchase order not found"
+	ErrPurchaseOrderNotDraft = "purchase order can only be changed or sent while it is a draft"
+	ErrPurchaseOrderNotOpen  = "purchase order is not open for receiving"
+	ErrPurchaseOrderClosed   = "purchase order is already received or cancelled"
+	ErrNothingToReorder      = "no products of this supplier are at or below their reorder point"
+
+	// promotion
+	ErrPromotionNotFound   = "promotion not found"
+	ErrPromotionCodeExists = "promotion code already exists"
+
+	// category
+	ErrCategoryNotFound       = "category not found"
+	ErrCategoryParentNotFound = "parent category not found"
+	ErrCategoryCycle          = "a category cannot be moved below itself or its subcategories"
+	ErrCategoryHasChildren    = "category still has subcategories"
+	ErrCategoryInUse          = "category is still used by products"
+	ErrCategorySlugExists     = "category slug already exists"
 )
 
 func (r *RespError) Error() string {
+	if r.Err != nil {
+		return fmt.Sprintf("%d: %v: %v", r.Code, r.Message, r.Err)
+	}
 	return fmt.Sprintf("%d: %v", r.Code, r.Message)
 }
 
-func UnwrapRespError(err interface{}) RespError {
-	respError, ok := err.(*RespError)
-	if !ok {
-		panic("fail when casting the respError type")
+func (r *RespError) Unwrap() error {
+	return r.Err
+}
+
+// UnwrapRespError finds the RespError in err's chain. Any other error is
+// reported as an internal server error wrapping err.
+func UnwrapRespError(err error) RespError {
+	var respError *RespError
+	if errors.As(err, &respError) {
+		resp := *respError
+		if resp.Code == 0 {
+			resp.Code = resp.ErrorCode.HTTPStatus()
+		}
+		if resp.ErrorCode == "" {
+			resp.ErrorCode = codeFor(resp.Message, CodeForStatus(resp.Code))
+		}
+		return resp
 	}
 
-	return *respError
+	return RespError{
+		Code:      http.StatusInternalServerError,
+		ErrorCode: CodeInternal,
+		Message:   ErrInternalServer,
+		Err:       err,
+	}
 }
 
-func InternalServerError(msg string) error {
-	log.Printf(msg)
+// New returns an error with the HTTP status mapped from the class code and the
+// message's own stable code.
+func New(code ErrorCode, message string) error {
+	return &RespError{
+		Code:      code.HTTPStatus(),
+		ErrorCode: codeFor(message, code),
+		Message:   message,
+	}
+}
+
+// Wrap is New keeping err as the cause, reachable through errors.As/Is.
+func Wrap(code ErrorCode, message string, err error) error {
+	return &RespError{
+		Code:      code.HTTPStatus(),
+		ErrorCode: codeFor(message, code),
+		Message:   message,
+		Err:       err,
+	}
+}
+
+// Validation returns a 400 listing every invalid field.
+func Validation(details ...FieldError) error {
+	return &RespError{
+		Code:      http.StatusBadRequest,
+		ErrorCode: CodeValidationFailed,
+		Message:   ErrValidationFailed,
+		Details:   details,
+	}
+}
 
+func InternalServerError(msg string) error {
 	return &RespError{
-		Code:    http.StatusInternalServerError,
-		Message: "internal server error",
+		Code:      http.StatusInternalServerError,
+		ErrorCode: CodeInternal,
+		Message:   ErrInternalServer,
+		Err:       errors.New(msg),
 	}
 }
 
 func BadRequest(msg string) error {
 	return &RespError{
-		Code:    http.StatusBadRequest,
-		Message: msg,
+		Code:      http.StatusBadRequest,
+		ErrorCode: codeFor(msg, CodeBadRequest),
+		Message:   msg,
 	}
 }
 
 func NotFound(msg string) error {
 	return &RespError{
-		Code:    http.StatusNotFound,
-		Message: msg,
+		Code:      http.StatusNotFound,
+		ErrorCode: codeFor(msg, CodeNotFound),
+		Message:   msg,
 	}
 }
 
 func Unauthorization(msg string) error {
 	return &RespError{
-		Code:    http.StatusUnauthorized,
-		Message: msg,
+		Code:      http.StatusUnauthorized,
+		ErrorCode: codeFor(msg, CodeUnauthorized),
+		Message:   msg,
+	}
+}
+
+func Conflict(msg string) error {
+	return &RespError{
+		Code:      http.StatusConflict,
+		ErrorCode: codeFor(msg, CodeConflict),
+		Message:   msg,
 	}
 }
 
@@ -31,6 +31,35 @@ const (
 	RefundDeclinedResponse          = "Refund Declined Successfully"
 	RefundSuccessfully              = "Transaction Refunded Successfully"
 	UserWalletSuccessfullyVerified  = "user wallet successfully verified"
+	// staff
+	UserRegisteredResponse = "User registered successfully"
+	UserLoggedResponse     = "User logged successfully"
+	// product
+	SuccessResponse                = "success"
+	ProductUpdatedResponse         = "Product updated successfully"
+	ProductDeletedResponse         = "Product deleted successfully"
+	ImageUploadedResponse          = "Images uploaded successfully"
+	PriceScheduledResponse         = "Price change scheduled"
+	PriceScheduleCancelledResponse = "Price change cancelled"
+	// checkout
+	CheckoutResponse = "Checkout completed successfully"
+	// inventory
+	StockAdjustedResponse          = "Stock adjusted successfully"
+	AlertAcknowledgedResponse      = "Stock alert acknowledged"
+	AlertResolvedResponse          = "Stock alert resolved"
+	TransferCreatedResponse        = "Stock transfer created"
+	TransferReceivedResponse       = "Stock transfer received"
+	TransferCancelledResponse      = "Stock transfer cancelled"
+	StockTakeCreatedResponse       = "Stock take started"
+	CountsRecordedResponse         = "Counts recorded"
+	StockTakeApprovedResponse      = "Stock take approved and stock adjusted"
+	StockTakeCancelledResponse     = "Stock take cancelled"
+	PurchaseOrderCreatedResponse   = "Purchase order created"
+	PurchaseOrderSentResponse      = "Purchase order sent"
+	PurchaseOrderCancelledResponse = "Purchase order cancelled"
+	GoodsReceivedResponse          = "Goods received and stock updated"
+	PromotionCreatedResponse       = "Promotion created"
+	PromotionUpdatedResponse       = "Promotion updated"
 )
 
 type Response struct {
@@ -0,0 +1,43 @@
+package msg
+
+// Validation message templates. The first verb is always the field name,
+// the second one the rule parameter.
+const (
+	ValRequired           = "%s cannot be empty"
+	ValMinLength          = "%s must be at least %s characters"
+	ValMaxLength          = "%s must be at most %s characters"
+	ValMin                = "%s must be at least %s"
+	ValMax                = "%s must be at most %s"
+	ValOneOf              = "%s must be one of: %s"
+	ValInvalidEmail       = "%s must be a valid email address"
+	ValInvalidURL         = "%s must be a valid http or https URL"
+	ValInvalidPhone       = "%s must be a valid phone number with country code"
+	ValInvalidCategory    = "%s is not a valid category"
+	ValInvalidFullName    = "%s can only contain letters and spaces and must be between 5 and 15 characters long"
+	ValInvalidPassword    = "%s must be between 5 and 15 characters long"
+	ValInvalidSlug        = "%s may only contain lowercase letters, digits and single dashes"
+	ValDuplicate          = "%s must be unique"
+	ValOptionsForString   = "%s can only be set for string attributes"
+	ValAttributeType      = "%s must be a %s"
+	ValUnknownAttribute   = "%s is not an attribute of this category"
+	ValReadOnly           = "%s cannot be changed"
+	ValVariantRequired    = "%s is required for products with options"
+	ValUnavailable        = "%s is not available"
+	ValInsufficientStock  = "%s exceeds the available stock of %s"
+	ValPaidNotEnough      = "%s is less than the total of %s"
+	ValDeltaOrCount       = "%s or count is required, but not both"
+	ValDecreaseOnly       = "%s only allows decreasing stock"
+	ValIncreaseOnly       = "%s only allows increasing stock"
+	ValNotInStockTake     = "%s is not part of this stock take"
+	ValAmbiguousSKU       = "%s matches several variants, give variantId instead"
+	ValSKUOrVariant       = "%s or variantId is required"
+	ValNotInPurchaseOrder = "%s is not a line of this purchase order"
+	ValOverReceived       = "%s exceeds the %s still to receive"
+	ValNotFuture          = "%s must be in the future"
+	ValRequiredFor        = "%s is required for %s promotions"
+	ValNotFor             = "%s does not apply to %s promotions"
+	ValAfter              = "%s must be after %s"
+	ValInvalidPromoCode   = "%s is not a valid promotion code"
+	ValInvalid            = "%s is invalid"
+	ValStockAdjustOnly    = "%s can only be changed with %s"
+)
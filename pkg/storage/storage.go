@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"projectsphere/eniqlo-store/config"
+	"strings"
+)
+
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage keeps objects under slash separated keys such as
+// "products/12/3f2a.jpg" and serves them from public URLs.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// LocalPath is where the router serves objects of the local driver.
+const LocalPath = "/uploads"
+
+type Config struct {
+	Driver string
+	// LocalDir is the directory of the local driver.
+	LocalDir string
+	// PublicURL is the base URL objects are served from, e.g.
+	// "https://api.example.com/uploads" for the local driver. It is required
+	// for the local driver; the S3 driver defaults to the bucket URL.
+	PublicURL string
+	// S3BaseURL is the bucket URL, path style (https://host/bucket) or
+	// virtual hosted (https://bucket.host).
+	S3BaseURL   string
+	S3Region    string
+	S3AccessKey string
+	S3SecretKey string
+}
+
+// ConfigFromEnv reads STORAGE_DRIVER, STORAGE_LOCAL_DIR,
+// STORAGE_PUBLIC_URL, S3_BASE_URL, S3_REGION, S3_ID and S3_SECRET_KEY.
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Driver:      strings.ToLower(config.GetString("STORAGE_DRIVER")),
+		LocalDir:    config.GetString("STORAGE_LOCAL_DIR"),
+		PublicURL:   strings.TrimRight(config.GetString("STORAGE_PUBLIC_URL"), "/"),
+		S3BaseURL:   strings.TrimRight(config.GetString("S3_BASE_URL"), "/"),
+		S3Region:    config.GetString("S3_REGION"),
+		S3AccessKey: config.GetString("S3_ID"),
+		S3SecretKey: config.GetString("S3_SECRET_KEY"),
+	}
+
+	if cfg.Driver == "" {
+		cfg.Driver = DriverLocal
+	}
+	if cfg.LocalDir == "" {
+		cfg.LocalDir = "./uploads"
+	}
+	if cfg.S3Region == "" {
+		cfg.S3Region = "us-east-1"
+	}
+	if cfg.PublicURL == "" && cfg.Driver == DriverS3 {
+		cfg.PublicURL = cfg.S3BaseURL
+	}
+
+	return cfg
+}
+
+// New builds the storage selected by cfg.Driver.
+func New(cfg Config) (Storage, error) {
+	switch cfg.Driver {
+	case DriverLocal:
+		// a guessed default would leak into the URLs returned to clients
+		if cfg.PublicURL == "" {
+			return nil, errors.New("storage: STORAGE_PUBLIC_URL is required for the local driver")
+		}
+		return NewLocal(cfg.LocalDir, cfg.PublicURL)
+	case DriverS3:
+		return NewS3(cfg.S3BaseURL, cfg.S3Region, cfg.S3AccessKey, cfg.S3SecretKey, cfg.PublicURL)
+	default:
+		return nil, fmt.Errorf("storage: unknown driver %q", cfg.Driver)
+	}
+}
+
+// validKey rejects keys that could escape the storage root.
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("storage: invalid key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("storage: invalid key %q", key)
+		}
+	}
+	return nil
+}
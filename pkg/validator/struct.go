@@ -0,0 +1,203 @@
+package validator
+
+import (
+	"errors"
+	"net/url"
+	"projectsphere/eniqlo-store/pkg/money"
+	"projectsphere/eniqlo-store/pkg/protocol/msg"
+	"reflect"
+	"strings"
+	"sync"
+
+	playground "github.com/go-playground/validator/v10"
+)
+
+var (
+	categoryMu      sync.RWMutex
+	categoryChecker func(category string) bool
+)
+
+// SetCategoryChecker installs the lookup behind the `category` rule. Until
+// then no category is valid.
+func SetCategoryChecker(checker func(category string) bool) {
+	categoryMu.Lock()
+	defer categoryMu.Unlock()
+	categoryChecker = checker
+}
+
+func isValidCategory(category string) bool {
+	categoryMu.RLock()
+	defer categoryMu.RUnlock()
+	return categoryChecker != nil && categoryChecker(category)
+}
+
+// StructValidator evaluates `validate` struct tags. Besides the built in
+// go-playground rules it knows phone, category, httpurl, fullname, password
+// and slug, and it validates money.Money fields by amount. It implements gin's binding.StructValidator.
+type StructValidator struct {
+	validate *playground.Validate
+}
+
+var structValidator = NewStructValidator()
+
+func NewStructValidator() *StructValidator {
+	validate := playground.New()
+	validate.SetTagName("validate")
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	// money is validated by its value, so min=1 means at least one major unit
+	validate.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		return field.Interface().(money.Money).Float64()
+	}, money.Money{})
+
+	stringRules := map[string]func(string) bool{
+		"phone":    IsValidPhoneNumber,
+		"category": isValidCategory,
+		"httpurl":  isHttpURL,
+		"fullname": IsValidFullName,
+		"password": IsSolidPassword,
+		"slug":     IsValidSlug,
+	}
+	for tag, rule := range stringRules {
+		rule := rule
+		validate.RegisterValidation(tag, func(fl playground.FieldLevel) bool {
+			return rule(fl.Field().String())
+		})
+	}
+
+	return &StructValidator{validate: validate}
+}
+
+// ValidateStruct validates structs, pointers to structs and slices of them.
+// Other values are ignored. Failures are returned as msg.Validation errors.
+func (v *StructValidator) ValidateStruct(obj any) error {
+	if obj == nil {
+		return nil
+	}
+
+	value := reflect.ValueOf(obj)
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return nil
+		}
+		return v.ValidateStruct(value.Elem().Interface())
+	case reflect.Struct:
+		return toRespError(v.validate.Struct(obj))
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := v.ValidateStruct(value.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (v *StructValidator) Engine() any {
+	return v.validate
+}
+
+// Struct validates obj with the shared StructValidator.
+func Struct(obj any) error {
+	return structValidator.ValidateStruct(obj)
+}
+
+// Shared returns the StructValidator used by Struct, to be installed as
+// gin's binding.Validator.
+func Shared() *StructValidator {
+	return structValidator
+}
+
+// BindError turns an error of gin's ShouldBind* into a RespError: validation
+// errors are kept, decoding errors become a bad request.
+func BindError(err error) error {
+	var respError *msg.RespError
+	if errors.As(err, &respError) {
+		return err
+	}
+	return msg.BadRequest(err.Error())
+}
+
+func toRespError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors playground.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return msg.BadRequest(err.Error())
+	}
+
+	details := make([]msg.FieldError, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		details = append(details, toFieldError(fieldError))
+	}
+	return msg.Validation(details...)
+}
+
+func toFieldError(fe playground.FieldError) msg.FieldError {
+	field := fieldPath(fe)
+	isString := fe.Kind() == reflect.String
+
+	switch fe.Tag() {
+	case "required":
+		return msg.NewFieldError(field, fe.Tag(), msg.ValRequired, field)
+	case "min", "gte":
+		if isString {
+			return msg.NewFieldError(field, fe.Tag(), msg.ValMinLength, field, fe.Param())
+		}
+		return msg.NewFieldError(field, fe.Tag(), msg.ValMin, field, fe.Param())
+	case "max", "lte":
+		if isString {
+			return msg.NewFieldError(field, fe.Tag(), msg.ValMaxLength, field, fe.Param())
+		}
+		return msg.NewFieldError(field, fe.Tag(), msg.ValMax, field, fe.Param())
+	case "oneof":
+		return msg.NewFieldError(field, fe.Tag(), msg.ValOneOf, field, fe.Param())
+	case "email":
+		return msg.NewFieldError(field, fe.Tag(), msg.ValInvalidEmail, field)
+	case "url", "httpurl":
+		return msg.NewFieldError(field, fe.Tag(), msg.ValInvalidURL, field)
+	case "phone":
+		return msg.NewFieldError(field, fe.Tag(), msg.ValInvalidPhone, field)
+	case "category":
+		return msg.NewFieldError(field, fe.Tag(), msg.ValInvalidCategory, field)
+	case "fullname":
+		return msg.NewFieldError(field, fe.Tag(), msg.ValInvalidFullName, field)
+	case "password":
+		return msg.NewFieldError(field, fe.Tag(), msg.ValInvalidPassword, field)
+	case "slug":
+		return msg.NewFieldError(field, fe.Tag(), msg.ValInvalidSlug, field)
+	default:
+		return msg.NewFieldError(field, fe.Tag(), msg.ValInvalid, field)
+	}
+}
+
+// fieldPath drops the top level struct name from the namespace, giving
+// "name" or "items[0].qty".
+func fieldPath(fe playground.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fe.Field()
+}
+
+func isHttpURL(raw string) bool {
+	u, err := url.ParseRequestURI(raw)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}